@@ -0,0 +1,56 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/interfaces"
+	"github.com/redis/go-redis/v9/auth"
+)
+
+// streamingCredentials adapts a CredentialsProvider to go-redis, which
+// subscribes every connection and re-authenticates it with AUTH on rotation
+type streamingCredentials struct {
+	provider interfaces.CredentialsProvider
+	logger   interfaces.Logger
+
+	// username from the URL, used when the provider gives none
+	username string
+}
+
+func newStreamingCredentials(provider interfaces.CredentialsProvider, username string, logger interfaces.Logger) *streamingCredentials {
+	return &streamingCredentials{provider: provider, username: username, logger: logger}
+}
+
+func (s *streamingCredentials) current() (auth.Credentials, error) {
+	credentials, err := s.provider.Credentials(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	username := credentials.Username
+	if username == "" {
+		username = s.username
+	}
+	return auth.NewBasicCredentials(username, credentials.Password), nil
+}
+
+func (s *streamingCredentials) Subscribe(listener auth.CredentialsListener) (auth.Credentials, auth.UnsubscribeFunc, error) {
+	credentials, err := s.current()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	unsubscribe := s.provider.Subscribe(func() {
+		credentials, err := s.current()
+		if err != nil {
+			s.logger.WithError(err).Warn("Failed to get rotated Redis credentials")
+			listener.OnError(err)
+			return
+		}
+		listener.OnNext(credentials)
+	})
+
+	return credentials, func() error {
+		unsubscribe()
+		return nil
+	}, nil
+}
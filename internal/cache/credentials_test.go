@@ -0,0 +1,74 @@
+package cache
+
+import (
+	"context"
+	"testing"
+
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/interfaces"
+	"github.com/redis/go-redis/v9/auth"
+)
+
+type rotatingCredentials struct {
+	current     interfaces.Credentials
+	subscribers map[int]func()
+}
+
+func (c *rotatingCredentials) Credentials(ctx context.Context) (interfaces.Credentials, error) {
+	return c.current, nil
+}
+
+func (c *rotatingCredentials) Subscribe(fn func()) func() {
+	id := len(c.subscribers)
+	c.subscribers[id] = fn
+	return func() { delete(c.subscribers, id) }
+}
+
+func (c *rotatingCredentials) rotate(password string) {
+	c.current.Password = password
+	for _, fn := range c.subscribers {
+		fn()
+	}
+}
+
+type recordingListener struct {
+	received []auth.Credentials
+}
+
+func (l *recordingListener) OnNext(credentials auth.Credentials) {
+	l.received = append(l.received, credentials)
+}
+func (l *recordingListener) OnError(err error) {}
+
+// TestStreamingCredentials tests that connections are re-authenticated on rotation
+func TestStreamingCredentials(t *testing.T) {
+	provider := &rotatingCredentials{
+		current:     interfaces.Credentials{Password: "first"},
+		subscribers: map[int]func(){},
+	}
+	streaming := newStreamingCredentials(provider, "custodian-adapter", nil)
+
+	listener := &recordingListener{}
+	initial, unsubscribe, err := streaming.Subscribe(listener)
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+	if username, password := initial.BasicAuth(); username != "custodian-adapter" || password != "first" {
+		t.Errorf("initial credentials = %s:%s", username, password)
+	}
+
+	provider.rotate("second")
+	if len(listener.received) != 1 {
+		t.Fatalf("listener received %d updates, expected 1", len(listener.received))
+	}
+	if _, password := listener.received[0].BasicAuth(); password != "second" {
+		t.Errorf("rotated password = %s, expected second", password)
+	}
+
+	if err := unsubscribe(); err != nil {
+		t.Fatalf("unsubscribe failed: %v", err)
+	}
+	provider.rotate("third")
+	if len(listener.received) != 1 {
+		t.Error("listener notified after unsubscribe")
+	}
+}
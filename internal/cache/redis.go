@@ -3,20 +3,28 @@ package cache
 import (
 	"context"
 	"fmt"
+	"net"
 	"time"
 
 	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/internal/config"
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/interfaces"
 	"github.com/redis/go-redis/v9"
-	"github.com/sirupsen/logrus"
 )
 
 type RedisClient struct {
-	Client *redis.Client
+	Client redis.UniversalClient
 	config *config.Config
-	logger *logrus.Logger
+	logger interfaces.Logger
+
+	// external clients belong to the caller and are not closed on Disconnect
+	external bool
 }
 
-func NewRedisClient(cfg *config.Config, logger *logrus.Logger) (*RedisClient, error) {
+// NewRedisClient creates a client for RedisURL, or for the sentinel master or
+// cluster selected by RedisMode. With a credentials provider, connections
+// authenticate with the provider's current credentials and are
+// re-authenticated when they rotate.
+func NewRedisClient(cfg *config.Config, credentials interfaces.CredentialsProvider, logger interfaces.Logger) (*RedisClient, error) {
 	if cfg.RedisURL == "" {
 		return nil, fmt.Errorf("REDIS_URL is required")
 	}
@@ -32,8 +40,23 @@ func NewRedisClient(cfg *config.Config, logger *logrus.Logger) (*RedisClient, er
 	opts.DialTimeout = cfg.RedisDialTimeout
 	opts.ReadTimeout = cfg.RedisReadTimeout
 	opts.WriteTimeout = cfg.RedisWriteTimeout
+	if cfg.RedisTLS.Enabled {
+		host, _, err := net.SplitHostPort(opts.Addr)
+		if err != nil {
+			host = opts.Addr
+		}
+		if opts.TLSConfig, err = cfg.RedisTLS.Build(host); err != nil {
+			return nil, fmt.Errorf("invalid Redis TLS configuration: %w", err)
+		}
+	}
+	if credentials != nil {
+		opts.StreamingCredentialsProvider = newStreamingCredentials(credentials, opts.Username, logger)
+	}
 
-	client := redis.NewClient(opts)
+	client, err := newUniversalClient(cfg, opts)
+	if err != nil {
+		return nil, err
+	}
 
 	return &RedisClient{
 		Client: client,
@@ -42,6 +65,61 @@ func NewRedisClient(cfg *config.Config, logger *logrus.Logger) (*RedisClient, er
 	}, nil
 }
 
+// NewRedisClientFromClient wraps a client created and configured by the
+// caller: a *redis.Client, *redis.FailoverClient or *redis.ClusterClient
+func NewRedisClientFromClient(client redis.UniversalClient, cfg *config.Config, logger interfaces.Logger) *RedisClient {
+	return &RedisClient{
+		Client:   client,
+		config:   cfg,
+		logger:   logger,
+		external: true,
+	}
+}
+
+// newUniversalClient connects to the topology in cfg.RedisMode using the
+// connection settings parsed from the URL
+func newUniversalClient(cfg *config.Config, opts *redis.Options) (redis.UniversalClient, error) {
+	if cfg.RedisMode == "" || cfg.RedisMode == config.RedisModeStandalone {
+		return redis.NewClient(opts), nil
+	}
+	if opts.Network == "unix" {
+		return nil, fmt.Errorf("Redis %s mode needs a redis:// or rediss:// URL", cfg.RedisMode)
+	}
+
+	universal := &redis.UniversalOptions{
+		DB:                           opts.DB,
+		Username:                     opts.Username,
+		Password:                     opts.Password,
+		StreamingCredentialsProvider: opts.StreamingCredentialsProvider,
+		SentinelPassword:             cfg.RedisSentinelPassword,
+		MaxRetries:                   opts.MaxRetries,
+		DialTimeout:                  opts.DialTimeout,
+		ReadTimeout:                  opts.ReadTimeout,
+		WriteTimeout:                 opts.WriteTimeout,
+		PoolSize:                     opts.PoolSize,
+		MinIdleConns:                 opts.MinIdleConns,
+		TLSConfig:                    opts.TLSConfig,
+		MasterName:                   cfg.RedisSentinelMaster,
+	}
+
+	switch cfg.RedisMode {
+	case config.RedisModeSentinel:
+		universal.Addrs = cfg.SentinelAddrs()
+		return redis.NewFailoverClient(universal.Failover()), nil
+	case config.RedisModeCluster:
+		// Cluster nodes only have database 0
+		if opts.DB != 0 {
+			return nil, fmt.Errorf("Redis cluster mode does not support database %d", opts.DB)
+		}
+		universal.Addrs = cfg.ClusterAddrs()
+		if len(universal.Addrs) == 0 {
+			universal.Addrs = []string{opts.Addr}
+		}
+		return redis.NewClusterClient(universal.Cluster()), nil
+	}
+	return nil, fmt.Errorf("unknown Redis mode %q", cfg.RedisMode)
+}
+
 func (r *RedisClient) Connect(ctx context.Context) error {
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
@@ -55,7 +133,7 @@ func (r *RedisClient) Connect(ctx context.Context) error {
 }
 
 func (r *RedisClient) Disconnect(ctx context.Context) error {
-	if r.Client != nil {
+	if r.Client != nil && !r.external {
 		if err := r.Client.Close(); err != nil {
 			return fmt.Errorf("failed to close Redis: %w", err)
 		}
@@ -0,0 +1,80 @@
+package cache
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/internal/config"
+	"github.com/redis/go-redis/v9"
+)
+
+// TestNewRedisClientModes tests that RedisMode selects the client type
+func TestNewRedisClientModes(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      config.Config
+		check    func(client redis.UniversalClient) bool
+		errorMsg string
+	}{
+		{
+			name:  "standalone",
+			cfg:   config.Config{RedisURL: "redis://localhost:6379/2"},
+			check: func(client redis.UniversalClient) bool { _, ok := client.(*redis.Client); return ok },
+		},
+		{
+			name: "sentinel",
+			cfg: config.Config{
+				RedisURL:            "redis://:secret@localhost:6379/2",
+				RedisMode:           config.RedisModeSentinel,
+				RedisSentinelMaster: "mymaster",
+				RedisSentinelAddrs:  "sentinel-1:26379,sentinel-2:26379",
+			},
+			check: func(client redis.UniversalClient) bool {
+				c, ok := client.(*redis.Client)
+				return ok && c.Options().DB == 2 && c.Options().Password == "secret" && strings.Contains(c.String(), "FailoverClient")
+			},
+		},
+		{
+			name: "cluster",
+			cfg: config.Config{
+				RedisURL:          "redis://localhost:6379/0",
+				RedisMode:         config.RedisModeCluster,
+				RedisClusterAddrs: "redis-1:6379,redis-2:6379",
+			},
+			check: func(client redis.UniversalClient) bool {
+				c, ok := client.(*redis.ClusterClient)
+				return ok && len(c.Options().Addrs) == 2
+			},
+		},
+		{
+			name:     "cluster rejects database",
+			cfg:      config.Config{RedisURL: "redis://localhost:6379/3", RedisMode: config.RedisModeCluster},
+			errorMsg: "does not support database 3",
+		},
+		{
+			name:     "unix socket outside standalone",
+			cfg:      config.Config{RedisURL: "unix:///tmp/redis.sock", RedisMode: config.RedisModeCluster},
+			errorMsg: "needs a redis:// or rediss:// URL",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewRedisClient(&tt.cfg, nil, nil)
+			if tt.errorMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+					t.Fatalf("NewRedisClient error = %v, expected %q", err, tt.errorMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewRedisClient failed: %v", err)
+			}
+			defer client.Client.Close()
+
+			if !tt.check(client.Client) {
+				t.Errorf("unexpected client %T: %v", client.Client, client.Client)
+			}
+		})
+	}
+}
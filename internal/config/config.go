@@ -2,14 +2,26 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"os"
 	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
 )
 
+// EnvironmentProduction enables checks that are only warnings elsewhere
+const EnvironmentProduction = "production"
+
+// Redis deployment modes
+const (
+	RedisModeStandalone = "standalone"
+	RedisModeSentinel   = "sentinel"
+	RedisModeCluster    = "cluster"
+)
+
 type Config struct {
 	// Service Identity
 	ServiceName         string
@@ -17,16 +29,33 @@ type Config struct {
 	ServiceVersion      string
 	Environment         string
 
+	// Storage backend: "postgres" (PostgreSQL with Redis) or a registered third-party backend
+	Backend string
+
 	// Database Isolation
 	SchemaName      string // PostgreSQL schema (auto-derived if empty)
 	RedisNamespace  string // Redis key prefix (auto-derived if empty)
 
+	// Claim the schema and namespaces on connect, failing if another live
+	// instance owns them; opt-in, as claims need a provisioned schema
+	InstanceClaimEnabled bool
+
+	// Apply pending schema migrations on connect; needs a role that may
+	// create tables in the schema
+	SchemaAutoMigrate bool
+
 	// PostgreSQL
 	PostgresURL            string
 	MaxConnections         int
 	MaxIdleConnections     int
 	ConnectionMaxLifetime  time.Duration
 	ConnectionMaxIdleTime  time.Duration
+	PostgresTLS            TLSConfig // Replaces sslmode in PostgresURL when enabled
+
+	// PostgreSQL read replicas (pool, TLS and credentials as for the primary)
+	PostgresReplicaURLs          string        // Comma-separated; see ReplicaURLs
+	PostgresReplicaMaxLag        time.Duration // Replicas further behind serve no reads (0 disables the check)
+	PostgresReplicaCheckInterval time.Duration // How often replica lag is measured
 
 	// Redis
 	RedisURL          string
@@ -36,10 +65,52 @@ type Config struct {
 	RedisDialTimeout  time.Duration
 	RedisReadTimeout  time.Duration
 	RedisWriteTimeout time.Duration
+	RedisTLS          TLSConfig // Applies to redis:// and rediss:// URLs when enabled
+
+	// Redis topology; REDIS_URL still supplies credentials, DB and TLS host
+	RedisMode             string // "standalone" (default), "sentinel" or "cluster"
+	RedisSentinelMaster   string // Master name monitored by the sentinels
+	RedisSentinelAddrs    string // Comma-separated sentinel host:port list
+	RedisSentinelPassword string // Password for the sentinels themselves, if any
+	RedisClusterAddrs     string // Comma-separated seed nodes (REDIS_URL host if empty)
 
 	// Cache
 	CacheTTL       time.Duration
-	CacheNamespace string
+	CacheNamespace string // Cache, lock and invalidation key prefix (RedisNamespace if empty)
+
+	// In-process cache tier (disabled when LocalCacheMaxEntries is 0)
+	LocalCacheMaxEntries int
+	LocalCacheTTL        time.Duration
+
+	// Serve position and balance reads through the cache (tag-invalidated on writes)
+	CacheRepositories bool
+
+	// Reject PostgreSQL position and settlement writes for unknown or closed
+	// accounts; opt-in, as every write fails until the accounts exist
+	AccountChecksEnabled bool
+
+	// Normalize symbols to known assets and check quantity precision on
+	// writes; opt-in, as every write fails until the asset table is populated
+	AssetChecksEnabled bool
+
+	// Connection Policy
+	ConnectPolicy         string        // strict, retry or lenient
+	ConnectRetryDeadline  time.Duration // Give up retrying after this long
+	ConnectInitialBackoff time.Duration
+	ConnectMaxBackoff     time.Duration
+
+	// Health Checks
+	HealthCheckTimeout    time.Duration // Per-component check timeout
+	HealthDegradedLatency time.Duration // Checks slower than this report degraded
+
+	// Circuit Breakers (one per Postgres and Redis)
+	CircuitBreakerEnabled          bool
+	CircuitBreakerFailureRate      float64       // Failed share of calls that opens the breaker
+	CircuitBreakerMinRequests      int           // Calls per window before the rate is evaluated
+	CircuitBreakerWindow           time.Duration // Failure counting window
+	CircuitBreakerOpenTimeout      time.Duration // Time open before trial calls are allowed
+	CircuitBreakerHalfOpenRequests int           // Successful trial calls needed to close
+	CacheFailOpen                  bool          // Treat failed cache reads as misses
 
 	// Service Discovery
 	ServiceDiscoveryNamespace string
@@ -51,8 +122,10 @@ type Config struct {
 	TestRedisURL    string
 
 	// Logging
-	LogLevel  string
-	LogFormat string
+	LogLevel         string
+	LogFormat        string
+	LogRedaction     bool // Mask account IDs and metadata in log fields
+	LogRevealAtDebug bool // Log unmasked values in debug messages
 
 	// Performance Testing
 	PerfTestSize      int
@@ -63,42 +136,91 @@ type Config struct {
 	SkipIntegrationTests bool
 }
 
+// LoadConfig reads the configuration from the environment, an optional .env
+// and the file named by CONFIG_FILE, if set
 func LoadConfig() (*Config, error) {
 	// Try to load .env file (ignore errors if not found)
 	_ = godotenv.Load()
 
+	return LoadConfigFile(os.Getenv("CONFIG_FILE"))
+}
+
+// LoadConfigFile reads the configuration in layers, each overriding the last:
+// defaults, the top level of the YAML or JSON file at path, the file's profile
+// for the environment, then environment variables. Any setting can instead be
+// read from the file named by <KEY>_FILE. An empty path skips the file.
+func LoadConfigFile(path string) (*Config, error) {
+	source, err := readConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	env := &envParser{source: source, used: map[string]bool{}}
 	cfg := &Config{
-		ServiceName:               getEnv("SERVICE_NAME", "custodian-data-adapter"),
-		ServiceInstanceName:       getEnv("SERVICE_INSTANCE_NAME", ""),
-		ServiceVersion:            getEnv("SERVICE_VERSION", "1.0.0"),
-		Environment:               getEnv("ENVIRONMENT", "development"),
-		SchemaName:                getEnv("SCHEMA_NAME", ""),
-		RedisNamespace:            getEnv("REDIS_NAMESPACE", ""),
-		PostgresURL:               getEnv("POSTGRES_URL", ""),
-		MaxConnections:            getEnvInt("MAX_CONNECTIONS", 25),
-		MaxIdleConnections:        getEnvInt("MAX_IDLE_CONNECTIONS", 10),
-		ConnectionMaxLifetime:     getEnvDuration("CONNECTION_MAX_LIFETIME", 300*time.Second),
-		ConnectionMaxIdleTime:     getEnvDuration("CONNECTION_MAX_IDLE_TIME", 60*time.Second),
-		RedisURL:                  getEnv("REDIS_URL", ""),
-		RedisPoolSize:             getEnvInt("REDIS_POOL_SIZE", 10),
-		RedisMinIdleConns:         getEnvInt("REDIS_MIN_IDLE_CONNS", 2),
-		RedisMaxRetries:           getEnvInt("REDIS_MAX_RETRIES", 3),
-		RedisDialTimeout:          getEnvDuration("REDIS_DIAL_TIMEOUT", 5*time.Second),
-		RedisReadTimeout:          getEnvDuration("REDIS_READ_TIMEOUT", 3*time.Second),
-		RedisWriteTimeout:         getEnvDuration("REDIS_WRITE_TIMEOUT", 3*time.Second),
-		CacheTTL:                  getEnvDuration("CACHE_TTL", 300*time.Second),
-		CacheNamespace:            getEnv("CACHE_NAMESPACE", "custodian"),
-		ServiceDiscoveryNamespace: getEnv("SERVICE_DISCOVERY_NAMESPACE", "custodian"),
-		HeartbeatInterval:         getEnvDuration("HEARTBEAT_INTERVAL", 30*time.Second),
-		ServiceTTL:                getEnvDuration("SERVICE_TTL", 90*time.Second),
-		TestPostgresURL:           getEnv("TEST_POSTGRES_URL", ""),
-		TestRedisURL:              getEnv("TEST_REDIS_URL", ""),
-		LogLevel:                  getEnv("LOG_LEVEL", "info"),
-		LogFormat:                 getEnv("LOG_FORMAT", "json"),
-		PerfTestSize:              getEnvInt("PERF_TEST_SIZE", 1000),
-		PerfThroughputMin:         getEnvInt("PERF_THROUGHPUT_MIN", 100),
-		PerfLatencyMax:            getEnvDuration("PERF_LATENCY_MAX", 100*time.Millisecond),
-		SkipIntegrationTests:      getEnvBool("SKIP_INTEGRATION_TESTS", false),
+		ServiceName:               env.getEnv("SERVICE_NAME", "custodian-data-adapter"),
+		ServiceInstanceName:       env.getEnv("SERVICE_INSTANCE_NAME", ""),
+		ServiceVersion:            env.getEnv("SERVICE_VERSION", "1.0.0"),
+		Environment:               env.getEnv("ENVIRONMENT", "development"),
+		Backend:                   env.getEnv("DATA_ADAPTER_BACKEND", "postgres"),
+		SchemaName:                env.getEnv("SCHEMA_NAME", ""),
+		RedisNamespace:            env.getEnv("REDIS_NAMESPACE", ""),
+		InstanceClaimEnabled:      env.getEnvBool("INSTANCE_CLAIM_ENABLED", false),
+		SchemaAutoMigrate:         env.getEnvBool("SCHEMA_AUTO_MIGRATE", false),
+		PostgresURL:               env.getEnv("POSTGRES_URL", ""),
+		MaxConnections:            env.getEnvInt("MAX_CONNECTIONS", 25),
+		MaxIdleConnections:        env.getEnvInt("MAX_IDLE_CONNECTIONS", 10),
+		ConnectionMaxLifetime:     env.getEnvDuration("CONNECTION_MAX_LIFETIME", 300*time.Second),
+		ConnectionMaxIdleTime:     env.getEnvDuration("CONNECTION_MAX_IDLE_TIME", 60*time.Second),
+		PostgresTLS:               env.getTLSConfig("POSTGRES_TLS"),
+		PostgresReplicaURLs:       env.getEnv("POSTGRES_REPLICA_URLS", ""),
+		PostgresReplicaMaxLag:     env.getEnvDuration("POSTGRES_REPLICA_MAX_LAG", 5*time.Second),
+		PostgresReplicaCheckInterval: env.getEnvDuration("POSTGRES_REPLICA_CHECK_INTERVAL", 5*time.Second),
+		RedisURL:                  env.getEnv("REDIS_URL", ""),
+		RedisPoolSize:             env.getEnvInt("REDIS_POOL_SIZE", 10),
+		RedisMinIdleConns:         env.getEnvInt("REDIS_MIN_IDLE_CONNS", 2),
+		RedisMaxRetries:           env.getEnvInt("REDIS_MAX_RETRIES", 3),
+		RedisDialTimeout:          env.getEnvDuration("REDIS_DIAL_TIMEOUT", 5*time.Second),
+		RedisReadTimeout:          env.getEnvDuration("REDIS_READ_TIMEOUT", 3*time.Second),
+		RedisWriteTimeout:         env.getEnvDuration("REDIS_WRITE_TIMEOUT", 3*time.Second),
+		RedisTLS:                  env.getTLSConfig("REDIS_TLS"),
+		RedisMode:                 env.getEnv("REDIS_MODE", RedisModeStandalone),
+		RedisSentinelMaster:       env.getEnv("REDIS_SENTINEL_MASTER", ""),
+		RedisSentinelAddrs:        env.getEnv("REDIS_SENTINEL_ADDRS", ""),
+		RedisSentinelPassword:     env.getEnv("REDIS_SENTINEL_PASSWORD", ""),
+		RedisClusterAddrs:         env.getEnv("REDIS_CLUSTER_ADDRS", ""),
+		CacheTTL:                  env.getEnvDuration("CACHE_TTL", 300*time.Second),
+		CacheNamespace:            env.getEnv("CACHE_NAMESPACE", ""),
+		LocalCacheMaxEntries:      env.getEnvInt("LOCAL_CACHE_MAX_ENTRIES", 0),
+		LocalCacheTTL:             env.getEnvDuration("LOCAL_CACHE_TTL", 30*time.Second),
+		CacheRepositories:         env.getEnvBool("CACHE_REPOSITORIES", false),
+		AccountChecksEnabled:      env.getEnvBool("ACCOUNT_CHECKS_ENABLED", false),
+		AssetChecksEnabled:        env.getEnvBool("ASSET_CHECKS_ENABLED", false),
+		ConnectPolicy:             env.getEnv("CONNECT_POLICY", "lenient"),
+		ConnectRetryDeadline:      env.getEnvDuration("CONNECT_RETRY_DEADLINE", 60*time.Second),
+		ConnectInitialBackoff:     env.getEnvDuration("CONNECT_INITIAL_BACKOFF", 500*time.Millisecond),
+		ConnectMaxBackoff:         env.getEnvDuration("CONNECT_MAX_BACKOFF", 10*time.Second),
+		HealthCheckTimeout:        env.getEnvDuration("HEALTH_CHECK_TIMEOUT", 5*time.Second),
+		HealthDegradedLatency:     env.getEnvDuration("HEALTH_DEGRADED_LATENCY", 500*time.Millisecond),
+		CircuitBreakerEnabled:     env.getEnvBool("CIRCUIT_BREAKER_ENABLED", true),
+		CircuitBreakerFailureRate: env.getEnvFloat("CIRCUIT_BREAKER_FAILURE_RATE", 0.5),
+		CircuitBreakerMinRequests: env.getEnvInt("CIRCUIT_BREAKER_MIN_REQUESTS", 20),
+		CircuitBreakerWindow:      env.getEnvDuration("CIRCUIT_BREAKER_WINDOW", 30*time.Second),
+		CircuitBreakerOpenTimeout: env.getEnvDuration("CIRCUIT_BREAKER_OPEN_TIMEOUT", 15*time.Second),
+		CircuitBreakerHalfOpenRequests: env.getEnvInt("CIRCUIT_BREAKER_HALF_OPEN_REQUESTS", 3),
+		CacheFailOpen:             env.getEnvBool("CACHE_FAIL_OPEN", true),
+		ServiceDiscoveryNamespace: env.getEnv("SERVICE_DISCOVERY_NAMESPACE", "custodian"),
+		HeartbeatInterval:         env.getEnvDuration("HEARTBEAT_INTERVAL", 30*time.Second),
+		ServiceTTL:                env.getEnvDuration("SERVICE_TTL", 90*time.Second),
+		TestPostgresURL:           env.getEnv("TEST_POSTGRES_URL", ""),
+		TestRedisURL:              env.getEnv("TEST_REDIS_URL", ""),
+		LogLevel:                  env.getEnv("LOG_LEVEL", "info"),
+		LogFormat:                 env.getEnv("LOG_FORMAT", "json"),
+		LogRedaction:              env.getEnvBool("LOG_REDACTION", true),
+		LogRevealAtDebug:          env.getEnvBool("LOG_REVEAL_AT_DEBUG", false),
+		PerfTestSize:              env.getEnvInt("PERF_TEST_SIZE", 1000),
+		PerfThroughputMin:         env.getEnvInt("PERF_THROUGHPUT_MIN", 100),
+		PerfLatencyMax:            env.getEnvDuration("PERF_LATENCY_MAX", 100*time.Millisecond),
+		SkipIntegrationTests:      env.getEnvBool("SKIP_INTEGRATION_TESTS", false),
 	}
 
 	// Backward compatibility: Default ServiceInstanceName to ServiceName
@@ -106,16 +228,204 @@ func LoadConfig() (*Config, error) {
 		cfg.ServiceInstanceName = cfg.ServiceName
 	}
 
-	// Validate instance name
-	if err := ValidateInstanceName(cfg.ServiceInstanceName); err != nil {
-		// Log warning but don't fail - allow backward compatibility
-		// In production, this should be enforced
-		_ = err
+	// Passwords kept out of the URLs, typically in secret files
+	if password := env.getEnv("POSTGRES_PASSWORD", ""); password != "" {
+		cfg.PostgresURL = env.withPassword("POSTGRES_URL", cfg.PostgresURL, password)
+	}
+	if password := env.getEnv("REDIS_PASSWORD", ""); password != "" {
+		cfg.RedisURL = env.withPassword("REDIS_URL", cfg.RedisURL, password)
+	}
+
+	env.checkUnknownKeys()
+
+	// Report unparsable values together with invalid ones
+	errs := env.errs
+	if err := cfg.Validate(); err != nil {
+		errs = append(errs, err.(ValidationError)...)
+	}
+	if len(errs) > 0 {
+		return nil, ValidationError(errs)
 	}
 
 	return cfg, nil
 }
 
+// ValidationError lists every configuration problem found
+type ValidationError []error
+
+func (e ValidationError) Error() string {
+	problems := make([]string, len(e))
+	for i, err := range e {
+		problems[i] = err.Error()
+	}
+	return fmt.Sprintf("invalid configuration: %s", strings.Join(problems, "; "))
+}
+
+func (e ValidationError) Unwrap() []error {
+	return e
+}
+
+// Validate checks URLs, pool sizes and durations, and the instance name when
+// running in production. It returns a ValidationError listing all problems.
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	// URLs
+	for _, u := range []struct {
+		name    string
+		value   string
+		schemes []string
+	}{
+		{"POSTGRES_URL", c.PostgresURL, []string{"postgres", "postgresql"}},
+		{"TEST_POSTGRES_URL", c.TestPostgresURL, []string{"postgres", "postgresql"}},
+		{"REDIS_URL", c.RedisURL, []string{"redis", "rediss", "unix"}},
+		{"TEST_REDIS_URL", c.TestRedisURL, []string{"redis", "rediss", "unix"}},
+	} {
+		if u.value != "" {
+			if err := validateURL(u.value, u.schemes); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", u.name, err))
+			}
+		}
+	}
+
+	for _, replicaURL := range c.ReplicaURLs() {
+		if err := validateURL(replicaURL, []string{"postgres", "postgresql"}); err != nil {
+			errs = append(errs, fmt.Errorf("POSTGRES_REPLICA_URLS: %w", err))
+		}
+	}
+
+	// TLS
+	errs = append(errs, c.PostgresTLS.validate("POSTGRES_TLS")...)
+	errs = append(errs, c.RedisTLS.validate("REDIS_TLS")...)
+
+	// Pool sizes
+	check(c.MaxConnections > 0, "MAX_CONNECTIONS must be positive (got %d)", c.MaxConnections)
+	check(c.MaxIdleConnections >= 0 && c.MaxIdleConnections <= c.MaxConnections,
+		"MAX_IDLE_CONNECTIONS must be between 0 and MAX_CONNECTIONS (got %d, max %d)", c.MaxIdleConnections, c.MaxConnections)
+	check(c.RedisPoolSize > 0, "REDIS_POOL_SIZE must be positive (got %d)", c.RedisPoolSize)
+	check(c.RedisMinIdleConns >= 0 && c.RedisMinIdleConns <= c.RedisPoolSize,
+		"REDIS_MIN_IDLE_CONNS must be between 0 and REDIS_POOL_SIZE (got %d, pool size %d)", c.RedisMinIdleConns, c.RedisPoolSize)
+	check(c.RedisMaxRetries >= -1, "REDIS_MAX_RETRIES must be -1 (disabled) or more (got %d)", c.RedisMaxRetries)
+	check(c.LocalCacheMaxEntries >= 0, "LOCAL_CACHE_MAX_ENTRIES must not be negative (got %d)", c.LocalCacheMaxEntries)
+
+	// Durations
+	for _, d := range []struct {
+		name  string
+		value time.Duration
+	}{
+		{"CONNECTION_MAX_LIFETIME", c.ConnectionMaxLifetime},
+		{"CONNECTION_MAX_IDLE_TIME", c.ConnectionMaxIdleTime},
+		{"POSTGRES_REPLICA_MAX_LAG", c.PostgresReplicaMaxLag},
+		{"POSTGRES_REPLICA_CHECK_INTERVAL", c.PostgresReplicaCheckInterval},
+		{"REDIS_DIAL_TIMEOUT", c.RedisDialTimeout},
+		{"REDIS_READ_TIMEOUT", c.RedisReadTimeout},
+		{"REDIS_WRITE_TIMEOUT", c.RedisWriteTimeout},
+		{"CACHE_TTL", c.CacheTTL},
+		{"LOCAL_CACHE_TTL", c.LocalCacheTTL},
+		{"CONNECT_RETRY_DEADLINE", c.ConnectRetryDeadline},
+		{"CONNECT_INITIAL_BACKOFF", c.ConnectInitialBackoff},
+		{"CONNECT_MAX_BACKOFF", c.ConnectMaxBackoff},
+		{"HEALTH_CHECK_TIMEOUT", c.HealthCheckTimeout},
+		{"HEALTH_DEGRADED_LATENCY", c.HealthDegradedLatency},
+		{"CIRCUIT_BREAKER_WINDOW", c.CircuitBreakerWindow},
+		{"CIRCUIT_BREAKER_OPEN_TIMEOUT", c.CircuitBreakerOpenTimeout},
+	} {
+		check(d.value >= 0, "%s must not be negative (got %s)", d.name, d.value)
+	}
+	check(c.HeartbeatInterval > 0, "HEARTBEAT_INTERVAL must be positive (got %s)", c.HeartbeatInterval)
+	check(c.ServiceTTL > c.HeartbeatInterval,
+		"SERVICE_TTL must exceed HEARTBEAT_INTERVAL (got %s, heartbeat %s)", c.ServiceTTL, c.HeartbeatInterval)
+	check(c.ConnectInitialBackoff <= c.ConnectMaxBackoff || c.ConnectMaxBackoff == 0,
+		"CONNECT_INITIAL_BACKOFF must not exceed CONNECT_MAX_BACKOFF (got %s, max %s)", c.ConnectInitialBackoff, c.ConnectMaxBackoff)
+
+	// Redis topology
+	switch c.RedisMode {
+	case "", RedisModeStandalone:
+		check(c.RedisSentinelMaster == "" && c.RedisSentinelAddrs == "" && c.RedisClusterAddrs == "",
+			"REDIS_SENTINEL_* and REDIS_CLUSTER_ADDRS need REDIS_MODE sentinel or cluster")
+	case RedisModeSentinel:
+		check(c.RedisSentinelMaster != "", "REDIS_SENTINEL_MASTER is required in sentinel mode")
+		check(len(c.SentinelAddrs()) > 0, "REDIS_SENTINEL_ADDRS is required in sentinel mode")
+	case RedisModeCluster:
+		check(c.RedisSentinelMaster == "" && c.RedisSentinelAddrs == "", "REDIS_SENTINEL_* does not apply in cluster mode")
+	default:
+		errs = append(errs, fmt.Errorf("REDIS_MODE must be standalone, sentinel or cluster (got %q)", c.RedisMode))
+	}
+
+	// Connection policy and circuit breakers
+	switch c.ConnectPolicy {
+	case "", "strict", "retry", "lenient":
+	default:
+		errs = append(errs, fmt.Errorf("CONNECT_POLICY must be strict, retry or lenient (got %q)", c.ConnectPolicy))
+	}
+	if c.CircuitBreakerEnabled {
+		check(c.CircuitBreakerFailureRate > 0 && c.CircuitBreakerFailureRate <= 1,
+			"CIRCUIT_BREAKER_FAILURE_RATE must be in (0, 1] (got %g)", c.CircuitBreakerFailureRate)
+		check(c.CircuitBreakerMinRequests >= 0, "CIRCUIT_BREAKER_MIN_REQUESTS must not be negative (got %d)", c.CircuitBreakerMinRequests)
+		check(c.CircuitBreakerHalfOpenRequests >= 0, "CIRCUIT_BREAKER_HALF_OPEN_REQUESTS must not be negative (got %d)", c.CircuitBreakerHalfOpenRequests)
+	}
+
+	// Instance names are DNS labels in production; elsewhere legacy names are tolerated
+	if c.Environment == EnvironmentProduction {
+		if err := ValidateInstanceName(c.ServiceInstanceName); err != nil {
+			errs = append(errs, fmt.Errorf("SERVICE_INSTANCE_NAME: %w", err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return ValidationError(errs)
+	}
+	return nil
+}
+
+// ReplicaURLs splits PostgresReplicaURLs
+func (c *Config) ReplicaURLs() []string {
+	return splitList(c.PostgresReplicaURLs)
+}
+
+// SentinelAddrs splits RedisSentinelAddrs
+func (c *Config) SentinelAddrs() []string {
+	return splitList(c.RedisSentinelAddrs)
+}
+
+// ClusterAddrs splits RedisClusterAddrs
+func (c *Config) ClusterAddrs() []string {
+	return splitList(c.RedisClusterAddrs)
+}
+
+// splitList splits a comma-separated setting, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// validateURL checks that value is an absolute URL with one of schemes
+func validateURL(value string, schemes []string) error {
+	parsed, err := url.Parse(value)
+	if err != nil {
+		return fmt.Errorf("invalid URL: %w", err)
+	}
+	for _, scheme := range schemes {
+		if parsed.Scheme == scheme {
+			if parsed.Host == "" && scheme != "unix" {
+				return fmt.Errorf("URL has no host")
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("URL scheme must be one of %s (got %q)", strings.Join(schemes, ", "), parsed.Scheme)
+}
+
 // ValidateInstanceName validates that an instance name follows DNS-safe naming conventions
 func ValidateInstanceName(name string) error {
 	// Required explicit - no empty strings
@@ -137,36 +447,117 @@ func ValidateInstanceName(name string) error {
 	return nil
 }
 
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
+func (p *envParser) getEnv(key, defaultValue string) string {
+	if value := p.lookup(key); value != "" {
 		return value
 	}
 	return defaultValue
 }
 
-func getEnvInt(key string, defaultValue int) int {
-	if value := os.Getenv(key); value != "" {
-		if intVal, err := strconv.Atoi(value); err == nil {
-			return intVal
+// envParser resolves settings through the configuration layers and collects
+// values that are set but cannot be parsed, so LoadConfig can report them
+// instead of silently using the default
+type envParser struct {
+	source *fileSource
+	used   map[string]bool
+	errs   []error
+}
+
+// lookup returns the highest-priority value of key: the environment, then
+// the profile, then the top level of the file. Within each layer <KEY>_FILE
+// is consulted when key itself is not set.
+func (p *envParser) lookup(key string) string {
+	p.used[key] = true
+	layers := []func(string) string{os.Getenv, p.source.profileValue, p.source.value}
+
+	for _, layer := range layers {
+		if value := layer(key); value != "" {
+			return value
+		}
+		if path := layer(key + "_FILE"); path != "" {
+			value, err := readSecretFile(path)
+			if err != nil {
+				p.errs = append(p.errs, fmt.Errorf("%s_FILE: %w", key, err))
+				return ""
+			}
+			return value
+		}
+	}
+	return ""
+}
+
+// withPassword sets the password in the URL of setting key
+func (p *envParser) withPassword(key, rawURL, password string) string {
+	if rawURL == "" {
+		p.errs = append(p.errs, fmt.Errorf("%s: password given but URL not set", key))
+		return rawURL
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		// Validate reports the URL itself
+		return rawURL
+	}
+	parsed.User = url.UserPassword(parsed.User.Username(), password)
+	return parsed.String()
+}
+
+// checkUnknownKeys reports file settings that no field reads, usually typos
+func (p *envParser) checkUnknownKeys() {
+	for _, key := range p.source.keys() {
+		if !p.used[key] && !p.used[strings.TrimSuffix(key, "_FILE")] {
+			p.errs = append(p.errs, fmt.Errorf("%s: unknown setting in %s", key, p.source.path))
+		}
+	}
+}
+
+func (p *envParser) invalid(key, value, expected string) {
+	p.errs = append(p.errs, fmt.Errorf("%s: invalid %s %q", key, expected, value))
+}
+
+func (p *envParser) getEnvInt(key string, defaultValue int) int {
+	if value := p.lookup(key); value != "" {
+		intVal, err := strconv.Atoi(value)
+		if err != nil {
+			p.invalid(key, value, "integer")
+			return defaultValue
+		}
+		return intVal
+	}
+	return defaultValue
+}
+
+func (p *envParser) getEnvFloat(key string, defaultValue float64) float64 {
+	if value := p.lookup(key); value != "" {
+		floatVal, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			p.invalid(key, value, "number")
+			return defaultValue
 		}
+		return floatVal
 	}
 	return defaultValue
 }
 
-func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
-	if value := os.Getenv(key); value != "" {
-		if duration, err := time.ParseDuration(value); err == nil {
-			return duration
+func (p *envParser) getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := p.lookup(key); value != "" {
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			p.invalid(key, value, "duration")
+			return defaultValue
 		}
+		return duration
 	}
 	return defaultValue
 }
 
-func getEnvBool(key string, defaultValue bool) bool {
-	if value := os.Getenv(key); value != "" {
-		if boolVal, err := strconv.ParseBool(value); err == nil {
-			return boolVal
+func (p *envParser) getEnvBool(key string, defaultValue bool) bool {
+	if value := p.lookup(key); value != "" {
+		boolVal, err := strconv.ParseBool(value)
+		if err != nil {
+			p.invalid(key, value, "boolean")
+			return defaultValue
 		}
+		return boolVal
 	}
 	return defaultValue
 }
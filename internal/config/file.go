@@ -0,0 +1,150 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// profilesKey holds per-environment overrides in a configuration file
+const profilesKey = "PROFILES"
+
+// fileSource is a parsed configuration file. Keys are the environment
+// variable names, so "cache_ttl: 5m" in a file sets CACHE_TTL.
+type fileSource struct {
+	path     string
+	base     map[string]string
+	profiles map[string]map[string]string
+
+	// profile selected by the environment
+	profile string
+}
+
+// readConfigFile parses a YAML or JSON file (by extension) and selects the
+// profile for ENVIRONMENT, taken from the environment or the file
+func readConfigFile(path string) (*fileSource, error) {
+	source := &fileSource{path: path}
+	if path == "" {
+		return source, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	raw := map[string]interface{}{}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = json.Unmarshal(data, &raw)
+	} else {
+		err = yaml.Unmarshal(data, &raw)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	source.base, err = flattenSettings(raw)
+	if err != nil {
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+
+	source.profiles = map[string]map[string]string{}
+	for key, value := range raw {
+		if strings.ToUpper(key) != profilesKey {
+			continue
+		}
+		profiles, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("config file %s: profiles must map environment names to settings", path)
+		}
+		for name, settings := range profiles {
+			settingsMap, ok := settings.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("config file %s: profile %s must be a map of settings", path, name)
+			}
+			if source.profiles[name], err = flattenSettings(settingsMap); err != nil {
+				return nil, fmt.Errorf("config file %s: profile %s: %w", path, name, err)
+			}
+		}
+	}
+
+	source.profile = os.Getenv("ENVIRONMENT")
+	if source.profile == "" {
+		source.profile = source.base["ENVIRONMENT"]
+	}
+	if source.profile == "" {
+		source.profile = "development"
+	}
+
+	return source, nil
+}
+
+// flattenSettings converts scalar settings to strings keyed by upper-case name
+func flattenSettings(raw map[string]interface{}) (map[string]string, error) {
+	settings := make(map[string]string, len(raw))
+	for key, value := range raw {
+		name := strings.ToUpper(key)
+		if name == profilesKey {
+			continue
+		}
+
+		switch v := value.(type) {
+		case nil:
+			settings[name] = ""
+		case string:
+			settings[name] = v
+		case bool:
+			settings[name] = strconv.FormatBool(v)
+		case int:
+			settings[name] = strconv.Itoa(v)
+		case float64:
+			settings[name] = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			return nil, fmt.Errorf("%s must be a single value", key)
+		}
+	}
+	return settings, nil
+}
+
+func (s *fileSource) value(key string) string {
+	return s.base[key]
+}
+
+func (s *fileSource) profileValue(key string) string {
+	return s.profiles[s.profile][key]
+}
+
+// keys lists every setting in the file, including all profiles
+func (s *fileSource) keys() []string {
+	seen := map[string]bool{}
+	for key := range s.base {
+		seen[key] = true
+	}
+	for _, profile := range s.profiles {
+		for key := range profile {
+			seen[key] = true
+		}
+	}
+
+	keys := make([]string, 0, len(seen))
+	for key := range seen {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// readSecretFile reads a value kept in its own file, such as a mounted secret
+func readSecretFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret file: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// TLSConfig configures TLS for connections to a store. Files are PEM encoded.
+type TLSConfig struct {
+	Enabled    bool
+	CAFile     string // CA bundle verifying the server (system roots if empty)
+	CertFile   string // Client certificate for mutual TLS
+	KeyFile    string // Client certificate key
+	ServerName string // Name verified against the server certificate (host if empty)
+	MinVersion string // "1.2" (default) or "1.3"
+}
+
+// tlsVersions maps MinVersion settings to crypto/tls versions
+var tlsVersions = map[string]uint16{
+	"":    tls.VersionTLS12,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// Build loads the certificates and returns the client TLS configuration, or
+// nil if TLS is disabled. serverName applies when ServerName is not set.
+func (t TLSConfig) Build(serverName string) (*tls.Config, error) {
+	if !t.Enabled {
+		return nil, nil
+	}
+
+	tlsConfig, errs := t.build(serverName)
+	if len(errs) > 0 {
+		return nil, ValidationError(errs)
+	}
+	return tlsConfig, nil
+}
+
+func (t TLSConfig) build(serverName string) (*tls.Config, []error) {
+	var errs []error
+	tlsConfig := &tls.Config{ServerName: serverName}
+	if t.ServerName != "" {
+		tlsConfig.ServerName = t.ServerName
+	}
+
+	minVersion, ok := tlsVersions[t.MinVersion]
+	if !ok {
+		errs = append(errs, fmt.Errorf("minimum TLS version must be 1.2 or 1.3 (got %q)", t.MinVersion))
+	}
+	tlsConfig.MinVersion = minVersion
+
+	if t.CAFile != "" {
+		pem, err := os.ReadFile(t.CAFile)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("CA bundle: %w", err))
+		} else {
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(pem) {
+				errs = append(errs, fmt.Errorf("CA bundle %s contains no PEM certificates", t.CAFile))
+			}
+			tlsConfig.RootCAs = pool
+		}
+	}
+
+	switch {
+	case t.CertFile == "" && t.KeyFile == "":
+	case t.CertFile == "" || t.KeyFile == "":
+		errs = append(errs, fmt.Errorf("client certificate and key must be set together"))
+	default:
+		if err := requireFile(t.CertFile, "client certificate"); err != nil {
+			errs = append(errs, err)
+		}
+		if err := requireFile(t.KeyFile, "client key"); err != nil {
+			errs = append(errs, err)
+		}
+		if len(errs) == 0 {
+			certificate, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to load client certificate: %w", err))
+			}
+			tlsConfig.Certificates = []tls.Certificate{certificate}
+		}
+	}
+
+	return tlsConfig, errs
+}
+
+// validate reports problems with the settings under the env prefix
+func (t TLSConfig) validate(prefix string) []error {
+	if !t.Enabled {
+		if t.CAFile != "" || t.CertFile != "" || t.KeyFile != "" {
+			return []error{fmt.Errorf("%s files are set but %s_ENABLED is false", prefix, prefix)}
+		}
+		return nil
+	}
+
+	_, errs := t.build("")
+	for i, err := range errs {
+		errs[i] = fmt.Errorf("%s: %w", prefix, err)
+	}
+	return errs
+}
+
+func requireFile(path, description string) error {
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("%s: %w", description, err)
+	}
+	return nil
+}
+
+// getTLSConfig reads the TLS settings under the env prefix
+func (p *envParser) getTLSConfig(prefix string) TLSConfig {
+	return TLSConfig{
+		Enabled:    p.getEnvBool(prefix+"_ENABLED", false),
+		CAFile:     p.getEnv(prefix+"_CA_FILE", ""),
+		CertFile:   p.getEnv(prefix+"_CERT_FILE", ""),
+		KeyFile:    p.getEnv(prefix+"_KEY_FILE", ""),
+		ServerName: p.getEnv(prefix+"_SERVER_NAME", ""),
+		MinVersion: p.getEnv(prefix+"_MIN_VERSION", "1.2"),
+	}
+}
@@ -0,0 +1,230 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// defaultWatchInterval applies when NewWatcher is given no interval
+const defaultWatchInterval = 5 * time.Second
+
+// ReloadableSettings are the values a Watcher applies without a restart
+type ReloadableSettings struct {
+	LogLevel          string
+	CacheTTL          time.Duration
+	LocalCacheTTL     time.Duration
+	HeartbeatInterval time.Duration
+	ServiceTTL        time.Duration
+}
+
+// Reloadable returns the settings of c that may change at runtime
+func (c *Config) Reloadable() ReloadableSettings {
+	return ReloadableSettings{
+		LogLevel:          c.LogLevel,
+		CacheTTL:          c.CacheTTL,
+		LocalCacheTTL:     c.LocalCacheTTL,
+		HeartbeatInterval: c.HeartbeatInterval,
+		ServiceTTL:        c.ServiceTTL,
+	}
+}
+
+// apply copies the reloadable settings into c
+func (s ReloadableSettings) apply(c *Config) {
+	c.LogLevel = s.LogLevel
+	c.CacheTTL = s.CacheTTL
+	c.LocalCacheTTL = s.LocalCacheTTL
+	c.HeartbeatInterval = s.HeartbeatInterval
+	c.ServiceTTL = s.ServiceTTL
+}
+
+// Subscriber is notified with the previous and new settings after a reload
+type Subscriber func(previous, current ReloadableSettings)
+
+// Watcher polls a configuration file and hot-reloads the reloadable
+// settings. Other changes take effect on restart; a file that fails to load
+// or validate is reported and the current settings are kept.
+type Watcher struct {
+	path     string
+	interval time.Duration
+
+	mu          sync.Mutex
+	current     *Config
+	modTime     time.Time
+	subscribers map[int]Subscriber
+	nextID      int
+	onError     func(error)
+	stop        chan struct{} // Non-nil while started
+	done        chan struct{} // Closed when the started poller returns
+}
+
+// NewWatcher loads the configuration file at path and watches it for
+// changes. Use Current for the configuration to start from.
+func NewWatcher(path string, interval time.Duration) (*Watcher, error) {
+	if interval <= 0 {
+		interval = defaultWatchInterval
+	}
+
+	cfg, err := LoadConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Watcher{
+		path:        path,
+		interval:    interval,
+		current:     cfg,
+		subscribers: map[int]Subscriber{},
+		onError:     func(error) {},
+	}
+	if info, err := os.Stat(path); err == nil {
+		w.modTime = info.ModTime()
+	}
+	return w, nil
+}
+
+// Subscribe registers fn for reload notifications and returns a function
+// that removes it
+func (w *Watcher) Subscribe(fn Subscriber) (unsubscribe func()) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	id := w.nextID
+	w.nextID++
+	w.subscribers[id] = fn
+
+	return func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		delete(w.subscribers, id)
+	}
+}
+
+// OnError sets the handler for files that fail to load and for changes
+// that need a restart
+func (w *Watcher) OnError(fn func(error)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.onError = fn
+}
+
+// Current returns a copy of the configuration with reloads applied
+func (w *Watcher) Current() *Config {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	cfg := *w.current
+	return &cfg
+}
+
+// Start polls the file until ctx is done or Stop is called; starting a
+// running watcher does nothing
+func (w *Watcher) Start(ctx context.Context) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.stop != nil {
+		return
+	}
+	stop, done := make(chan struct{}), make(chan struct{})
+	w.stop, w.done = stop, done
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-stop:
+				return
+			case <-ticker.C:
+				w.checkModified()
+			}
+		}
+	}()
+}
+
+// Stop ends polling started by Start
+func (w *Watcher) Stop() {
+	w.mu.Lock()
+	stop, done := w.stop, w.done
+	w.stop, w.done = nil, nil
+	w.mu.Unlock()
+
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-done
+}
+
+// checkModified reloads when the file's modification time changes
+func (w *Watcher) checkModified() {
+	info, err := os.Stat(w.path)
+	if err != nil {
+		w.reportError(fmt.Errorf("failed to stat config file: %w", err))
+		return
+	}
+
+	w.mu.Lock()
+	modified := !info.ModTime().Equal(w.modTime)
+	w.modTime = info.ModTime()
+	w.mu.Unlock()
+
+	if modified {
+		if err := w.Reload(); err != nil {
+			w.reportError(err)
+		}
+	}
+}
+
+// Reload re-reads the file now and notifies subscribers if a reloadable
+// setting changed
+func (w *Watcher) Reload() error {
+	next, err := LoadConfigFile(w.path)
+	if err != nil {
+		return fmt.Errorf("config reload rejected: %w", err)
+	}
+
+	w.mu.Lock()
+	previous := w.current.Reloadable()
+	current := next.Reloadable()
+
+	// Changes to anything else wait for a restart
+	restartNeeded := *next
+	previous.apply(&restartNeeded)
+	pending := restartNeeded != *w.current
+
+	var subscribers []Subscriber
+	if current != previous {
+		updated := *w.current
+		current.apply(&updated)
+		w.current = &updated
+
+		for _, fn := range w.subscribers {
+			subscribers = append(subscribers, fn)
+		}
+	}
+	w.mu.Unlock()
+
+	for _, fn := range subscribers {
+		fn(previous, current)
+	}
+
+	if pending {
+		return fmt.Errorf("config file %s changed settings that require a restart", w.path)
+	}
+	return nil
+}
+
+func (w *Watcher) reportError(err error) {
+	w.mu.Lock()
+	onError := w.onError
+	w.mu.Unlock()
+	onError(err)
+}
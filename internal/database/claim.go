@@ -0,0 +1,86 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ErrClaimUnavailable means the owner registry is missing and cannot be
+// created, because the schema has not been provisioned or the connection
+// lacks the privileges
+var ErrClaimUnavailable = errors.New("instance owner registry unavailable")
+
+// ownerTableDDL creates the single-row registry naming the instance that owns
+// a schema; %[1]s is the quoted schema
+const ownerTableDDL = `CREATE TABLE IF NOT EXISTS %[1]s.instance_owner (
+	singleton BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (singleton),
+	instance_name TEXT NOT NULL,
+	claimed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+	heartbeat_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+)`
+
+// claimQuery takes over the registry row when it is ours or its heartbeat
+// is older than the TTL, returning no row while another instance holds it
+const claimQuery = `
+	INSERT INTO %[1]s.instance_owner (instance_name) VALUES ($1)
+	ON CONFLICT (singleton) DO UPDATE SET
+		instance_name = EXCLUDED.instance_name,
+		heartbeat_at = NOW(),
+		claimed_at = CASE
+			WHEN instance_owner.instance_name = EXCLUDED.instance_name THEN instance_owner.claimed_at
+			ELSE NOW()
+		END
+	WHERE instance_owner.instance_name = EXCLUDED.instance_name
+	   OR instance_owner.heartbeat_at < NOW() - make_interval(secs => $2)
+	RETURNING instance_name
+`
+
+// ClaimSchema records instance as the owner of schema, or refreshes its
+// claim. A claim not refreshed within ttl has lapsed and may be taken over.
+// It returns the owner: instance on success, otherwise the live instance
+// holding the schema.
+func ClaimSchema(ctx context.Context, db *sql.DB, schema, instance string, ttl time.Duration) (string, error) {
+	quoted := pq.QuoteIdentifier(schema)
+
+	owner, err := claimSchema(ctx, db, quoted, instance, ttl)
+	if isPQError(err, "42P01") {
+		// Schemas not created by ProvisionSchema get the registry on first claim
+		if _, err := db.ExecContext(ctx, fmt.Sprintf(ownerTableDDL, quoted)); err != nil {
+			if isPQError(err, "42501") || isPQError(err, "3F000") {
+				return "", fmt.Errorf("%w: %v", ErrClaimUnavailable, err)
+			}
+			return "", fmt.Errorf("failed to create instance owner registry: %w", err)
+		}
+		owner, err = claimSchema(ctx, db, quoted, instance, ttl)
+	}
+	if isPQError(err, "42501") || isPQError(err, "3F000") {
+		return "", fmt.Errorf("%w: %v", ErrClaimUnavailable, err)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to claim schema %s: %w", schema, err)
+	}
+	return owner, nil
+}
+
+func claimSchema(ctx context.Context, db *sql.DB, quoted, instance string, ttl time.Duration) (string, error) {
+	var owner string
+	err := db.QueryRowContext(ctx, fmt.Sprintf(claimQuery, quoted), instance, ttl.Seconds()).Scan(&owner)
+	if err != sql.ErrNoRows {
+		return owner, err
+	}
+
+	// Another instance holds a live claim
+	err = db.QueryRowContext(ctx, fmt.Sprintf(`SELECT instance_name FROM %s.instance_owner`, quoted)).Scan(&owner)
+	return owner, err
+}
+
+// isPQError reports whether err is a PostgreSQL error with code
+func isPQError(err error, code pq.ErrorCode) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == code
+}
@@ -0,0 +1,64 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// failingDatabase fails every statement with a PostgreSQL error code
+type failingDatabase struct {
+	code pq.ErrorCode
+}
+
+func (d failingDatabase) Connect(ctx context.Context) (driver.Conn, error) {
+	return failingConn(d), nil
+}
+func (d failingDatabase) Driver() driver.Driver { return nil }
+
+type failingConn struct {
+	code pq.ErrorCode
+}
+
+func (c failingConn) Prepare(query string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (c failingConn) Close() error                              { return nil }
+func (c failingConn) Begin() (driver.Tx, error)                 { return nil, driver.ErrSkip }
+
+func (c failingConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return nil, &pq.Error{Code: c.code}
+}
+
+func (c failingConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return nil, &pq.Error{Code: c.code}
+}
+
+// TestClaimSchemaUnavailable tests which failures skip the claim instead of failing Connect
+func TestClaimSchemaUnavailable(t *testing.T) {
+	tests := []struct {
+		name        string
+		code        pq.ErrorCode
+		unavailable bool
+	}{
+		{name: "schema not provisioned", code: "3F000", unavailable: true},
+		{name: "insufficient privilege", code: "42501", unavailable: true},
+		{name: "connection failure", code: "08006", unavailable: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := sql.OpenDB(failingDatabase{code: tt.code})
+			_, err := ClaimSchema(context.Background(), db, "custodian_komainu", "custodian-Komainu", time.Minute)
+			if err == nil {
+				t.Fatal("ClaimSchema succeeded, expected an error")
+			}
+			if errors.Is(err, ErrClaimUnavailable) != tt.unavailable {
+				t.Errorf("ClaimSchema error = %v, ErrClaimUnavailable expected: %v", err, tt.unavailable)
+			}
+		})
+	}
+}
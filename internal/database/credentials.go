@@ -0,0 +1,146 @@
+package database
+
+import (
+	"context"
+	"crypto/tls"
+	"database/sql/driver"
+	"fmt"
+	"net/url"
+	"sync/atomic"
+
+	"github.com/lib/pq"
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/interfaces"
+)
+
+// credentialsConnector opens PostgreSQL connections with the provider's
+// current credentials. Connections remember the credentials generation they
+// were opened with, and are closed instead of reused once it is rotated.
+type credentialsConnector struct {
+	baseURL     *url.URL
+	credentials interfaces.CredentialsProvider
+	generation  atomic.Uint64
+
+	// open dials the DSN (pq by default; replaced in tests)
+	open func(ctx context.Context, dsn string) (driver.Conn, error)
+}
+
+func newCredentialsConnector(rawURL string, credentials interfaces.CredentialsProvider, tlsConfig *tls.Config) (*credentialsConnector, error) {
+	baseURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse PostgreSQL URL: %w", err)
+	}
+
+	return &credentialsConnector{
+		baseURL:     baseURL,
+		credentials: credentials,
+		open: func(ctx context.Context, dsn string) (driver.Conn, error) {
+			connector, err := newPQConnector(dsn, tlsConfig)
+			if err != nil {
+				return nil, err
+			}
+			return connector.Connect(ctx)
+		},
+	}, nil
+}
+
+func (c *credentialsConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	// Read the generation first so a rotation during the dial marks the
+	// connection stale
+	generation := c.generation.Load()
+
+	credentials, err := c.credentials.Credentials(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get PostgreSQL credentials: %w", err)
+	}
+
+	dsn := *c.baseURL
+	username := credentials.Username
+	if username == "" {
+		username = c.baseURL.User.Username()
+	}
+	dsn.User = url.UserPassword(username, credentials.Password)
+
+	conn, err := c.open(ctx, dsn.String())
+	if err != nil {
+		return nil, err
+	}
+	return &credentialsConn{Conn: conn, generation: generation, connector: c}, nil
+}
+
+func (c *credentialsConnector) Driver() driver.Driver {
+	return &pq.Driver{}
+}
+
+// rotate marks every open connection stale
+func (c *credentialsConnector) rotate() {
+	c.generation.Add(1)
+}
+
+// credentialsConn forwards to the driver connection and reports itself
+// invalid once the credentials it was opened with are rotated
+type credentialsConn struct {
+	driver.Conn
+	generation uint64
+	connector  *credentialsConnector
+}
+
+func (c *credentialsConn) stale() bool {
+	return c.generation != c.connector.generation.Load()
+}
+
+// IsValid is checked when the connection is returned to the pool
+func (c *credentialsConn) IsValid() bool {
+	if c.stale() {
+		return false
+	}
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+// ResetSession is called before a pooled connection is reused
+func (c *credentialsConn) ResetSession(ctx context.Context) error {
+	if c.stale() {
+		return driver.ErrBadConn
+	}
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *credentialsConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *credentialsConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return preparer.PrepareContext(ctx, query)
+	}
+	return c.Conn.Prepare(query)
+}
+
+func (c *credentialsConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin()
+}
+
+func (c *credentialsConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if queryer, ok := c.Conn.(driver.QueryerContext); ok {
+		return queryer.QueryContext(ctx, query, args)
+	}
+	return nil, driver.ErrSkip
+}
+
+func (c *credentialsConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if execer, ok := c.Conn.(driver.ExecerContext); ok {
+		return execer.ExecContext(ctx, query, args)
+	}
+	return nil, driver.ErrSkip
+}
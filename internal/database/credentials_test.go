@@ -0,0 +1,139 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/internal/config"
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/interfaces"
+)
+
+type rotatingCredentials struct {
+	mu          sync.Mutex
+	current     interfaces.Credentials
+	subscribers []func()
+}
+
+func (c *rotatingCredentials) Credentials(ctx context.Context) (interfaces.Credentials, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.current, nil
+}
+
+func (c *rotatingCredentials) Subscribe(fn func()) func() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.subscribers = append(c.subscribers, fn)
+	return func() {}
+}
+
+func (c *rotatingCredentials) rotate(password string) {
+	c.mu.Lock()
+	c.current.Password = password
+	subscribers := c.subscribers
+	c.mu.Unlock()
+	for _, fn := range subscribers {
+		fn()
+	}
+}
+
+// fakeConn records the password it was opened with and whether it was closed
+type fakeConn struct {
+	password string
+	closed   bool
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (c *fakeConn) Begin() (driver.Tx, error)                 { return nil, driver.ErrSkip }
+func (c *fakeConn) Ping(ctx context.Context) error            { return nil }
+func (c *fakeConn) Close() error {
+	c.closed = true
+	return nil
+}
+
+type nopLogger struct{}
+
+func (l nopLogger) WithField(key string, value interface{}) interfaces.Logger { return l }
+func (l nopLogger) WithFields(fields interfaces.LogFields) interfaces.Logger  { return l }
+func (l nopLogger) WithError(err error) interfaces.Logger                     { return l }
+func (l nopLogger) WithContext(ctx context.Context) interfaces.Logger         { return l }
+func (l nopLogger) Debug(msg string)                                          {}
+func (l nopLogger) Info(msg string)                                           {}
+func (l nopLogger) Warn(msg string)                                           {}
+func (l nopLogger) Error(msg string)                                          {}
+func (l nopLogger) Enabled(level interfaces.LogLevel) bool                    { return false }
+
+// TestCredentialRotation tests that rotated credentials are used for new
+// connections and that pooled connections are recycled
+func TestCredentialRotation(t *testing.T) {
+	credentials := &rotatingCredentials{current: interfaces.Credentials{Password: "first"}}
+	p, err := NewPostgresDB(&config.Config{
+		PostgresURL:        "postgres://custodian@localhost:5432/trading_ecosystem",
+		MaxConnections:     5,
+		MaxIdleConnections: 5,
+	}, credentials, nopLogger{})
+	if err != nil {
+		t.Fatalf("NewPostgresDB failed: %v", err)
+	}
+	defer p.Disconnect(context.Background())
+
+	var mu sync.Mutex
+	var opened []*fakeConn
+	p.connector.open = func(ctx context.Context, dsn string) (driver.Conn, error) {
+		parsed, err := url.Parse(dsn)
+		if err != nil {
+			return nil, err
+		}
+		password, _ := parsed.User.Password()
+		conn := &fakeConn{password: password}
+		mu.Lock()
+		opened = append(opened, conn)
+		mu.Unlock()
+		return conn, nil
+	}
+
+	ctx := context.Background()
+
+	// Hold one connection and leave a second idle, both with the first credentials
+	if err := p.DB.PingContext(ctx); err != nil {
+		t.Fatalf("PingContext failed: %v", err)
+	}
+	busy, err := p.DB.Conn(ctx)
+	if err != nil {
+		t.Fatalf("Conn failed: %v", err)
+	}
+	if len(opened) != 1 {
+		t.Fatalf("opened %d connections, expected the idle one reused", len(opened))
+	}
+	extra, err := p.DB.Conn(ctx)
+	if err != nil {
+		t.Fatalf("Conn failed: %v", err)
+	}
+	extra.Close()
+
+	credentials.rotate("second")
+
+	if !opened[1].closed {
+		t.Error("idle connection not closed on rotation")
+	}
+	if opened[0].closed {
+		t.Error("busy connection closed while in use")
+	}
+	if err := busy.PingContext(ctx); err != nil {
+		t.Errorf("busy connection unusable after rotation: %v", err)
+	}
+	busy.Close()
+	if !opened[0].closed {
+		t.Error("busy connection not closed when released")
+	}
+
+	if err := p.DB.PingContext(ctx); err != nil {
+		t.Fatalf("PingContext failed: %v", err)
+	}
+	if last := opened[len(opened)-1]; last.password != "second" {
+		t.Errorf("new connection password = %s, expected rotated credentials", last.password)
+	}
+}
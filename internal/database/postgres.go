@@ -8,36 +8,93 @@ import (
 
 	_ "github.com/lib/pq"
 	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/internal/config"
-	"github.com/sirupsen/logrus"
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/interfaces"
 )
 
 type PostgresDB struct {
 	DB     *sql.DB
 	config *config.Config
-	logger *logrus.Logger
+	logger interfaces.Logger
+
+	// external pools belong to the caller and are not closed on Disconnect
+	external bool
+
+	// Credential rotation (nil when the URL's credentials are used)
+	connector   *credentialsConnector
+	unsubscribe func()
 }
 
-func NewPostgresDB(cfg *config.Config, logger *logrus.Logger) (*PostgresDB, error) {
+// NewPostgresDB opens a pool for PostgresURL, over TLS when PostgresTLS is
+// enabled. With a credentials provider, each new connection authenticates
+// with the provider's current credentials and pooled connections are
+// recycled when they rotate.
+func NewPostgresDB(cfg *config.Config, credentials interfaces.CredentialsProvider, logger interfaces.Logger) (*PostgresDB, error) {
 	if cfg.PostgresURL == "" {
 		return nil, fmt.Errorf("POSTGRES_URL is required")
 	}
 
-	db, err := sql.Open("postgres", cfg.PostgresURL)
+	p := &PostgresDB{
+		config: cfg,
+		logger: logger,
+	}
+
+	tlsConfig, err := cfg.PostgresTLS.Build("")
 	if err != nil {
-		return nil, fmt.Errorf("failed to open database: %w", err)
+		return nil, fmt.Errorf("invalid PostgreSQL TLS configuration: %w", err)
+	}
+
+	switch {
+	case credentials != nil:
+		connector, err := newCredentialsConnector(cfg.PostgresURL, credentials, tlsConfig)
+		if err != nil {
+			return nil, err
+		}
+		p.connector = connector
+		p.DB = sql.OpenDB(connector)
+		p.unsubscribe = credentials.Subscribe(p.rotateCredentials)
+	case tlsConfig != nil:
+		connector, err := newPQConnector(cfg.PostgresURL, tlsConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open database: %w", err)
+		}
+		p.DB = sql.OpenDB(connector)
+	default:
+		db, err := sql.Open("postgres", cfg.PostgresURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open database: %w", err)
+		}
+		p.DB = db
 	}
 
 	// Configure connection pool
-	db.SetMaxOpenConns(cfg.MaxConnections)
-	db.SetMaxIdleConns(cfg.MaxIdleConnections)
-	db.SetConnMaxLifetime(cfg.ConnectionMaxLifetime)
-	db.SetConnMaxIdleTime(cfg.ConnectionMaxIdleTime)
+	p.DB.SetMaxOpenConns(cfg.MaxConnections)
+	p.DB.SetMaxIdleConns(cfg.MaxIdleConnections)
+	p.DB.SetConnMaxLifetime(cfg.ConnectionMaxLifetime)
+	p.DB.SetConnMaxIdleTime(cfg.ConnectionMaxIdleTime)
 
+	return p, nil
+}
+
+// rotateCredentials closes idle connections now and busy ones when they are
+// released, so queries in flight finish on their existing connections
+func (p *PostgresDB) rotateCredentials() {
+	p.connector.rotate()
+
+	// Shrinking the idle pool closes the connections in it
+	p.DB.SetMaxIdleConns(0)
+	p.DB.SetMaxIdleConns(p.config.MaxIdleConnections)
+
+	p.logger.Info("PostgreSQL credentials rotated, recycling pooled connections")
+}
+
+// NewPostgresDBFromDB wraps a pool opened and configured by the caller
+func NewPostgresDBFromDB(db *sql.DB, cfg *config.Config, logger interfaces.Logger) *PostgresDB {
 	return &PostgresDB{
-		DB:     db,
-		config: cfg,
-		logger: logger,
-	}, nil
+		DB:       db,
+		config:   cfg,
+		logger:   logger,
+		external: true,
+	}
 }
 
 func (p *PostgresDB) Connect(ctx context.Context) error {
@@ -53,7 +110,10 @@ func (p *PostgresDB) Connect(ctx context.Context) error {
 }
 
 func (p *PostgresDB) Disconnect(ctx context.Context) error {
-	if p.DB != nil {
+	if p.unsubscribe != nil {
+		p.unsubscribe()
+	}
+	if p.DB != nil && !p.external {
 		if err := p.DB.Close(); err != nil {
 			return fmt.Errorf("failed to close database: %w", err)
 		}
@@ -0,0 +1,248 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// migrationsTable records the migrations applied to a provisioned schema
+const migrationsTable = "schema_migrations"
+
+// migration is one versioned change to a custodian schema. Statements refer
+// to the quoted schema as %[1]s. Statements creating objects use IF NOT
+// EXISTS, so schemas created before migrations were recorded (by the
+// orchestrator or earlier releases) are adopted rather than failing.
+type migration struct {
+	version     int
+	description string
+	statements  []string
+}
+
+var migrations = []migration{
+	{
+		version:     1,
+		description: "create positions, settlements and balances",
+		statements: []string{
+			`CREATE TABLE IF NOT EXISTS %[1]s.positions (
+				position_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+				account_id VARCHAR(100) NOT NULL,
+				symbol VARCHAR(50) NOT NULL,
+				quantity DECIMAL(24, 8) NOT NULL,
+				available_quantity DECIMAL(24, 8) NOT NULL,
+				locked_quantity DECIMAL(24, 8) NOT NULL DEFAULT 0,
+				average_cost DECIMAL(24, 8),
+				market_value DECIMAL(24, 8),
+				currency VARCHAR(10) NOT NULL,
+				last_updated TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+				created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+				metadata JSONB,
+				CONSTRAINT positive_quantity CHECK (quantity >= 0),
+				CONSTRAINT available_less_equal_quantity CHECK (available_quantity <= quantity),
+				CONSTRAINT unique_account_symbol UNIQUE (account_id, symbol)
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_positions_account ON %[1]s.positions(account_id)`,
+			`CREATE INDEX IF NOT EXISTS idx_positions_symbol ON %[1]s.positions(symbol)`,
+			`CREATE INDEX IF NOT EXISTS idx_positions_updated ON %[1]s.positions(last_updated)`,
+
+			`CREATE TABLE IF NOT EXISTS %[1]s.settlements (
+				settlement_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+				external_id VARCHAR(100),
+				settlement_type VARCHAR(20) NOT NULL CHECK (settlement_type IN ('DEPOSIT', 'WITHDRAWAL', 'TRANSFER')),
+				account_id VARCHAR(100) NOT NULL,
+				symbol VARCHAR(50) NOT NULL,
+				quantity DECIMAL(24, 8) NOT NULL,
+				status VARCHAR(20) NOT NULL CHECK (status IN ('PENDING', 'IN_PROGRESS', 'COMPLETED', 'FAILED', 'CANCELLED')),
+				source_account VARCHAR(100),
+				destination_account VARCHAR(100),
+				initiated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+				completed_at TIMESTAMP WITH TIME ZONE,
+				expected_settlement_date TIMESTAMP WITH TIME ZONE,
+				metadata JSONB
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_settlements_account ON %[1]s.settlements(account_id)`,
+			`CREATE INDEX IF NOT EXISTS idx_settlements_status ON %[1]s.settlements(status)`,
+			`CREATE INDEX IF NOT EXISTS idx_settlements_type ON %[1]s.settlements(settlement_type)`,
+			`CREATE INDEX IF NOT EXISTS idx_settlements_initiated ON %[1]s.settlements(initiated_at)`,
+
+			`CREATE TABLE IF NOT EXISTS %[1]s.balances (
+				balance_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+				account_id VARCHAR(100) NOT NULL,
+				currency VARCHAR(10) NOT NULL,
+				available_balance DECIMAL(24, 8) NOT NULL DEFAULT 0,
+				locked_balance DECIMAL(24, 8) NOT NULL DEFAULT 0,
+				total_balance DECIMAL(24, 8) NOT NULL DEFAULT 0,
+				last_updated TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+				created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+				metadata JSONB,
+				CONSTRAINT positive_balances CHECK (available_balance >= 0 AND locked_balance >= 0),
+				CONSTRAINT total_equals_sum CHECK (total_balance = available_balance + locked_balance),
+				CONSTRAINT unique_account_currency UNIQUE (account_id, currency)
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_balances_account ON %[1]s.balances(account_id)`,
+			`CREATE INDEX IF NOT EXISTS idx_balances_currency ON %[1]s.balances(currency)`,
+			`CREATE INDEX IF NOT EXISTS idx_balances_updated ON %[1]s.balances(last_updated)`,
+		},
+	},
+	{
+		version:     2,
+		description: "create instance owner registry",
+		statements:  []string{ownerTableDDL},
+	},
+	{
+		version:     3,
+		description: "create accounts",
+		statements: []string{
+			`CREATE TABLE IF NOT EXISTS %[1]s.accounts (
+				account_id VARCHAR(100) PRIMARY KEY,
+				account_type VARCHAR(20) NOT NULL CHECK (account_type IN ('CLIENT', 'OMNIBUS', 'HOUSE', 'FEE')),
+				parent_account_id VARCHAR(100) REFERENCES %[1]s.accounts(account_id),
+				status VARCHAR(20) NOT NULL DEFAULT 'ACTIVE' CHECK (status IN ('ACTIVE', 'FROZEN', 'CLOSED')),
+				base_currency VARCHAR(10) NOT NULL,
+				created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+				updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+				closed_at TIMESTAMP WITH TIME ZONE,
+				metadata JSONB,
+				CONSTRAINT not_own_parent CHECK (parent_account_id <> account_id)
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_accounts_parent ON %[1]s.accounts(parent_account_id)`,
+			`CREATE INDEX IF NOT EXISTS idx_accounts_status ON %[1]s.accounts(status)`,
+		},
+	},
+	{
+		version:     4,
+		description: "create assets",
+		statements: []string{
+			`CREATE TABLE IF NOT EXISTS %[1]s.assets (
+				symbol VARCHAR(50) PRIMARY KEY CHECK (symbol = UPPER(symbol)),
+				asset_class VARCHAR(20) NOT NULL CHECK (asset_class IN ('CRYPTO', 'STABLECOIN', 'FIAT', 'SECURITY')),
+				decimals SMALLINT NOT NULL CHECK (decimals BETWEEN 0 AND 8),
+				network VARCHAR(50),
+				settlement_days SMALLINT NOT NULL DEFAULT 0 CHECK (settlement_days >= 0),
+				enabled BOOLEAN NOT NULL DEFAULT TRUE,
+				created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+				updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+				metadata JSONB
+			)`,
+			`CREATE TABLE IF NOT EXISTS %[1]s.asset_aliases (
+				alias VARCHAR(50) PRIMARY KEY CHECK (alias = UPPER(alias)),
+				symbol VARCHAR(50) NOT NULL REFERENCES %[1]s.assets(symbol) ON DELETE CASCADE
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_asset_aliases_symbol ON %[1]s.asset_aliases(symbol)`,
+		},
+	},
+}
+
+// ProvisionSchema creates schema if needed, applies pending migrations,
+// adopting tables that already exist, and, if role is set, grants it read
+// and write access to the tables. It runs in one transaction, so a failure
+// leaves no partial schema behind and concurrent calls for the same schema
+// are serialized. It returns the number of migrations applied.
+func ProvisionSchema(ctx context.Context, db *sql.DB, schema, role string) (int, error) {
+	quoted := pq.QuoteIdentifier(schema)
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin provisioning: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, schema); err != nil {
+		return 0, fmt.Errorf("failed to lock schema %s: %w", schema, err)
+	}
+
+	setup := []string{
+		fmt.Sprintf(`CREATE SCHEMA IF NOT EXISTS %s`, quoted),
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s.%s (
+			version INTEGER PRIMARY KEY,
+			description TEXT NOT NULL,
+			applied_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+		)`, quoted, migrationsTable),
+	}
+	for _, statement := range setup {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			return 0, fmt.Errorf("failed to create schema %s: %w", schema, err)
+		}
+	}
+
+	var current int
+	query := fmt.Sprintf(`SELECT COALESCE(MAX(version), 0) FROM %s.%s`, quoted, migrationsTable)
+	if err := tx.QueryRowContext(ctx, query).Scan(&current); err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	applied := 0
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+		for _, statement := range m.statements {
+			if _, err := tx.ExecContext(ctx, fmt.Sprintf(statement, quoted)); err != nil {
+				return 0, fmt.Errorf("migration %d (%s) failed: %w", m.version, m.description, err)
+			}
+		}
+		record := fmt.Sprintf(`INSERT INTO %s.%s (version, description) VALUES ($1, $2)`, quoted, migrationsTable)
+		if _, err := tx.ExecContext(ctx, record, m.version, m.description); err != nil {
+			return 0, fmt.Errorf("failed to record migration %d: %w", m.version, err)
+		}
+		applied++
+	}
+
+	if role != "" {
+		quotedRole := pq.QuoteIdentifier(role)
+		grants := []string{
+			fmt.Sprintf(`GRANT USAGE ON SCHEMA %s TO %s`, quoted, quotedRole),
+			fmt.Sprintf(`GRANT SELECT, INSERT, UPDATE, DELETE ON ALL TABLES IN SCHEMA %s TO %s`, quoted, quotedRole),
+			fmt.Sprintf(`ALTER DEFAULT PRIVILEGES IN SCHEMA %s GRANT SELECT, INSERT, UPDATE, DELETE ON TABLES TO %s`, quoted, quotedRole),
+		}
+		for _, statement := range grants {
+			if _, err := tx.ExecContext(ctx, statement); err != nil {
+				return 0, fmt.Errorf("failed to grant %s access to schema %s: %w", role, schema, err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit provisioning: %w", err)
+	}
+	return applied, nil
+}
+
+// SchemaStatus reports whether schema exists and whether it was created by
+// ProvisionSchema
+func SchemaStatus(ctx context.Context, db *sql.DB, schema string) (exists, provisioned bool, err error) {
+	query := `
+		SELECT EXISTS (SELECT 1 FROM information_schema.schemata WHERE schema_name = $1),
+		       EXISTS (SELECT 1 FROM information_schema.tables WHERE table_schema = $1 AND table_name = $2)
+	`
+	if err := db.QueryRowContext(ctx, query, schema, migrationsTable).Scan(&exists, &provisioned); err != nil {
+		return false, false, fmt.Errorf("failed to check schema %s: %w", schema, err)
+	}
+	return exists, provisioned, nil
+}
+
+// MissingTables returns the tables that do not exist in schema
+func MissingTables(ctx context.Context, db *sql.DB, schema string, tables ...string) ([]string, error) {
+	var missing []string
+	for _, table := range tables {
+		var exists bool
+		name := pq.QuoteIdentifier(schema) + "." + pq.QuoteIdentifier(table)
+		if err := db.QueryRowContext(ctx, `SELECT to_regclass($1) IS NOT NULL`, name).Scan(&exists); err != nil {
+			return nil, fmt.Errorf("failed to check table %s: %w", name, err)
+		}
+		if !exists {
+			missing = append(missing, table)
+		}
+	}
+	return missing, nil
+}
+
+// DropSchema drops schema and everything in it
+func DropSchema(ctx context.Context, db *sql.DB, schema string) error {
+	if _, err := db.ExecContext(ctx, fmt.Sprintf(`DROP SCHEMA IF EXISTS %s CASCADE`, pq.QuoteIdentifier(schema))); err != nil {
+		return fmt.Errorf("failed to drop schema %s: %w", schema, err)
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+package database
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestMigrationsIdempotent tests that migrations adopt tables and indexes
+// created before they were recorded, e.g. by an earlier ProvisionSchema
+// that failed to record them or by the orchestrator
+func TestMigrationsIdempotent(t *testing.T) {
+	for _, m := range migrations {
+		for _, statement := range m.statements {
+			if strings.HasPrefix(statement, "CREATE") && !strings.Contains(strings.SplitN(statement, "%", 2)[0], "IF NOT EXISTS") {
+				t.Errorf("migration %d statement is not idempotent: %s", m.version, strings.SplitN(statement, "\n", 2)[0])
+			}
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package database
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// sslRequestCode asks the server to switch to TLS before the startup message
+const sslRequestCode = 80877103
+
+// newPQConnector returns a pq connector for dsn. With tlsConfig, TLS is
+// negotiated by tlsDialer instead of pq, whose sslmode settings cannot set
+// the server name or minimum version.
+func newPQConnector(dsn string, tlsConfig *tls.Config) (*pq.Connector, error) {
+	if tlsConfig != nil {
+		parsed, err := url.Parse(dsn)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse PostgreSQL URL: %w", err)
+		}
+		query := parsed.Query()
+		query.Set("sslmode", "disable")
+		parsed.RawQuery = query.Encode()
+		dsn = parsed.String()
+	}
+
+	connector, err := pq.NewConnector(dsn)
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		connector.Dialer(&tlsDialer{config: tlsConfig})
+	}
+	return connector, nil
+}
+
+// tlsDialer opens PostgreSQL connections and upgrades them to TLS the way
+// libpq does, so pq sees an already encrypted connection
+type tlsDialer struct {
+	config *tls.Config
+	dialer net.Dialer
+}
+
+func (d *tlsDialer) Dial(network, address string) (net.Conn, error) {
+	return d.DialContext(context.Background(), network, address)
+}
+
+func (d *tlsDialer) DialTimeout(network, address string, timeout time.Duration) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return d.DialContext(ctx, network, address)
+}
+
+func (d *tlsDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	conn, err := d.dialer.DialContext(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unix sockets are local and not encrypted
+	if network == "unix" {
+		return conn, nil
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	if err := requestSSL(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	config := d.config
+	if config.ServerName == "" {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			host = address
+		}
+		config = config.Clone()
+		config.ServerName = host
+	}
+
+	tlsConn := tls.Client(conn, config)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("PostgreSQL TLS handshake failed: %w", err)
+	}
+	conn.SetDeadline(time.Time{})
+	return tlsConn, nil
+}
+
+// requestSSL sends SSLRequest and checks that the server accepts it
+func requestSSL(conn net.Conn) error {
+	request := make([]byte, 8)
+	binary.BigEndian.PutUint32(request[0:4], 8)
+	binary.BigEndian.PutUint32(request[4:8], sslRequestCode)
+	if _, err := conn.Write(request); err != nil {
+		return fmt.Errorf("failed to request PostgreSQL TLS: %w", err)
+	}
+
+	response := make([]byte, 1)
+	if _, err := conn.Read(response); err != nil {
+		return fmt.Errorf("failed to request PostgreSQL TLS: %w", err)
+	}
+	if response[0] != 'S' {
+		return fmt.Errorf("PostgreSQL server does not accept TLS connections")
+	}
+	return nil
+}
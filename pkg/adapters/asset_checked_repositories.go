@@ -0,0 +1,259 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/interfaces"
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/models"
+)
+
+// resolveAsset returns the asset that symbol names or aliases, failing with
+// ErrInvalidAsset if it is unknown or disabled. The asset is read from the
+// primary, so one defined just before is found.
+func resolveAsset(ctx context.Context, assets interfaces.AssetRepository, symbol string) (*models.Asset, error) {
+	asset, err := assets.GetBySymbol(WithPrimaryReads(ctx), symbol)
+	if errors.Is(err, interfaces.ErrNotFound) {
+		return nil, fmt.Errorf("%w: unknown asset %q", interfaces.ErrInvalidAsset, symbol)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve asset %q: %w", symbol, err)
+	}
+	if !asset.Enabled {
+		return nil, fmt.Errorf("%w: asset %s is disabled", interfaces.ErrInvalidAsset, asset.Symbol)
+	}
+	return asset, nil
+}
+
+// canonicalSymbol maps symbol to its asset for lookups, leaving it unchanged
+// if it cannot be resolved
+func canonicalSymbol(ctx context.Context, assets interfaces.AssetRepository, symbol string) string {
+	if asset, err := assets.GetBySymbol(ctx, symbol); err == nil {
+		return asset.Symbol
+	}
+	return symbol
+}
+
+// canonicalFilter maps a query's symbol filter to its asset; nil matches any
+func canonicalFilter(ctx context.Context, assets interfaces.AssetRepository, filter *string) *string {
+	if filter == nil {
+		return nil
+	}
+	symbol := canonicalSymbol(ctx, assets, *filter)
+	return &symbol
+}
+
+type quantityField struct {
+	name  string
+	value float64
+}
+
+// checkPrecision fails with ErrInvalidAsset if a quantity has more decimal
+// places than the asset allows. The tolerance absorbs float64 rounding, which
+// grows with the magnitude of the scaled quantity.
+func checkPrecision(asset *models.Asset, quantities ...quantityField) error {
+	for _, q := range quantities {
+		scaled := q.value * math.Pow10(asset.Decimals)
+		tolerance := math.Max(1e-6, math.Abs(scaled)*1e-15)
+		if math.Abs(scaled-math.Round(scaled)) > tolerance {
+			return fmt.Errorf("%w: %s %v has more than the %d decimals of %s",
+				interfaces.ErrInvalidAsset, q.name, q.value, asset.Decimals, asset.Symbol)
+		}
+	}
+	return nil
+}
+
+// AssetCheckedPositionRepository stores positions under their assets'
+// canonical symbols and rejects quantities finer than the asset's decimals
+type AssetCheckedPositionRepository struct {
+	interfaces.PositionRepository
+
+	assets interfaces.AssetRepository
+}
+
+func NewAssetCheckedPositionRepository(inner interfaces.PositionRepository, assets interfaces.AssetRepository) interfaces.PositionRepository {
+	return &AssetCheckedPositionRepository{
+		PositionRepository: inner,
+		assets:             assets,
+	}
+}
+
+func (r *AssetCheckedPositionRepository) Create(ctx context.Context, position *models.Position) error {
+	if err := r.normalize(ctx, position); err != nil {
+		return err
+	}
+	return r.PositionRepository.Create(ctx, position)
+}
+
+func (r *AssetCheckedPositionRepository) Update(ctx context.Context, position *models.Position) error {
+	if err := r.normalize(ctx, position); err != nil {
+		return err
+	}
+	return r.PositionRepository.Update(ctx, position)
+}
+
+func (r *AssetCheckedPositionRepository) UpdateAvailableQuantity(ctx context.Context, positionID string, availableQty, lockedQty float64) error {
+	position, err := r.PositionRepository.GetByID(WithPrimaryReads(ctx), positionID)
+	if err != nil {
+		return err
+	}
+	asset, err := resolveAsset(ctx, r.assets, position.Symbol)
+	if err != nil {
+		return err
+	}
+	if err := checkPrecision(asset, quantityField{"available quantity", availableQty}, quantityField{"locked quantity", lockedQty}); err != nil {
+		return err
+	}
+	return r.PositionRepository.UpdateAvailableQuantity(ctx, positionID, availableQty, lockedQty)
+}
+
+func (r *AssetCheckedPositionRepository) GetByAccountAndSymbol(ctx context.Context, accountID, symbol string) (*models.Position, error) {
+	return r.PositionRepository.GetByAccountAndSymbol(ctx, accountID, canonicalSymbol(ctx, r.assets, symbol))
+}
+
+// Query filters by canonical symbol and currency; query itself is not modified
+func (r *AssetCheckedPositionRepository) Query(ctx context.Context, query *models.PositionQuery) ([]*models.Position, error) {
+	if query != nil {
+		normalized := *query
+		normalized.Symbol = canonicalFilter(ctx, r.assets, query.Symbol)
+		normalized.Currency = canonicalFilter(ctx, r.assets, query.Currency)
+		query = &normalized
+	}
+	return r.PositionRepository.Query(ctx, query)
+}
+
+func (r *AssetCheckedPositionRepository) normalize(ctx context.Context, position *models.Position) error {
+	asset, err := resolveAsset(ctx, r.assets, position.Symbol)
+	if err != nil {
+		return err
+	}
+	err = checkPrecision(asset,
+		quantityField{"quantity", position.Quantity},
+		quantityField{"available quantity", position.AvailableQuantity},
+		quantityField{"locked quantity", position.LockedQuantity},
+	)
+	if err != nil {
+		return err
+	}
+	currency, err := resolveAsset(ctx, r.assets, position.Currency)
+	if err != nil {
+		return err
+	}
+
+	position.Symbol = asset.Symbol
+	position.Currency = currency.Symbol
+	return nil
+}
+
+// AssetCheckedBalanceRepository stores balances under their currencies'
+// canonical symbols and rejects amounts finer than the asset's decimals
+type AssetCheckedBalanceRepository struct {
+	interfaces.BalanceRepository
+
+	assets interfaces.AssetRepository
+}
+
+func NewAssetCheckedBalanceRepository(inner interfaces.BalanceRepository, assets interfaces.AssetRepository) interfaces.BalanceRepository {
+	return &AssetCheckedBalanceRepository{
+		BalanceRepository: inner,
+		assets:            assets,
+	}
+}
+
+func (r *AssetCheckedBalanceRepository) Upsert(ctx context.Context, balance *models.Balance) error {
+	asset, err := resolveAsset(ctx, r.assets, balance.Currency)
+	if err != nil {
+		return err
+	}
+	err = checkPrecision(asset,
+		quantityField{"available balance", balance.AvailableBalance},
+		quantityField{"locked balance", balance.LockedBalance},
+		quantityField{"total balance", balance.TotalBalance},
+	)
+	if err != nil {
+		return err
+	}
+
+	balance.Currency = asset.Symbol
+	return r.BalanceRepository.Upsert(ctx, balance)
+}
+
+func (r *AssetCheckedBalanceRepository) UpdateAvailableBalance(ctx context.Context, balanceID string, availableBalance, lockedBalance float64) error {
+	balance, err := r.BalanceRepository.GetByID(WithPrimaryReads(ctx), balanceID)
+	if err != nil {
+		return err
+	}
+	asset, err := resolveAsset(ctx, r.assets, balance.Currency)
+	if err != nil {
+		return err
+	}
+	if err := checkPrecision(asset, quantityField{"available balance", availableBalance}, quantityField{"locked balance", lockedBalance}); err != nil {
+		return err
+	}
+	return r.BalanceRepository.UpdateAvailableBalance(ctx, balanceID, availableBalance, lockedBalance)
+}
+
+func (r *AssetCheckedBalanceRepository) AtomicUpdate(ctx context.Context, accountID, currency string, availableDelta, lockedDelta float64) error {
+	asset, err := resolveAsset(ctx, r.assets, currency)
+	if err != nil {
+		return err
+	}
+	if err := checkPrecision(asset, quantityField{"available delta", availableDelta}, quantityField{"locked delta", lockedDelta}); err != nil {
+		return err
+	}
+	return r.BalanceRepository.AtomicUpdate(ctx, accountID, asset.Symbol, availableDelta, lockedDelta)
+}
+
+func (r *AssetCheckedBalanceRepository) GetByAccountAndCurrency(ctx context.Context, accountID, currency string) (*models.Balance, error) {
+	return r.BalanceRepository.GetByAccountAndCurrency(ctx, accountID, canonicalSymbol(ctx, r.assets, currency))
+}
+
+// Query filters by canonical currency; query itself is not modified
+func (r *AssetCheckedBalanceRepository) Query(ctx context.Context, query *models.BalanceQuery) ([]*models.Balance, error) {
+	if query != nil {
+		normalized := *query
+		normalized.Currency = canonicalFilter(ctx, r.assets, query.Currency)
+		query = &normalized
+	}
+	return r.BalanceRepository.Query(ctx, query)
+}
+
+// AssetCheckedSettlementRepository stores settlements under their assets'
+// canonical symbols and rejects quantities finer than the asset's decimals
+type AssetCheckedSettlementRepository struct {
+	interfaces.SettlementRepository
+
+	assets interfaces.AssetRepository
+}
+
+func NewAssetCheckedSettlementRepository(inner interfaces.SettlementRepository, assets interfaces.AssetRepository) interfaces.SettlementRepository {
+	return &AssetCheckedSettlementRepository{
+		SettlementRepository: inner,
+		assets:               assets,
+	}
+}
+
+func (r *AssetCheckedSettlementRepository) Create(ctx context.Context, settlement *models.Settlement) error {
+	asset, err := resolveAsset(ctx, r.assets, settlement.Symbol)
+	if err != nil {
+		return err
+	}
+	if err := checkPrecision(asset, quantityField{"quantity", settlement.Quantity}); err != nil {
+		return err
+	}
+
+	settlement.Symbol = asset.Symbol
+	return r.SettlementRepository.Create(ctx, settlement)
+}
+
+// Query filters by canonical symbol; query itself is not modified
+func (r *AssetCheckedSettlementRepository) Query(ctx context.Context, query *models.SettlementQuery) ([]*models.Settlement, error) {
+	if query != nil {
+		normalized := *query
+		normalized.Symbol = canonicalFilter(ctx, r.assets, query.Symbol)
+		query = &normalized
+	}
+	return r.SettlementRepository.Query(ctx, query)
+}
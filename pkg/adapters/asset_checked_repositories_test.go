@@ -0,0 +1,231 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/interfaces"
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/models"
+)
+
+type stubAssetRepository struct {
+	interfaces.AssetRepository
+	assets []*models.Asset
+}
+
+func (r *stubAssetRepository) GetBySymbol(ctx context.Context, symbol string) (*models.Asset, error) {
+	symbol = normalizeSymbol(symbol)
+	for _, asset := range r.assets {
+		if asset.Symbol == symbol {
+			return asset, nil
+		}
+		for _, alias := range asset.Aliases {
+			if alias == symbol {
+				return asset, nil
+			}
+		}
+	}
+	return nil, interfaces.ErrNotFound
+}
+
+type recordingPositionRepository struct {
+	interfaces.PositionRepository
+	writes int
+	query  *models.PositionQuery
+}
+
+func (r *recordingPositionRepository) Query(ctx context.Context, query *models.PositionQuery) ([]*models.Position, error) {
+	r.query = query
+	return nil, nil
+}
+
+func (r *recordingPositionRepository) Create(ctx context.Context, position *models.Position) error {
+	r.writes++
+	return nil
+}
+
+type recordingSettlementRepository struct {
+	interfaces.SettlementRepository
+	writes int
+	query  *models.SettlementQuery
+}
+
+func (r *recordingSettlementRepository) Query(ctx context.Context, query *models.SettlementQuery) ([]*models.Settlement, error) {
+	r.query = query
+	return nil, nil
+}
+
+func (r *recordingSettlementRepository) Create(ctx context.Context, settlement *models.Settlement) error {
+	r.writes++
+	return nil
+}
+
+type recordingBalanceRepository struct {
+	interfaces.BalanceRepository
+	currencies []string
+	query      *models.BalanceQuery
+}
+
+func (r *recordingBalanceRepository) Query(ctx context.Context, query *models.BalanceQuery) ([]*models.Balance, error) {
+	r.query = query
+	return nil, nil
+}
+
+func (r *recordingBalanceRepository) AtomicUpdate(ctx context.Context, accountID, currency string, availableDelta, lockedDelta float64) error {
+	r.currencies = append(r.currencies, currency)
+	return nil
+}
+
+// TestCheckPrecision tests quantities against an asset's decimals
+func TestCheckPrecision(t *testing.T) {
+	btc := &models.Asset{Symbol: "BTC", Decimals: 8}
+	usd := &models.Asset{Symbol: "USD", Decimals: 2}
+
+	tests := []struct {
+		name     string
+		asset    *models.Asset
+		quantity float64
+		valid    bool
+	}{
+		{name: "satoshi", asset: btc, quantity: 0.00000001, valid: true},
+		{name: "below satoshi", asset: btc, quantity: 0.000000015, valid: false},
+		{name: "float rounding", asset: btc, quantity: 0.1 + 0.2, valid: true},
+		{name: "large amount", asset: btc, quantity: 20999999.97690000, valid: true},
+		{name: "negative delta", asset: usd, quantity: -12.34, valid: true},
+		{name: "cents exceeded", asset: usd, quantity: 10.005, valid: false},
+		{name: "whole units", asset: &models.Asset{Symbol: "AAPL"}, quantity: 1.5, valid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkPrecision(tt.asset, quantityField{"quantity", tt.quantity})
+			if (err == nil) != tt.valid {
+				t.Errorf("checkPrecision(%v) = %v, expected valid: %v", tt.quantity, err, tt.valid)
+			}
+			if err != nil && !errors.Is(err, interfaces.ErrInvalidAsset) {
+				t.Errorf("error %v does not wrap ErrInvalidAsset", err)
+			}
+		})
+	}
+}
+
+// TestAssetCheckedRepositories tests that writes are stored under canonical
+// symbols and that invalid assets never reach the wrapped repositories
+func TestAssetCheckedRepositories(t *testing.T) {
+	assets := &stubAssetRepository{assets: []*models.Asset{
+		{Symbol: "BTC", Aliases: []string{"XBT"}, Decimals: 8, Enabled: true},
+		{Symbol: "USD", Decimals: 2, Enabled: true},
+		{Symbol: "LUNA", Decimals: 6, Enabled: false},
+	}}
+
+	tests := []struct {
+		name     string
+		symbol   string
+		quantity float64
+		expected string
+		errorMsg string
+	}{
+		{name: "canonical symbol", symbol: "BTC", quantity: 1.5, expected: "BTC"},
+		{name: "alias in lower case", symbol: " xbt", quantity: 1.5, expected: "BTC"},
+		{name: "unknown asset", symbol: "DOGE", quantity: 1, errorMsg: "unknown asset"},
+		{name: "disabled asset", symbol: "luna", quantity: 1, errorMsg: "asset LUNA is disabled"},
+		{name: "excess precision", symbol: "btc", quantity: 0.123456789, errorMsg: "more than the 8 decimals of BTC"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settlements := &recordingSettlementRepository{}
+			settlement := &models.Settlement{Symbol: tt.symbol, Quantity: tt.quantity}
+			err := NewAssetCheckedSettlementRepository(settlements, assets).Create(context.Background(), settlement)
+
+			positions := &recordingPositionRepository{}
+			position := &models.Position{Symbol: tt.symbol, Quantity: tt.quantity, AvailableQuantity: tt.quantity, Currency: "usd"}
+			positionErr := NewAssetCheckedPositionRepository(positions, assets).Create(context.Background(), position)
+
+			balances := &recordingBalanceRepository{}
+			balanceErr := NewAssetCheckedBalanceRepository(balances, assets).AtomicUpdate(context.Background(), "ACC-1", tt.symbol, tt.quantity, 0)
+
+			for _, err := range []error{err, positionErr, balanceErr} {
+				if tt.errorMsg != "" {
+					if !errors.Is(err, interfaces.ErrInvalidAsset) || !strings.Contains(err.Error(), tt.errorMsg) {
+						t.Errorf("error = %v, expected %q", err, tt.errorMsg)
+					}
+				} else if err != nil {
+					t.Errorf("write failed: %v", err)
+				}
+			}
+
+			if tt.errorMsg != "" {
+				if settlements.writes != 0 || positions.writes != 0 || len(balances.currencies) != 0 {
+					t.Errorf("invalid write reached the wrapped repositories")
+				}
+				return
+			}
+			if settlement.Symbol != tt.expected || position.Symbol != tt.expected || balances.currencies[0] != tt.expected {
+				t.Errorf("symbols = %s, %s, %s, expected %s", settlement.Symbol, position.Symbol, balances.currencies[0], tt.expected)
+			}
+			if position.Currency != "USD" {
+				t.Errorf("position currency = %s, expected USD", position.Currency)
+			}
+		})
+	}
+}
+
+// TestAssetCheckedQueries tests that query filters match the canonical
+// symbols writes are stored under, without modifying the caller's query
+func TestAssetCheckedQueries(t *testing.T) {
+	assets := &stubAssetRepository{assets: []*models.Asset{
+		{Symbol: "BTC", Aliases: []string{"XBT"}, Decimals: 8, Enabled: true},
+		{Symbol: "USD", Decimals: 2, Enabled: true},
+	}}
+
+	tests := []struct {
+		name     string
+		filter   string // Empty for no filter
+		expected string
+	}{
+		{name: "alias", filter: "xbt", expected: "BTC"},
+		{name: "canonical in lower case", filter: "usd", expected: "USD"},
+		{name: "unknown asset", filter: "DOGE", expected: "DOGE"},
+		{name: "no filter"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var filter *string
+			if tt.filter != "" {
+				filter = &tt.filter
+			}
+
+			ctx := context.Background()
+			positions := &recordingPositionRepository{}
+			positionQuery := &models.PositionQuery{Symbol: filter, Currency: filter}
+			if _, err := NewAssetCheckedPositionRepository(positions, assets).Query(ctx, positionQuery); err != nil {
+				t.Fatalf("position Query failed: %v", err)
+			}
+			balances := &recordingBalanceRepository{}
+			balanceQuery := &models.BalanceQuery{Currency: filter}
+			if _, err := NewAssetCheckedBalanceRepository(balances, assets).Query(ctx, balanceQuery); err != nil {
+				t.Fatalf("balance Query failed: %v", err)
+			}
+			settlements := &recordingSettlementRepository{}
+			settlementQuery := &models.SettlementQuery{Symbol: filter}
+			if _, err := NewAssetCheckedSettlementRepository(settlements, assets).Query(ctx, settlementQuery); err != nil {
+				t.Fatalf("settlement Query failed: %v", err)
+			}
+
+			for _, got := range []*string{positions.query.Symbol, positions.query.Currency, balances.query.Currency, settlements.query.Symbol} {
+				if got == nil && filter != nil {
+					t.Errorf("filter %q dropped", tt.filter)
+				} else if got != nil && (filter == nil || *got != tt.expected) {
+					t.Errorf("filter %q passed on as %q, expected %q", tt.filter, *got, tt.expected)
+				}
+			}
+			if positionQuery.Symbol != filter || balanceQuery.Currency != filter || settlementQuery.Symbol != filter {
+				t.Error("caller's query was modified")
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/internal/config"
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/interfaces"
+)
+
+// BackendPostgres is the built-in backend: PostgreSQL repositories with
+// Redis for cache, service discovery and locks
+const BackendPostgres = "postgres"
+
+// Repositories is the set of repositories an adapter exposes; nil members
+// are unavailable
+type Repositories struct {
+	Position         interfaces.PositionRepository
+	Settlement       interfaces.SettlementRepository
+	Balance          interfaces.BalanceRepository
+	Account          interfaces.AccountRepository
+	Asset            interfaces.AssetRepository
+	ServiceDiscovery interfaces.ServiceDiscoveryRepository
+	Cache            interfaces.CacheRepository
+	Lock             interfaces.LockRepository
+}
+
+// Backend is a third-party storage implementation selected by the Backend
+// config field
+type Backend interface {
+	Repositories() Repositories
+
+	// Connect is retried according to the connect policy
+	Connect(ctx context.Context) error
+	Disconnect(ctx context.Context) error
+
+	// HealthCheck is reported as the backend's health component
+	HealthCheck(ctx context.Context) error
+}
+
+// BackendConfig is the resolved adapter configuration passed to a backend
+// factory. Backend-specific settings such as connection URLs are read by the
+// backend itself.
+type BackendConfig struct {
+	ServiceName         string
+	ServiceInstanceName string
+	ServiceVersion      string
+	Environment         string
+
+	// Isolation names, derived from the instance name unless set explicitly
+	SchemaName                string
+	RedisNamespace            string
+	CacheNamespace            string
+	ServiceDiscoveryNamespace string
+
+	CacheTTL   time.Duration
+	ServiceTTL time.Duration
+}
+
+func newBackendConfig(cfg *config.Config) BackendConfig {
+	return BackendConfig{
+		ServiceName:               cfg.ServiceName,
+		ServiceInstanceName:       cfg.ServiceInstanceName,
+		ServiceVersion:            cfg.ServiceVersion,
+		Environment:               cfg.Environment,
+		SchemaName:                cfg.SchemaName,
+		RedisNamespace:            cfg.RedisNamespace,
+		CacheNamespace:            cfg.CacheNamespace,
+		ServiceDiscoveryNamespace: cfg.ServiceDiscoveryNamespace,
+		CacheTTL:                  cfg.CacheTTL,
+		ServiceTTL:                cfg.ServiceTTL,
+	}
+}
+
+// BackendFactory creates a backend from the adapter configuration
+type BackendFactory func(cfg BackendConfig, logger interfaces.Logger) (Backend, error)
+
+var (
+	backendsMu sync.RWMutex
+	backends   = map[string]BackendFactory{}
+)
+
+// RegisterBackend makes a backend selectable by name, typically from an init
+// function. Registering the built-in name or a name twice fails.
+func RegisterBackend(name string, factory BackendFactory) error {
+	if name == "" || factory == nil {
+		return fmt.Errorf("backend name and factory are required")
+	}
+	if name == BackendPostgres {
+		return fmt.Errorf("backend %q is built in", name)
+	}
+
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+
+	if _, exists := backends[name]; exists {
+		return fmt.Errorf("backend %q already registered", name)
+	}
+	backends[name] = factory
+	return nil
+}
+
+// RegisteredBackends lists the selectable backend names, including the built-in one
+func RegisteredBackends() []string {
+	backendsMu.RLock()
+	defer backendsMu.RUnlock()
+
+	return backendNamesLocked()
+}
+
+// backendNamesLocked lists the backend names; the caller holds backendsMu
+func backendNamesLocked() []string {
+	names := []string{BackendPostgres}
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func lookupBackend(name string) (BackendFactory, error) {
+	backendsMu.RLock()
+	defer backendsMu.RUnlock()
+
+	factory, ok := backends[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown backend %q (registered: %v)", name, backendNamesLocked())
+	}
+	return factory, nil
+}
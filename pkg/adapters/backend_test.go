@@ -0,0 +1,147 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/internal/config"
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/interfaces"
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/models"
+)
+
+type stubPositionRepository struct {
+	interfaces.PositionRepository
+	err error
+}
+
+func (r *stubPositionRepository) GetByID(ctx context.Context, positionID string) (*models.Position, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	return &models.Position{PositionID: positionID}, nil
+}
+
+type faultyPositionRepository struct {
+	interfaces.PositionRepository
+}
+
+func (r *faultyPositionRepository) GetByID(ctx context.Context, positionID string) (*models.Position, error) {
+	return nil, errors.New("injected fault")
+}
+
+type stubBackend struct {
+	connected bool
+}
+
+func (b *stubBackend) Repositories() Repositories {
+	return Repositories{Position: &stubPositionRepository{}}
+}
+
+func (b *stubBackend) Connect(ctx context.Context) error {
+	b.connected = true
+	return nil
+}
+
+func (b *stubBackend) Disconnect(ctx context.Context) error  { return nil }
+func (b *stubBackend) HealthCheck(ctx context.Context) error { return nil }
+
+// TestRegisteredBackend tests selecting a third-party backend from config
+func TestRegisteredBackend(t *testing.T) {
+	backend := &stubBackend{}
+	var received BackendConfig
+	factory := func(cfg BackendConfig, logger interfaces.Logger) (Backend, error) {
+		received = cfg
+		return backend, nil
+	}
+
+	if err := RegisterBackend("stub", factory); err != nil {
+		t.Fatalf("RegisterBackend failed: %v", err)
+	}
+	if err := RegisterBackend("stub", factory); err == nil {
+		t.Error("duplicate RegisterBackend succeeded")
+	}
+	if err := RegisterBackend(BackendPostgres, factory); err == nil {
+		t.Error("registering the built-in backend succeeded")
+	}
+
+	adapter, err := NewCustodianDataAdapter(&config.Config{
+		ServiceName:         "custodian-simulator",
+		ServiceInstanceName: "custodian-simulator",
+		Backend:             "stub",
+	})
+	if err != nil {
+		t.Fatalf("NewCustodianDataAdapter failed: %v", err)
+	}
+	if received.ServiceInstanceName != "custodian-simulator" || received.SchemaName != "custodian" {
+		t.Errorf("factory config = %+v", received)
+	}
+	if err := adapter.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	if !backend.connected {
+		t.Error("backend not connected")
+	}
+
+	position, err := adapter.PositionRepository().GetByID(context.Background(), "pos-1")
+	if err != nil || position.PositionID != "pos-1" {
+		t.Errorf("GetByID = %v, %v", position, err)
+	}
+	if readiness := adapter.(HealthReporter).Readiness(context.Background()); !readiness.Healthy {
+		t.Errorf("Readiness unhealthy: %v", readiness.Reasons)
+	}
+
+	if _, err := NewCustodianDataAdapter(&config.Config{Backend: "missing"}); err == nil {
+		t.Error("unknown backend accepted")
+	}
+}
+
+// TestLookupBackend tests that failed lookups list the registered backends
+func TestLookupBackend(t *testing.T) {
+	_, err := lookupBackend("missing")
+	if err == nil || !strings.Contains(err.Error(), BackendPostgres) {
+		t.Errorf("lookupBackend error = %v, want the registered backends listed", err)
+	}
+}
+
+// TestRepositoryOptions tests overriding and decorating repositories
+func TestRepositoryOptions(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []Option
+		expectError bool
+	}{
+		{
+			name: "override",
+			opts: []Option{WithPositionRepository(&stubPositionRepository{})},
+		},
+		{
+			name: "decorator wraps override",
+			opts: []Option{
+				WithPositionRepository(&stubPositionRepository{}),
+				WithPositionDecorator(func(repo interfaces.PositionRepository) interfaces.PositionRepository {
+					return &faultyPositionRepository{PositionRepository: repo}
+				}),
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter, err := NewCustodianDataAdapter(&config.Config{
+				ServiceName:         "custodian-simulator",
+				ServiceInstanceName: "custodian-simulator",
+			}, tt.opts...)
+			if err != nil {
+				t.Fatalf("NewCustodianDataAdapter failed: %v", err)
+			}
+
+			_, err = adapter.PositionRepository().GetByID(context.Background(), "pos-1")
+			if (err != nil) != tt.expectError {
+				t.Errorf("GetByID error = %v, expectError %v", err, tt.expectError)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package adapters
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/interfaces"
+)
+
+// JSONCodec encodes values as JSON, matching what CacheRepository.Set stores
+// for non-string values
+type JSONCodec struct{}
+
+func (JSONCodec) Name() string { return "json" }
+
+func (JSONCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (JSONCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// GobCodec encodes values with encoding/gob. Only Go consumers can read them.
+type GobCodec struct{}
+
+func (GobCodec) Name() string { return "gob" }
+
+func (GobCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (GobCodec) Unmarshal(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// RawCodec stores string and []byte values unchanged
+type RawCodec struct{}
+
+func (RawCodec) Name() string { return "raw" }
+
+func (RawCodec) Marshal(v interface{}) ([]byte, error) {
+	switch val := v.(type) {
+	case []byte:
+		return val, nil
+	case string:
+		return []byte(val), nil
+	case *[]byte:
+		return *val, nil
+	case *string:
+		return []byte(*val), nil
+	}
+	return nil, fmt.Errorf("raw codec supports string and []byte, got %T", v)
+}
+
+func (RawCodec) Unmarshal(data []byte, v interface{}) error {
+	switch val := v.(type) {
+	case *[]byte:
+		*val = append([]byte(nil), data...)
+		return nil
+	case *string:
+		*val = string(data)
+		return nil
+	}
+	return fmt.Errorf("raw codec supports *string and *[]byte, got %T", v)
+}
+
+// Header bytes framing typed cache values. Compressed values always start
+// with cacheValueCompressed. Uncompressed values are stored unchanged, so
+// JSON stays readable by plain Get and other consumers, unless they start
+// with a header byte themselves; those are escaped with cacheValuePlain.
+// Valid JSON never starts with either byte.
+const (
+	cacheValuePlain      byte = 0x00
+	cacheValueCompressed byte = 0x01
+)
+
+// encodeCacheValue marshals v and gzips the result when it is larger than
+// compressAbove bytes. compressAbove <= 0 disables compression.
+func encodeCacheValue(codec interfaces.CacheCodec, v interface{}, compressAbove int) ([]byte, error) {
+	data, err := codec.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode value with %s codec: %w", codec.Name(), err)
+	}
+	if compressAbove <= 0 || len(data) <= compressAbove {
+		if len(data) > 0 && (data[0] == cacheValuePlain || data[0] == cacheValueCompressed) {
+			return append([]byte{cacheValuePlain}, data...), nil
+		}
+		return data, nil
+	}
+
+	buf := bytes.NewBuffer([]byte{cacheValueCompressed})
+	zw := gzip.NewWriter(buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, fmt.Errorf("failed to compress value: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress value: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeCacheValue reverses encodeCacheValue. Compression is read from the
+// header byte, so readers need not know the writer's threshold.
+func decodeCacheValue(codec interfaces.CacheCodec, data []byte, v interface{}) error {
+	if len(data) > 0 {
+		switch data[0] {
+		case cacheValuePlain:
+			data = data[1:]
+		case cacheValueCompressed:
+			zr, err := gzip.NewReader(bytes.NewReader(data[1:]))
+			if err != nil {
+				return fmt.Errorf("failed to decompress value: %w", err)
+			}
+			defer zr.Close()
+
+			data, err = io.ReadAll(zr)
+			if err != nil {
+				return fmt.Errorf("failed to decompress value: %w", err)
+			}
+		}
+	}
+
+	if err := codec.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to decode value with %s codec: %w", codec.Name(), err)
+	}
+	return nil
+}
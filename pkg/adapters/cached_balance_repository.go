@@ -0,0 +1,133 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/interfaces"
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/models"
+)
+
+func balanceCacheTag(balanceID string) string {
+	return "balance:" + balanceID
+}
+
+// CachedBalanceRepository serves balance reads from the cache and
+// invalidates them by account and balance tags on every write. Cache
+// failures degrade to reading the wrapped repository; a write whose
+// invalidation fails returns ErrCacheNotInvalidated. Misses are filled
+// from the primary, as a lagging replica could reinstate stale balances, and
+// under a tag fence, so a fill racing a write is dropped rather than stored.
+type CachedBalanceRepository struct {
+	interfaces.BalanceRepository
+
+	cache    interfaces.CacheRepository
+	balances *TypedCache[*models.Balance]
+	lists    *TypedCache[[]*models.Balance]
+	ttl      *durationSetting // Read on each fill, so reloads apply
+	logger   interfaces.Logger
+}
+
+func NewCachedBalanceRepository(inner interfaces.BalanceRepository, cache interfaces.CacheRepository, ttl time.Duration, logger interfaces.Logger) interfaces.BalanceRepository {
+	return newCachedBalanceRepository(inner, cache, newDurationSetting(ttl), logger)
+}
+
+func newCachedBalanceRepository(inner interfaces.BalanceRepository, cache interfaces.CacheRepository, ttl *durationSetting, logger interfaces.Logger) interfaces.BalanceRepository {
+	return &CachedBalanceRepository{
+		BalanceRepository: inner,
+		cache:             cache,
+		balances:          NewTypedCache[*models.Balance](cache, JSONCodec{}, DefaultCompressionThreshold),
+		lists:             NewTypedCache[[]*models.Balance](cache, JSONCodec{}, DefaultCompressionThreshold),
+		ttl:               ttl,
+		logger:            logger,
+	}
+}
+
+func (r *CachedBalanceRepository) GetByID(ctx context.Context, balanceID string) (*models.Balance, error) {
+	key := fmt.Sprintf("balance:id:%s", balanceID)
+	if balance, err := r.balances.Get(ctx, key); err == nil {
+		return balance, nil
+	}
+
+	fence, fenced := beginCacheFill(ctx, r.cache, r.logger)
+	balance, err := r.BalanceRepository.GetByID(WithPrimaryReads(ctx), balanceID)
+	if err != nil {
+		return nil, err
+	}
+	if fenced {
+		r.store(ctx, fence, key, balance)
+	}
+	return balance, nil
+}
+
+func (r *CachedBalanceRepository) GetByAccountAndCurrency(ctx context.Context, accountID, currency string) (*models.Balance, error) {
+	key := fmt.Sprintf("balance:account:%s:currency:%s", accountID, currency)
+	if balance, err := r.balances.Get(ctx, key); err == nil {
+		return balance, nil
+	}
+
+	fence, fenced := beginCacheFill(ctx, r.cache, r.logger)
+	balance, err := r.BalanceRepository.GetByAccountAndCurrency(WithPrimaryReads(ctx), accountID, currency)
+	if err != nil {
+		return nil, err
+	}
+	if fenced {
+		r.store(ctx, fence, key, balance)
+	}
+	return balance, nil
+}
+
+func (r *CachedBalanceRepository) GetByAccount(ctx context.Context, accountID string) ([]*models.Balance, error) {
+	key := fmt.Sprintf("balance:account:%s:all", accountID)
+	if balances, err := r.lists.Get(ctx, key); err == nil {
+		return balances, nil
+	}
+
+	fence, fenced := beginCacheFill(ctx, r.cache, r.logger)
+	balances, err := r.BalanceRepository.GetByAccount(WithPrimaryReads(ctx), accountID)
+	if err != nil || !fenced {
+		return balances, err
+	}
+
+	tags := []string{accountCacheTag(accountID)}
+	for _, balance := range balances {
+		tags = append(tags, balanceCacheTag(balance.BalanceID))
+	}
+	if _, err := r.lists.SetWithTagsSince(ctx, fence, key, balances, r.ttl.Load(), tags...); err != nil {
+		contextLogger(ctx, r.logger).WithError(err).WithField("key", key).Warn("Failed to cache balances")
+	}
+	return balances, nil
+}
+
+func (r *CachedBalanceRepository) store(ctx context.Context, fence int64, key string, balance *models.Balance) {
+	tags := []string{accountCacheTag(balance.AccountID), balanceCacheTag(balance.BalanceID)}
+	if _, err := r.balances.SetWithTagsSince(ctx, fence, key, balance, r.ttl.Load(), tags...); err != nil {
+		contextLogger(ctx, r.logger).WithError(err).WithField("key", key).Warn("Failed to cache balance")
+	}
+}
+
+func (r *CachedBalanceRepository) invalidate(ctx context.Context, tags ...string) error {
+	return invalidateCacheTags(ctx, r.cache, r.logger, tags...)
+}
+
+func (r *CachedBalanceRepository) Upsert(ctx context.Context, balance *models.Balance) error {
+	if err := r.BalanceRepository.Upsert(ctx, balance); err != nil {
+		return err
+	}
+	return r.invalidate(ctx, accountCacheTag(balance.AccountID))
+}
+
+func (r *CachedBalanceRepository) UpdateAvailableBalance(ctx context.Context, balanceID string, availableBalance, lockedBalance float64) error {
+	if err := r.BalanceRepository.UpdateAvailableBalance(ctx, balanceID, availableBalance, lockedBalance); err != nil {
+		return err
+	}
+	return r.invalidate(ctx, balanceCacheTag(balanceID))
+}
+
+func (r *CachedBalanceRepository) AtomicUpdate(ctx context.Context, accountID, currency string, availableDelta, lockedDelta float64) error {
+	if err := r.BalanceRepository.AtomicUpdate(ctx, accountID, currency, availableDelta, lockedDelta); err != nil {
+		return err
+	}
+	return r.invalidate(ctx, accountCacheTag(accountID))
+}
@@ -0,0 +1,123 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/interfaces"
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/models"
+)
+
+// memoryBalanceRepository keeps balances in a map and counts reads.
+// duringRead, if set, runs inside the next read after the row was loaded.
+type memoryBalanceRepository struct {
+	interfaces.BalanceRepository
+	balances   map[string]models.Balance
+	reads      int
+	duringRead func()
+}
+
+func (r *memoryBalanceRepository) GetByAccountAndCurrency(ctx context.Context, accountID, currency string) (*models.Balance, error) {
+	for _, balance := range r.balances {
+		if balance.AccountID == accountID && balance.Currency == currency {
+			r.reads++
+			if hook := r.duringRead; hook != nil {
+				r.duringRead = nil
+				hook()
+			}
+			return &balance, nil
+		}
+	}
+	return nil, interfaces.ErrNotFound
+}
+
+func (r *memoryBalanceRepository) AtomicUpdate(ctx context.Context, accountID, currency string, availableDelta, lockedDelta float64) error {
+	for id, balance := range r.balances {
+		if balance.AccountID == accountID && balance.Currency == currency {
+			balance.AvailableBalance += availableDelta
+			balance.LockedBalance += lockedDelta
+			r.balances[id] = balance
+		}
+	}
+	return nil
+}
+
+// TestCachedBalanceRepository tests that balance reads are cached, dropped by
+// account writes and never stored when the fill raced a write
+func TestCachedBalanceRepository(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		racing    bool
+		available float64
+		reads     int
+	}{
+		{name: "cached read", available: 100, reads: 1},
+		{name: "fill racing a write", racing: true, available: 90, reads: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, client := newTestRedis(t)
+			inner := &memoryBalanceRepository{balances: map[string]models.Balance{
+				"B1": {BalanceID: "B1", AccountID: "ACC-1", Currency: "USD", AvailableBalance: 100},
+			}}
+			cache := NewRedisCacheRepository(client, "custodian", NewSlogLogger(nil))
+			cached := NewCachedBalanceRepository(inner, cache, time.Minute, NewSlogLogger(nil))
+
+			if tt.racing {
+				inner.duringRead = func() {
+					if err := cached.AtomicUpdate(ctx, "ACC-1", "USD", -10, 10); err != nil {
+						t.Fatalf("AtomicUpdate failed: %v", err)
+					}
+				}
+			}
+			if _, err := cached.GetByAccountAndCurrency(ctx, "ACC-1", "USD"); err != nil {
+				t.Fatalf("GetByAccountAndCurrency failed: %v", err)
+			}
+
+			balance, err := cached.GetByAccountAndCurrency(ctx, "ACC-1", "USD")
+			if err != nil {
+				t.Fatalf("GetByAccountAndCurrency failed: %v", err)
+			}
+			if balance.AvailableBalance != tt.available {
+				t.Errorf("available balance = %v, expected %v", balance.AvailableBalance, tt.available)
+			}
+			if inner.reads != tt.reads {
+				t.Errorf("inner reads = %d, expected %d", inner.reads, tt.reads)
+			}
+		})
+	}
+}
+
+// failingInvalidationCache fails every InvalidateTag
+type failingInvalidationCache struct {
+	interfaces.CacheRepository
+}
+
+func (c failingInvalidationCache) InvalidateTag(ctx context.Context, tag string) ([]string, error) {
+	return nil, errors.New("connection refused")
+}
+
+// TestCachedBalanceRepositoryInvalidationFailure tests that a committed write
+// whose invalidation fails is reported instead of leaving stale reads silently
+func TestCachedBalanceRepositoryInvalidationFailure(t *testing.T) {
+	ctx := context.Background()
+	_, client := newTestRedis(t)
+	inner := &memoryBalanceRepository{balances: map[string]models.Balance{
+		"B1": {BalanceID: "B1", AccountID: "ACC-1", Currency: "USD", AvailableBalance: 100},
+	}}
+	cache := failingInvalidationCache{NewRedisCacheRepository(client, "custodian", NewSlogLogger(nil))}
+	cached := NewCachedBalanceRepository(inner, cache, time.Minute, NewSlogLogger(nil))
+
+	err := cached.AtomicUpdate(ctx, "ACC-1", "USD", -10, 10)
+	if !errors.Is(err, ErrCacheNotInvalidated) {
+		t.Errorf("AtomicUpdate error = %v, expected ErrCacheNotInvalidated", err)
+	}
+	if balance := inner.balances["B1"]; balance.AvailableBalance != 90 {
+		t.Errorf("available balance = %v, expected the write to be committed", balance.AvailableBalance)
+	}
+}
@@ -0,0 +1,181 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/interfaces"
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/models"
+)
+
+// accountCacheTag groups all cached data of an account for InvalidateTag
+func accountCacheTag(accountID string) string {
+	return "account:" + accountID
+}
+
+func positionCacheTag(positionID string) string {
+	return "position:" + positionID
+}
+
+// ErrCacheNotInvalidated is returned by the cached repositories when a write
+// committed but the cached reads it affects could not be invalidated. The
+// write must not be retried; reads may be stale until the cache TTL.
+var ErrCacheNotInvalidated = errors.New("write committed but cached reads not invalidated")
+
+// invalidateCacheTags invalidates every tag, returning ErrCacheNotInvalidated
+// if any of them failed
+func invalidateCacheTags(ctx context.Context, cache interfaces.CacheRepository, logger interfaces.Logger, tags ...string) error {
+	var failed []error
+	for _, tag := range tags {
+		if _, err := cache.InvalidateTag(ctx, tag); err != nil {
+			contextLogger(ctx, logger).WithError(err).WithField("tag", tag).Error("Failed to invalidate cached reads")
+			failed = append(failed, err)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%w: %w", ErrCacheNotInvalidated, errors.Join(failed...))
+	}
+	return nil
+}
+
+// beginCacheFill takes the tag fence for a read that will fill the cache. If
+// no fence can be taken the result is not cached, as it could not be checked
+// against invalidations racing the read.
+func beginCacheFill(ctx context.Context, cache interfaces.CacheRepository, logger interfaces.Logger) (int64, bool) {
+	fence, err := cache.TagFence(ctx)
+	if err != nil {
+		contextLogger(ctx, logger).WithError(err).Warn("Failed to start cache fill")
+		return 0, false
+	}
+	return fence, true
+}
+
+// CachedPositionRepository serves position reads from the cache and
+// invalidates them by account and position tags on every write. Cache
+// failures degrade to reading the wrapped repository; a write whose
+// invalidation fails returns ErrCacheNotInvalidated. Misses read the
+// primary under a tag fence, and the fill is dropped if a write invalidated
+// any of its tags meanwhile, so an entry never predates such a write.
+type CachedPositionRepository struct {
+	interfaces.PositionRepository
+
+	cache     interfaces.CacheRepository
+	positions *TypedCache[*models.Position]
+	lists     *TypedCache[[]*models.Position]
+	ttl       *durationSetting // Read on each fill, so reloads apply
+	logger    interfaces.Logger
+}
+
+func NewCachedPositionRepository(inner interfaces.PositionRepository, cache interfaces.CacheRepository, ttl time.Duration, logger interfaces.Logger) interfaces.PositionRepository {
+	return newCachedPositionRepository(inner, cache, newDurationSetting(ttl), logger)
+}
+
+func newCachedPositionRepository(inner interfaces.PositionRepository, cache interfaces.CacheRepository, ttl *durationSetting, logger interfaces.Logger) interfaces.PositionRepository {
+	return &CachedPositionRepository{
+		PositionRepository: inner,
+		cache:              cache,
+		positions:          NewTypedCache[*models.Position](cache, JSONCodec{}, DefaultCompressionThreshold),
+		lists:              NewTypedCache[[]*models.Position](cache, JSONCodec{}, DefaultCompressionThreshold),
+		ttl:                ttl,
+		logger:             logger,
+	}
+}
+
+func (r *CachedPositionRepository) GetByID(ctx context.Context, positionID string) (*models.Position, error) {
+	key := fmt.Sprintf("position:id:%s", positionID)
+	if position, err := r.positions.Get(ctx, key); err == nil {
+		return position, nil
+	}
+
+	fence, fenced := beginCacheFill(ctx, r.cache, r.logger)
+	position, err := r.PositionRepository.GetByID(WithPrimaryReads(ctx), positionID)
+	if err != nil {
+		return nil, err
+	}
+	if fenced {
+		r.store(ctx, fence, key, position)
+	}
+	return position, nil
+}
+
+func (r *CachedPositionRepository) GetByAccountAndSymbol(ctx context.Context, accountID, symbol string) (*models.Position, error) {
+	key := fmt.Sprintf("position:account:%s:symbol:%s", accountID, symbol)
+	if position, err := r.positions.Get(ctx, key); err == nil {
+		return position, nil
+	}
+
+	fence, fenced := beginCacheFill(ctx, r.cache, r.logger)
+	position, err := r.PositionRepository.GetByAccountAndSymbol(WithPrimaryReads(ctx), accountID, symbol)
+	if err != nil {
+		return nil, err
+	}
+	if fenced {
+		r.store(ctx, fence, key, position)
+	}
+	return position, nil
+}
+
+func (r *CachedPositionRepository) GetByAccount(ctx context.Context, accountID string) ([]*models.Position, error) {
+	key := fmt.Sprintf("position:account:%s:all", accountID)
+	if positions, err := r.lists.Get(ctx, key); err == nil {
+		return positions, nil
+	}
+
+	fence, fenced := beginCacheFill(ctx, r.cache, r.logger)
+	positions, err := r.PositionRepository.GetByAccount(WithPrimaryReads(ctx), accountID)
+	if err != nil || !fenced {
+		return positions, err
+	}
+
+	// Tag the list with every member so a write to any one of them drops it
+	tags := []string{accountCacheTag(accountID)}
+	for _, position := range positions {
+		tags = append(tags, positionCacheTag(position.PositionID))
+	}
+	if _, err := r.lists.SetWithTagsSince(ctx, fence, key, positions, r.ttl.Load(), tags...); err != nil {
+		contextLogger(ctx, r.logger).WithError(err).WithField("key", key).Warn("Failed to cache positions")
+	}
+	return positions, nil
+}
+
+func (r *CachedPositionRepository) store(ctx context.Context, fence int64, key string, position *models.Position) {
+	tags := []string{accountCacheTag(position.AccountID), positionCacheTag(position.PositionID)}
+	if _, err := r.positions.SetWithTagsSince(ctx, fence, key, position, r.ttl.Load(), tags...); err != nil {
+		contextLogger(ctx, r.logger).WithError(err).WithField("key", key).Warn("Failed to cache position")
+	}
+}
+
+func (r *CachedPositionRepository) invalidate(ctx context.Context, tags ...string) error {
+	return invalidateCacheTags(ctx, r.cache, r.logger, tags...)
+}
+
+func (r *CachedPositionRepository) Create(ctx context.Context, position *models.Position) error {
+	if err := r.PositionRepository.Create(ctx, position); err != nil {
+		return err
+	}
+	return r.invalidate(ctx, accountCacheTag(position.AccountID))
+}
+
+func (r *CachedPositionRepository) Update(ctx context.Context, position *models.Position) error {
+	// The position tag also covers the list of a previous account if the update moves it
+	if err := r.PositionRepository.Update(ctx, position); err != nil {
+		return err
+	}
+	return r.invalidate(ctx, accountCacheTag(position.AccountID), positionCacheTag(position.PositionID))
+}
+
+func (r *CachedPositionRepository) UpdateAvailableQuantity(ctx context.Context, positionID string, availableQty, lockedQty float64) error {
+	if err := r.PositionRepository.UpdateAvailableQuantity(ctx, positionID, availableQty, lockedQty); err != nil {
+		return err
+	}
+	return r.invalidate(ctx, positionCacheTag(positionID))
+}
+
+func (r *CachedPositionRepository) Delete(ctx context.Context, positionID string) error {
+	if err := r.PositionRepository.Delete(ctx, positionID); err != nil {
+		return err
+	}
+	return r.invalidate(ctx, positionCacheTag(positionID))
+}
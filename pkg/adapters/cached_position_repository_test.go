@@ -0,0 +1,155 @@
+package adapters
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/interfaces"
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/models"
+)
+
+// memoryPositionRepository keeps positions in a map and counts reads.
+// duringRead, if set, runs inside the next read after the row was loaded.
+type memoryPositionRepository struct {
+	interfaces.PositionRepository
+	positions  map[string]models.Position
+	reads      int
+	duringRead func()
+}
+
+func (r *memoryPositionRepository) read() {
+	r.reads++
+	if hook := r.duringRead; hook != nil {
+		r.duringRead = nil
+		hook()
+	}
+}
+
+func (r *memoryPositionRepository) GetByID(ctx context.Context, positionID string) (*models.Position, error) {
+	position, ok := r.positions[positionID]
+	if !ok {
+		return nil, interfaces.ErrNotFound
+	}
+	r.read()
+	return &position, nil
+}
+
+func (r *memoryPositionRepository) GetByAccount(ctx context.Context, accountID string) ([]*models.Position, error) {
+	var positions []*models.Position
+	for _, position := range r.positions {
+		if position.AccountID == accountID {
+			position := position
+			positions = append(positions, &position)
+		}
+	}
+	r.read()
+	return positions, nil
+}
+
+func (r *memoryPositionRepository) UpdateAvailableQuantity(ctx context.Context, positionID string, availableQty, lockedQty float64) error {
+	position := r.positions[positionID]
+	position.AvailableQuantity, position.LockedQuantity = availableQty, lockedQty
+	r.positions[positionID] = position
+	return nil
+}
+
+// TestCachedPositionRepository tests cached reads, their invalidation by
+// writes and that a fill racing a write is not stored
+func TestCachedPositionRepository(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T) (*memoryPositionRepository, interfaces.PositionRepository) {
+		_, client := newTestRedis(t)
+		inner := &memoryPositionRepository{positions: map[string]models.Position{
+			"P1": {PositionID: "P1", AccountID: "ACC-1", Symbol: "BTC", AvailableQuantity: 1},
+			"P2": {PositionID: "P2", AccountID: "ACC-1", Symbol: "ETH", AvailableQuantity: 2},
+		}}
+		cache := NewRedisCacheRepository(client, "custodian", NewSlogLogger(nil))
+		return inner, NewCachedPositionRepository(inner, cache, time.Minute, NewSlogLogger(nil))
+	}
+
+	t.Run("read is cached until a write", func(t *testing.T) {
+		inner, cached := setup(t)
+		for i := 0; i < 2; i++ {
+			if _, err := cached.GetByID(ctx, "P1"); err != nil {
+				t.Fatalf("GetByID failed: %v", err)
+			}
+		}
+		if inner.reads != 1 {
+			t.Errorf("inner reads = %d, expected 1", inner.reads)
+		}
+
+		if err := cached.UpdateAvailableQuantity(ctx, "P1", 0.5, 0.5); err != nil {
+			t.Fatalf("UpdateAvailableQuantity failed: %v", err)
+		}
+		position, err := cached.GetByID(ctx, "P1")
+		if err != nil {
+			t.Fatalf("GetByID failed: %v", err)
+		}
+		if position.AvailableQuantity != 0.5 {
+			t.Errorf("available quantity = %v after update, expected 0.5", position.AvailableQuantity)
+		}
+	})
+
+	t.Run("member write drops account list", func(t *testing.T) {
+		inner, cached := setup(t)
+		if _, err := cached.GetByAccount(ctx, "ACC-1"); err != nil {
+			t.Fatalf("GetByAccount failed: %v", err)
+		}
+		if err := cached.UpdateAvailableQuantity(ctx, "P2", 1, 1); err != nil {
+			t.Fatalf("UpdateAvailableQuantity failed: %v", err)
+		}
+		if _, err := cached.GetByAccount(ctx, "ACC-1"); err != nil {
+			t.Fatalf("GetByAccount failed: %v", err)
+		}
+		if inner.reads != 2 {
+			t.Errorf("inner reads = %d, expected the list to be reloaded", inner.reads)
+		}
+	})
+
+	t.Run("fill racing a write is dropped", func(t *testing.T) {
+		inner, cached := setup(t)
+
+		// The write commits and invalidates after the miss read the old row
+		// but before the fill stores it
+		inner.duringRead = func() {
+			if err := cached.UpdateAvailableQuantity(ctx, "P1", 0.25, 0.75); err != nil {
+				t.Fatalf("UpdateAvailableQuantity failed: %v", err)
+			}
+		}
+		stale, err := cached.GetByID(ctx, "P1")
+		if err != nil {
+			t.Fatalf("GetByID failed: %v", err)
+		}
+		if stale.AvailableQuantity != 1 {
+			t.Fatalf("racing read = %v, expected the old row", stale.AvailableQuantity)
+		}
+
+		position, err := cached.GetByID(ctx, "P1")
+		if err != nil {
+			t.Fatalf("GetByID failed: %v", err)
+		}
+		if position.AvailableQuantity != 0.25 {
+			t.Errorf("available quantity = %v, expected 0.25: the racing fill was cached", position.AvailableQuantity)
+		}
+	})
+
+	t.Run("list fill racing a member write is dropped", func(t *testing.T) {
+		inner, cached := setup(t)
+		inner.duringRead = func() {
+			if err := cached.UpdateAvailableQuantity(ctx, "P2", 0, 2); err != nil {
+				t.Fatalf("UpdateAvailableQuantity failed: %v", err)
+			}
+		}
+		if _, err := cached.GetByAccount(ctx, "ACC-1"); err != nil {
+			t.Fatalf("GetByAccount failed: %v", err)
+		}
+		if _, err := cached.GetByAccount(ctx, "ACC-1"); err != nil {
+			t.Fatalf("GetByAccount failed: %v", err)
+		}
+		if inner.reads != 2 {
+			t.Errorf("inner reads = %d, expected the racing list fill to be dropped", inner.reads)
+		}
+	})
+}
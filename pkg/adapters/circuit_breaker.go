@@ -0,0 +1,295 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/interfaces"
+)
+
+// ErrCircuitOpen is returned without calling the store while a breaker is open
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// CircuitState is the state of a circuit breaker
+type CircuitState string
+
+const (
+	// CircuitClosed passes every call through and counts failures
+	CircuitClosed CircuitState = "closed"
+
+	// CircuitOpen rejects every call until OpenTimeout has passed
+	CircuitOpen CircuitState = "open"
+
+	// CircuitHalfOpen lets a limited number of trial calls through; one
+	// failure reopens the breaker, enough successes close it
+	CircuitHalfOpen CircuitState = "half-open"
+)
+
+// Circuit breaker defaults, used when the config leaves them unset
+const (
+	defaultCircuitFailureRate      = 0.5
+	defaultCircuitMinRequests      = 20
+	defaultCircuitWindow           = 30 * time.Second
+	defaultCircuitOpenTimeout      = 15 * time.Second
+	defaultCircuitHalfOpenRequests = 3
+)
+
+// CircuitBreakerConfig configures when a breaker opens and how it recovers
+type CircuitBreakerConfig struct {
+	// FailureRate opens the breaker once this share of calls in the current
+	// window failed (0.5 if unset)
+	FailureRate float64
+
+	// MinRequests is the number of calls a window needs before the failure
+	// rate is evaluated (20 if unset)
+	MinRequests int
+
+	// Window is the length of the counting window (30s if unset)
+	Window time.Duration
+
+	// OpenTimeout is how long the breaker stays open before trial calls
+	// are let through (15s if unset)
+	OpenTimeout time.Duration
+
+	// HalfOpenRequests is the number of trial calls that must succeed to
+	// close the breaker again (3 if unset)
+	HalfOpenRequests int
+}
+
+// CircuitSnapshot is the reportable state of a breaker
+type CircuitSnapshot struct {
+	State    CircuitState `json:"state"`
+	Requests int          `json:"requests"`
+	Failures int          `json:"failures"`
+	OpenedAt *time.Time   `json:"opened_at,omitempty"`
+}
+
+// CircuitBreaker guards calls to one dependency. Failures are counted over
+// fixed windows; only errors that indicate the dependency is unhealthy count,
+// not missing records, constraint violations or cancelled callers.
+type CircuitBreaker struct {
+	name   string
+	config CircuitBreakerConfig
+	logger interfaces.Logger
+
+	mu          sync.Mutex
+	state       CircuitState
+	generation  uint64
+	windowStart time.Time
+	requests    int
+	failures    int
+	openedAt    time.Time
+	trials      int
+	successes   int
+
+	now func() time.Time
+}
+
+func NewCircuitBreaker(name string, cfg CircuitBreakerConfig, logger interfaces.Logger) *CircuitBreaker {
+	if cfg.FailureRate <= 0 || cfg.FailureRate > 1 {
+		cfg.FailureRate = defaultCircuitFailureRate
+	}
+	if cfg.MinRequests <= 0 {
+		cfg.MinRequests = defaultCircuitMinRequests
+	}
+	cfg.Window = durationOrDefault(cfg.Window, defaultCircuitWindow)
+	cfg.OpenTimeout = durationOrDefault(cfg.OpenTimeout, defaultCircuitOpenTimeout)
+	if cfg.HalfOpenRequests <= 0 {
+		cfg.HalfOpenRequests = defaultCircuitHalfOpenRequests
+	}
+
+	return &CircuitBreaker{
+		name:   name,
+		config: cfg,
+		logger: logger,
+		state:  CircuitClosed,
+		now:    time.Now,
+	}
+}
+
+// State returns the current state, moving an expired open breaker to half-open
+func (cb *CircuitBreaker) State() CircuitState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.advance(cb.now())
+	return cb.state
+}
+
+func (cb *CircuitBreaker) Snapshot() *CircuitSnapshot {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.advance(cb.now())
+
+	snapshot := &CircuitSnapshot{
+		State:    cb.state,
+		Requests: cb.requests,
+		Failures: cb.failures,
+	}
+	if cb.state != CircuitClosed {
+		openedAt := cb.openedAt
+		snapshot.OpenedAt = &openedAt
+	}
+	return snapshot
+}
+
+// Execute runs fn if the breaker admits the call and records its outcome
+func (cb *CircuitBreaker) Execute(ctx context.Context, fn func(ctx context.Context) error) error {
+	generation, err := cb.allow()
+	if err != nil {
+		return err
+	}
+	err = fn(ctx)
+	cb.record(generation, isDependencyFailure(err))
+	return err
+}
+
+// Interceptor guards repository calls with the breaker. With failOpenReads,
+// rejected or failed read-only calls return an error wrapping
+// interfaces.ErrCacheMiss, so cache readers fall through to the database.
+func (cb *CircuitBreaker) Interceptor(failOpenReads bool) CallInterceptor {
+	return func(ctx context.Context, call RepositoryCall, next func(ctx context.Context) error) error {
+		err := cb.Execute(ctx, next)
+		if err != nil && failOpenReads && call.ReadOnly && !errors.Is(err, interfaces.ErrCacheMiss) &&
+			(errors.Is(err, ErrCircuitOpen) || isDependencyFailure(err)) {
+			return fmt.Errorf("%w: %w", interfaces.ErrCacheMiss, err)
+		}
+		return err
+	}
+}
+
+func (cb *CircuitBreaker) allow() (uint64, error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	now := cb.now()
+	cb.advance(now)
+
+	switch cb.state {
+	case CircuitOpen:
+		return 0, fmt.Errorf("%w: %s", ErrCircuitOpen, cb.name)
+	case CircuitHalfOpen:
+		if cb.trials >= cb.config.HalfOpenRequests {
+			return 0, fmt.Errorf("%w: %s (half-open)", ErrCircuitOpen, cb.name)
+		}
+		cb.trials++
+	default:
+		if now.Sub(cb.windowStart) >= cb.config.Window {
+			cb.windowStart = now
+			cb.requests = 0
+			cb.failures = 0
+		}
+	}
+	return cb.generation, nil
+}
+
+// record counts the outcome of a call admitted in the given generation.
+// Outcomes of calls admitted before the last state change are ignored.
+func (cb *CircuitBreaker) record(generation uint64, failed bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if generation != cb.generation {
+		return
+	}
+
+	switch cb.state {
+	case CircuitClosed:
+		cb.requests++
+		if failed {
+			cb.failures++
+		}
+		if cb.requests >= cb.config.MinRequests &&
+			float64(cb.failures)/float64(cb.requests) >= cb.config.FailureRate {
+			cb.transition(CircuitOpen)
+		}
+
+	case CircuitHalfOpen:
+		if failed {
+			cb.transition(CircuitOpen)
+			return
+		}
+		cb.successes++
+		if cb.successes >= cb.config.HalfOpenRequests {
+			cb.transition(CircuitClosed)
+		}
+	}
+}
+
+// advance moves an open breaker to half-open once OpenTimeout has passed
+func (cb *CircuitBreaker) advance(now time.Time) {
+	if cb.state == CircuitOpen && now.Sub(cb.openedAt) >= cb.config.OpenTimeout {
+		cb.transition(CircuitHalfOpen)
+	}
+}
+
+func (cb *CircuitBreaker) transition(state CircuitState) {
+	now := cb.now()
+	from := cb.state
+
+	cb.state = state
+	cb.generation++
+	cb.trials = 0
+	cb.successes = 0
+
+	switch state {
+	case CircuitOpen:
+		cb.openedAt = now
+	case CircuitClosed:
+		cb.windowStart = now
+		cb.requests = 0
+		cb.failures = 0
+	}
+
+	if cb.logger != nil {
+		entry := cb.logger.WithFields(interfaces.LogFields{
+			"circuit":  cb.name,
+			"from":     from,
+			"to":       state,
+			"requests": cb.requests,
+			"failures": cb.failures,
+		})
+		if state == CircuitOpen {
+			entry.Warn("Circuit breaker opened")
+		} else {
+			entry.Info("Circuit breaker state changed")
+		}
+	}
+}
+
+// isDependencyFailure reports whether err indicates the dependency itself is
+// failing, as opposed to an expected outcome of a healthy call
+func isDependencyFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	for _, expected := range []error{
+		interfaces.ErrNotFound,
+		interfaces.ErrCacheMiss,
+		interfaces.ErrLockNotAcquired,
+		interfaces.ErrLockNotHeld,
+		interfaces.ErrInvalidAccount,
+		interfaces.ErrInvalidAsset,
+		ErrCircuitOpen,
+		context.Canceled,
+	} {
+		if errors.Is(err, expected) {
+			return false
+		}
+	}
+
+	// Data exceptions and integrity constraint violations are the caller's
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code.Class() {
+		case "22", "23":
+			return false
+		}
+	}
+
+	return true
+}
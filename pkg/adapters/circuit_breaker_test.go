@@ -0,0 +1,101 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/interfaces"
+)
+
+// TestCircuitBreakerTransitions tests closed -> open -> half-open -> closed and reopening
+func TestCircuitBreakerTransitions(t *testing.T) {
+	now := time.Unix(0, 0)
+	cb := NewCircuitBreaker("redis", CircuitBreakerConfig{
+		FailureRate:      0.5,
+		MinRequests:      4,
+		Window:           time.Minute,
+		OpenTimeout:      10 * time.Second,
+		HalfOpenRequests: 2,
+	}, nil)
+	cb.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	timeout := errors.New("i/o timeout")
+	succeed := func(ctx context.Context) error { return nil }
+	fail := func(ctx context.Context) error { return timeout }
+	notFound := func(ctx context.Context) error { return fmt.Errorf("position %w: p1", interfaces.ErrNotFound) }
+
+	// Missing records are not dependency failures
+	for i := 0; i < 4; i++ {
+		_ = cb.Execute(ctx, notFound)
+	}
+	if state := cb.State(); state != CircuitClosed {
+		t.Fatalf("state after not-found errors = %s, expected %s", state, CircuitClosed)
+	}
+
+	// A new window opens once half of its calls fail
+	now = now.Add(time.Minute)
+	_ = cb.Execute(ctx, succeed)
+	_ = cb.Execute(ctx, fail)
+	_ = cb.Execute(ctx, succeed)
+	if state := cb.State(); state != CircuitClosed {
+		t.Fatalf("state below MinRequests = %s, expected %s", state, CircuitClosed)
+	}
+	_ = cb.Execute(ctx, fail)
+	if state := cb.State(); state != CircuitOpen {
+		t.Fatalf("state at failure rate = %s, expected %s", state, CircuitOpen)
+	}
+
+	called := false
+	err := cb.Execute(ctx, func(ctx context.Context) error { called = true; return nil })
+	if !errors.Is(err, ErrCircuitOpen) || called {
+		t.Fatalf("open breaker: err = %v, called = %v", err, called)
+	}
+
+	// After OpenTimeout a failed trial reopens the breaker
+	now = now.Add(10 * time.Second)
+	if state := cb.State(); state != CircuitHalfOpen {
+		t.Fatalf("state after OpenTimeout = %s, expected %s", state, CircuitHalfOpen)
+	}
+	_ = cb.Execute(ctx, fail)
+	if state := cb.State(); state != CircuitOpen {
+		t.Fatalf("state after failed trial = %s, expected %s", state, CircuitOpen)
+	}
+
+	// Enough successful trials close it
+	now = now.Add(10 * time.Second)
+	_ = cb.Execute(ctx, succeed)
+	_ = cb.Execute(ctx, succeed)
+	if state := cb.State(); state != CircuitClosed {
+		t.Fatalf("state after successful trials = %s, expected %s", state, CircuitClosed)
+	}
+}
+
+// TestCircuitBreakerFailOpenReads tests that cache reads degrade to misses while writes fail
+func TestCircuitBreakerFailOpenReads(t *testing.T) {
+	cb := NewCircuitBreaker("redis", CircuitBreakerConfig{MinRequests: 2, FailureRate: 0.5}, nil)
+	cache := NewInterceptedCacheRepository(newMemoryCache(), cb.Interceptor(true))
+	ctx := context.Background()
+
+	if _, err := cache.Get(ctx, "missing"); !IsCacheMiss(err) {
+		t.Fatalf("Get(missing) error = %v, expected cache miss", err)
+	}
+	if state := cb.State(); state != CircuitClosed {
+		t.Fatalf("state after cache miss = %s, expected %s", state, CircuitClosed)
+	}
+
+	// Force the breaker open with a failing call
+	_ = cb.Execute(ctx, func(ctx context.Context) error { return errors.New("connection refused") })
+
+	_, err := cache.Get(ctx, "key")
+	if !IsCacheMiss(err) || !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Get with open breaker error = %v, expected cache miss wrapping ErrCircuitOpen", err)
+	}
+	err = cache.Set(ctx, "key", "value", 0)
+	if IsCacheMiss(err) || !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Set with open breaker error = %v, expected ErrCircuitOpen", err)
+	}
+}
@@ -0,0 +1,187 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/internal/config"
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/interfaces"
+)
+
+// ReloadableSettings are the settings a ConfigWatcher applies without a restart
+type ReloadableSettings struct {
+	LogLevel          string
+	CacheTTL          time.Duration
+	LocalCacheTTL     time.Duration
+	HeartbeatInterval time.Duration
+	ServiceTTL        time.Duration
+}
+
+func newReloadableSettings(s config.ReloadableSettings) ReloadableSettings {
+	return ReloadableSettings{
+		LogLevel:          s.LogLevel,
+		CacheTTL:          s.CacheTTL,
+		LocalCacheTTL:     s.LocalCacheTTL,
+		HeartbeatInterval: s.HeartbeatInterval,
+		ServiceTTL:        s.ServiceTTL,
+	}
+}
+
+// ConfigWatcher polls a configuration file and hot-reloads the reloadable
+// settings. Other changes take effect on restart; a file that fails to load
+// or validate is reported and the current settings are kept.
+type ConfigWatcher struct {
+	watcher *config.Watcher
+}
+
+// NewConfigWatcher loads the configuration file at path (in the layers of
+// LoadConfig) and polls it every interval, 5s if not positive
+func NewConfigWatcher(path string, interval time.Duration) (*ConfigWatcher, error) {
+	watcher, err := config.NewWatcher(path, interval)
+	if err != nil {
+		return nil, err
+	}
+	return &ConfigWatcher{watcher: watcher}, nil
+}
+
+// Settings returns the reloadable settings currently in effect
+func (w *ConfigWatcher) Settings() ReloadableSettings {
+	return newReloadableSettings(w.watcher.Current().Reloadable())
+}
+
+// Subscribe registers fn for reload notifications and returns a function
+// that removes it
+func (w *ConfigWatcher) Subscribe(fn func(previous, current ReloadableSettings)) (unsubscribe func()) {
+	return w.watcher.Subscribe(func(previous, current config.ReloadableSettings) {
+		fn(newReloadableSettings(previous), newReloadableSettings(current))
+	})
+}
+
+// OnError sets the handler for files that fail to load and for changes
+// that need a restart
+func (w *ConfigWatcher) OnError(fn func(error)) {
+	w.watcher.OnError(fn)
+}
+
+// Reload re-reads the file now
+func (w *ConfigWatcher) Reload() error {
+	return w.watcher.Reload()
+}
+
+// Start polls the file until ctx is done or Stop is called
+func (w *ConfigWatcher) Start(ctx context.Context) {
+	w.watcher.Start(ctx)
+}
+
+// Stop ends polling started by Start
+func (w *ConfigWatcher) Stop() {
+	w.watcher.Stop()
+}
+
+// NewCustodianDataAdapterFromWatcher creates an adapter from the watched
+// configuration. The adapter starts the watcher on Connect, stops it on
+// Disconnect and applies reloaded settings to its live repositories.
+func NewCustodianDataAdapterFromWatcher(watcher *ConfigWatcher, opts ...Option) (DataAdapter, error) {
+	adapter, err := newCustodianDataAdapter(watcher.watcher.Current(), opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	adapter.watcher = watcher
+	watcher.OnError(func(err error) {
+		adapter.logger.WithError(err).Warn("Configuration file not reloaded")
+	})
+	watcher.Subscribe(adapter.applySettings)
+	return adapter, nil
+}
+
+// watchedConfigFile returns the file and poll interval NewCustodianDataAdapterFromEnv
+// watches: CONFIG_FILE when CONFIG_WATCH_INTERVAL is set
+func watchedConfigFile() (string, time.Duration, error) {
+	path, value := os.Getenv("CONFIG_FILE"), os.Getenv("CONFIG_WATCH_INTERVAL")
+	if path == "" || value == "" {
+		return "", 0, nil
+	}
+	interval, err := time.ParseDuration(value)
+	if err != nil || interval <= 0 {
+		return "", 0, fmt.Errorf("invalid CONFIG_WATCH_INTERVAL %q: expected a positive duration", value)
+	}
+	return path, interval, nil
+}
+
+// applySettings applies reloaded settings to the live adapter
+func (a *CustodianDataAdapter) applySettings(previous, current ReloadableSettings) {
+	a.settings.cacheTTL.Store(current.CacheTTL)
+	a.settings.heartbeatInterval.Store(current.HeartbeatInterval)
+	a.settings.serviceTTL.Store(current.ServiceTTL)
+	if a.tieredCache != nil {
+		if err := a.tieredCache.SetLocalTTL(current.LocalCacheTTL); err != nil {
+			a.logger.WithError(err).Warn("Local cache TTL not changed")
+		}
+	}
+
+	if current.LogLevel != previous.LogLevel {
+		a.setLogLevel(current.LogLevel)
+	}
+
+	a.logger.WithFields(interfaces.LogFields{
+		"log_level":          current.LogLevel,
+		"cache_ttl":          current.CacheTTL.String(),
+		"local_cache_ttl":    current.LocalCacheTTL.String(),
+		"heartbeat_interval": current.HeartbeatInterval.String(),
+		"service_ttl":        current.ServiceTTL.String(),
+	}).Info("Configuration reloaded")
+}
+
+// setLogLevel changes the logger's level if it supports runtime changes
+func (a *CustodianDataAdapter) setLogLevel(value string) {
+	level, err := ParseLogLevel(value)
+	if err != nil {
+		a.logger.WithError(err).Warn("Log level not changed")
+		return
+	}
+
+	inner := a.logger
+	if redacting, ok := inner.(*redactingLogger); ok {
+		inner = redacting.inner
+	}
+	setter, ok := inner.(interfaces.LevelSetter)
+	if !ok {
+		a.logger.WithField("log_level", value).Warn("Logger does not support runtime level changes")
+		return
+	}
+	setter.SetLevel(level)
+}
+
+// liveSettings holds the reloadable settings that components read on each
+// use, so a reload reaches repositories created at startup
+type liveSettings struct {
+	cacheTTL          *durationSetting
+	heartbeatInterval *durationSetting
+	serviceTTL        *durationSetting
+}
+
+func newLiveSettings(cfg *config.Config) liveSettings {
+	return liveSettings{
+		cacheTTL:          newDurationSetting(cfg.CacheTTL),
+		heartbeatInterval: newDurationSetting(cfg.HeartbeatInterval),
+		serviceTTL:        newDurationSetting(cfg.ServiceTTL),
+	}
+}
+
+// durationSetting is a duration that may be replaced while in use
+type durationSetting struct {
+	value atomic.Int64
+}
+
+func newDurationSetting(d time.Duration) *durationSetting {
+	s := &durationSetting{}
+	s.Store(d)
+	return s
+}
+
+func (s *durationSetting) Load() time.Duration   { return time.Duration(s.value.Load()) }
+func (s *durationSetting) Store(d time.Duration) { s.value.Store(int64(d)) }
@@ -0,0 +1,137 @@
+package adapters
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TestConfigWatcherReload tests that editing the watched file changes the
+// cache TTL and log level of a connected adapter
+func TestConfigWatcherReload(t *testing.T) {
+	server, client := newTestRedis(t)
+	path := filepath.Join(t.TempDir(), "custodian.yaml")
+	writeConfig := func(content string, modified time.Time) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+		if err := os.Chtimes(path, modified, modified); err != nil {
+			t.Fatalf("Chtimes failed: %v", err)
+		}
+	}
+	writeConfig("service_instance_name: custodian-simulator\ncache_repositories: true\ncache_ttl: 5m\nlog_level: info\n", time.Now())
+
+	watcher, err := NewConfigWatcher(path, 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("NewConfigWatcher failed: %v", err)
+	}
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	adapter, err := NewCustodianDataAdapterFromWatcher(watcher,
+		WithLogger(NewLogrusLogger(logger)),
+		WithRedisClient(client),
+		WithPositionRepository(&stubPositionRepository{}),
+		WithBalanceRepository(&stubQueryBalanceRepository{}),
+	)
+	if err != nil {
+		t.Fatalf("NewCustodianDataAdapterFromWatcher failed: %v", err)
+	}
+	ctx := context.Background()
+	if err := adapter.Connect(ctx); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer adapter.Disconnect(ctx)
+
+	cachedTTL := func(positionID string) time.Duration {
+		t.Helper()
+		if _, err := adapter.PositionRepository().GetByID(ctx, positionID); err != nil {
+			t.Fatalf("GetByID failed: %v", err)
+		}
+		for _, key := range server.Keys() {
+			if strings.HasSuffix(key, "position:id:"+positionID) {
+				return server.TTL(key)
+			}
+		}
+		t.Fatalf("position %s not cached", positionID)
+		return 0
+	}
+
+	if ttl := cachedTTL("pos-1"); ttl != 5*time.Minute {
+		t.Errorf("TTL before reload = %v, expected 5m", ttl)
+	}
+
+	writeConfig("service_instance_name: custodian-simulator\ncache_repositories: true\ncache_ttl: 1m\nlog_level: debug\n", time.Now().Add(time.Second))
+	deadline := time.Now().Add(5 * time.Second)
+	for logger.GetLevel() != logrus.DebugLevel {
+		if time.Now().After(deadline) {
+			t.Fatalf("log level = %v after editing the file, expected debug", logger.GetLevel())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if ttl := cachedTTL("pos-2"); ttl != time.Minute {
+		t.Errorf("TTL after reload = %v, expected 1m", ttl)
+	}
+	if got := watcher.Settings().CacheTTL; got != time.Minute {
+		t.Errorf("Settings().CacheTTL = %v, expected 1m", got)
+	}
+}
+
+// TestConfigWatcherStartStop tests that the watcher can be started and
+// stopped repeatedly and concurrently
+func TestConfigWatcherStartStop(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "custodian.json")
+	if err := os.WriteFile(path, []byte(`{"log_level": "info"}`), 0o600); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	watcher, err := NewConfigWatcher(path, time.Millisecond)
+	if err != nil {
+		t.Fatalf("NewConfigWatcher failed: %v", err)
+	}
+
+	done := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		go func() {
+			defer func() { done <- struct{}{} }()
+			for j := 0; j < 50; j++ {
+				watcher.Start(context.Background())
+				watcher.Stop()
+			}
+		}()
+	}
+	for i := 0; i < 4; i++ {
+		<-done
+	}
+	watcher.Stop()
+}
+
+// TestParseLogLevel tests the accepted LOG_LEVEL values
+func TestParseLogLevel(t *testing.T) {
+	tests := []struct {
+		value       string
+		expectError bool
+	}{
+		{value: "debug"},
+		{value: "INFO"},
+		{value: "warning"},
+		{value: "error"},
+		{value: "verbose", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			_, err := ParseLogLevel(tt.value)
+			if (err != nil) != tt.expectError {
+				t.Errorf("ParseLogLevel(%q) error = %v, expectError %v", tt.value, err, tt.expectError)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ConnectPolicy controls how Connect handles unreachable dependencies
+type ConnectPolicy string
+
+const (
+	// ConnectPolicyStrict fails Connect on the first unreachable dependency
+	ConnectPolicyStrict ConnectPolicy = "strict"
+
+	// ConnectPolicyRetry retries with exponential backoff and jitter until
+	// ConnectRetryDeadline, then fails
+	ConnectPolicyRetry ConnectPolicy = "retry"
+
+	// ConnectPolicyLenient returns immediately and reconnects in the background;
+	// Readiness stays false until every dependency is connected
+	ConnectPolicyLenient ConnectPolicy = "lenient"
+)
+
+// Connect retry defaults, used when the config leaves them unset
+const (
+	defaultConnectRetryDeadline  = 60 * time.Second
+	defaultConnectInitialBackoff = 500 * time.Millisecond
+	defaultConnectMaxBackoff     = 10 * time.Second
+)
+
+// ParseConnectPolicy validates a policy name; empty selects lenient
+func ParseConnectPolicy(s string) (ConnectPolicy, error) {
+	switch policy := ConnectPolicy(s); policy {
+	case "":
+		return ConnectPolicyLenient, nil
+	case ConnectPolicyStrict, ConnectPolicyRetry, ConnectPolicyLenient:
+		return policy, nil
+	}
+	return "", fmt.Errorf("unknown connect policy %q (expected strict, retry or lenient)", s)
+}
+
+// backoff produces exponentially growing, jittered delays capped at max
+type backoff struct {
+	next time.Duration
+	max  time.Duration
+}
+
+func newBackoff(initial, max time.Duration) *backoff {
+	if initial <= 0 {
+		initial = defaultConnectInitialBackoff
+	}
+	if max <= 0 {
+		max = defaultConnectMaxBackoff
+	}
+	if max < initial {
+		max = initial
+	}
+	return &backoff{next: initial, max: max}
+}
+
+// Delay returns the next delay, drawn uniformly from [d/2, d)
+func (b *backoff) Delay() time.Duration {
+	d := b.next
+	b.next *= 2
+	if b.next > b.max {
+		b.next = b.max
+	}
+	return d/2 + jitter(d/2)
+}
+
+// isPermanentConnectError reports connect errors that retrying cannot fix:
+// another live instance owns the storage, the data is in a legacy schema or
+// the schema lacks tables
+func isPermanentConnectError(err error) bool {
+	return errors.Is(err, ErrInstanceConflict) || errors.Is(err, ErrLegacySchema) || errors.Is(err, ErrSchemaNotMigrated)
+}
+
+// retryWithBackoff calls attempt until it succeeds, deadline passes or ctx is
+// cancelled, returning the last attempt error in the latter cases. A zero
+// deadline retries until ctx is cancelled. Permanent errors are returned
+// without retrying.
+func retryWithBackoff(ctx context.Context, deadline time.Time, b *backoff, attempt func(ctx context.Context) error) error {
+	for {
+		err := attempt(ctx)
+		if err == nil || isPermanentConnectError(err) {
+			return err
+		}
+
+		wait := b.Delay()
+		if !deadline.IsZero() {
+			remaining := time.Until(deadline)
+			if remaining <= 0 {
+				return err
+			}
+			if wait > remaining {
+				wait = remaining
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w (last error: %v)", ctx.Err(), err)
+		case <-time.After(wait):
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package adapters
+
+import (
+	"context"
+	"sync"
+
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/interfaces"
+)
+
+// RotatingCredentials is a CredentialsProvider whose credentials are replaced
+// with Rotate, e.g. by a secrets manager watcher
+type RotatingCredentials struct {
+	mu          sync.Mutex
+	current     interfaces.Credentials
+	subscribers map[int]func()
+	nextID      int
+}
+
+// NewRotatingCredentials starts with the given credentials
+func NewRotatingCredentials(initial interfaces.Credentials) *RotatingCredentials {
+	return &RotatingCredentials{
+		current:     initial,
+		subscribers: map[int]func(){},
+	}
+}
+
+func (c *RotatingCredentials) Credentials(ctx context.Context) (interfaces.Credentials, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.current, nil
+}
+
+func (c *RotatingCredentials) Subscribe(fn func()) (unsubscribe func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	id := c.nextID
+	c.nextID++
+	c.subscribers[id] = fn
+
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		delete(c.subscribers, id)
+	}
+}
+
+// Rotate replaces the credentials and notifies subscribers. Busy PostgreSQL
+// connections are only closed once released, so the old credentials should
+// stay valid for a grace period.
+func (c *RotatingCredentials) Rotate(credentials interfaces.Credentials) {
+	c.mu.Lock()
+	c.current = credentials
+	subscribers := make([]func(), 0, len(c.subscribers))
+	for _, fn := range c.subscribers {
+		subscribers = append(subscribers, fn)
+	}
+	c.mu.Unlock()
+
+	for _, fn := range subscribers {
+		fn()
+	}
+}
@@ -2,14 +2,19 @@ package adapters
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
+	"net/http"
+	"net/url"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/internal/cache"
 	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/internal/config"
 	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/internal/database"
 	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/interfaces"
-	"github.com/sirupsen/logrus"
 )
 
 type DataAdapter interface {
@@ -26,28 +31,158 @@ type DataAdapter interface {
 	HealthCheck(ctx context.Context) error
 }
 
+// The interfaces below are optional DataAdapter extensions, so existing
+// DataAdapter implementations keep compiling. CustodianDataAdapter
+// implements all of them; check for one with a type assertion.
+
+// ReferenceDataAdapter exposes the account and asset reference data
+type ReferenceDataAdapter interface {
+	AccountRepository() interfaces.AccountRepository
+	AssetRepository() interfaces.AssetRepository
+}
+
+// CoordinationAdapter exposes distributed locks and leader election
+type CoordinationAdapter interface {
+	LockRepository() interfaces.LockRepository
+	NewLeaderElector(election string, cfg LeaderElectionConfig) (interfaces.LeaderElector, error)
+}
+
+// HealthReporter reports per-component health and probe results
+type HealthReporter interface {
+	HealthReport(ctx context.Context) *HealthReport
+	Liveness(ctx context.Context) *ProbeResult
+	Readiness(ctx context.Context) *ProbeResult
+}
+
+// ObservableAdapter exposes metrics and accepts a tracer
+type ObservableAdapter interface {
+	MetricsHandler() http.Handler
+	SetTracer(tracer interfaces.Tracer)
+}
+
+var (
+	_ DataAdapter          = (*CustodianDataAdapter)(nil)
+	_ ReferenceDataAdapter = (*CustodianDataAdapter)(nil)
+	_ CoordinationAdapter  = (*CustodianDataAdapter)(nil)
+	_ HealthReporter       = (*CustodianDataAdapter)(nil)
+	_ ObservableAdapter    = (*CustodianDataAdapter)(nil)
+)
+
 type CustodianDataAdapter struct {
 	config *config.Config
-	logger *logrus.Logger
+	logger interfaces.Logger
 
 	// Infrastructure
 	postgresDB  *database.PostgresDB
 	redisClient *cache.RedisClient
 
+	// Read replicas and the router sending repository reads to them
+	postgresReplicas []*database.PostgresDB
+	reads            *readRouter
+
 	// Repositories
 	positionRepo         interfaces.PositionRepository
 	settlementRepo       interfaces.SettlementRepository
 	balanceRepo          interfaces.BalanceRepository
+	accountRepo          interfaces.AccountRepository
+	assetRepo            interfaces.AssetRepository
 	serviceDiscoveryRepo interfaces.ServiceDiscoveryRepository
 	cacheRepo            interfaces.CacheRepository
+	lockRepo             interfaces.LockRepository
+
+	// Optional in-process cache tier, started on Connect
+	tieredCache *TieredCacheRepository
+
+	// Ownership claims on the schema and Redis namespaces (nil when disabled)
+	claims *instanceClaims
+
+	// Reloadable settings, and the watcher reloading them (nil when unwatched)
+	settings liveSettings
+	watcher  *ConfigWatcher
+
+	// Schema and namespace earlier releases derived for this instance, checked
+	// on connect (empty when the names are explicit or unchanged)
+	legacySchema    string
+	legacyNamespace string
+
+	// Registered third-party backend, used instead of Postgres and Redis
+	backend          Backend
+	backendConnected atomic.Bool
+
+	// Per-dependency circuit breakers (nil when disabled)
+	postgresBreaker *CircuitBreaker
+	redisBreaker    *CircuitBreaker
+
+	// Repository call and connection pool metrics
+	metrics *Metrics
+
+	// Tracer invoked around every repository call (no-op until SetTracer)
+	tracer *tracerHolder
+
+	// Connection state
+	connectPolicy     ConnectPolicy
+	postgresConnected atomic.Bool
+	redisConnected    atomic.Bool
+	stopReconnect     context.CancelFunc
+	reconnectWG       sync.WaitGroup
+
+	// Health
+	health       *healthTracker
+	disconnected atomic.Bool
+}
+
+// dependency is a backing store Connect must reach
+type dependency struct {
+	name        string
+	connect     func(ctx context.Context) error
+	connected   *atomic.Bool
+	onConnected func(ctx context.Context)
+}
+
+// NewCustodianDataAdapter builds the adapter for cfg.Backend. Options supply
+// a logger, tracer or existing clients, and replace or decorate individual
+// repositories.
+func NewCustodianDataAdapter(cfg *config.Config, opts ...Option) (DataAdapter, error) {
+	adapter, err := newCustodianDataAdapter(cfg, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return adapter, nil
 }
 
-func NewCustodianDataAdapter(cfg *config.Config, logger *logrus.Logger) (DataAdapter, error) {
+func newCustodianDataAdapter(cfg *config.Config, opts ...Option) (*CustodianDataAdapter, error) {
 	if cfg == nil {
 		return nil, fmt.Errorf("config is required")
 	}
+
+	options := &adapterOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	logger := options.logger
 	if logger == nil {
-		return nil, fmt.Errorf("logger is required")
+		logger = NewSlogLogger(nil)
+	}
+	if cfg.LogRedaction {
+		logger = NewRedactingLogger(logger, RedactionConfig{RevealAtDebug: cfg.LogRevealAtDebug})
+	}
+
+	if cfg.Backend == "" {
+		cfg.Backend = BackendPostgres
+	}
+
+	// Derived names must not collide with another instance's
+	if cfg.SchemaName == "" || cfg.RedisNamespace == "" {
+		if err := checkDerivableInstanceName(cfg.ServiceName, cfg.ServiceInstanceName); err != nil {
+			return nil, err
+		}
+	}
+
+	// Instances named with more than two parts used to share a schema
+	legacySchema, legacyNamespace := "", ""
+	if cfg.SchemaName == "" && cfg.RedisNamespace == "" {
+		legacySchema, legacyNamespace = legacyInstanceNames(cfg.ServiceName, cfg.ServiceInstanceName)
 	}
 
 	// Apply derivation if schema name not explicitly provided
@@ -60,97 +195,531 @@ func NewCustodianDataAdapter(cfg *config.Config, logger *logrus.Logger) (DataAda
 		cfg.RedisNamespace = deriveRedisNamespace(cfg.ServiceName, cfg.ServiceInstanceName)
 	}
 
-	logger.WithFields(logrus.Fields{
+	// Cached rows, locks and invalidations belong to the instance's data
+	if cfg.CacheNamespace == "" {
+		cfg.CacheNamespace = cfg.RedisNamespace
+	}
+	// Earlier .env.example files set CACHE_NAMESPACE=custodian for every
+	// instance; keep honouring it so existing deployments start, but flag it
+	if cfg.SchemaName != defaultSchemaName && cfg.CacheNamespace == defaultSchemaName {
+		logger.WithFields(interfaces.LogFields{
+			"schema_name":     cfg.SchemaName,
+			"cache_namespace": cfg.CacheNamespace,
+			"redis_namespace": cfg.RedisNamespace,
+		}).Warn("Cache namespace is shared by the singleton instance; unset CACHE_NAMESPACE to give this instance its own")
+	}
+
+	connectPolicy, err := ParseConnectPolicy(cfg.ConnectPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.WithFields(interfaces.LogFields{
 		"service_name":     cfg.ServiceName,
 		"instance_name":    cfg.ServiceInstanceName,
+		"backend":          cfg.Backend,
 		"schema_name":      cfg.SchemaName,
 		"redis_namespace":  cfg.RedisNamespace,
+		"cache_namespace":  cfg.CacheNamespace,
 	}).Info("DataAdapter configuration resolved")
 
 	adapter := &CustodianDataAdapter{
-		config: cfg,
-		logger: logger,
+		config:          cfg,
+		logger:          logger,
+		connectPolicy:   connectPolicy,
+		legacySchema:    legacySchema,
+		legacyNamespace: legacyNamespace,
+		health:          newHealthTracker(),
+		metrics:         NewMetrics(cfg.ServiceInstanceName),
+		tracer:          newTracerHolder(),
+		settings:        newLiveSettings(cfg),
+	}
+	adapter.tracer.set(options.tracer)
+
+	if cfg.CircuitBreakerEnabled {
+		breakerConfig := CircuitBreakerConfig{
+			FailureRate:      cfg.CircuitBreakerFailureRate,
+			MinRequests:      cfg.CircuitBreakerMinRequests,
+			Window:           cfg.CircuitBreakerWindow,
+			OpenTimeout:      cfg.CircuitBreakerOpenTimeout,
+			HalfOpenRequests: cfg.CircuitBreakerHalfOpenRequests,
+		}
+		adapter.postgresBreaker = NewCircuitBreaker(ComponentPostgres, breakerConfig, logger)
+		adapter.redisBreaker = NewCircuitBreaker(ComponentRedis, breakerConfig, logger)
+		adapter.metrics.ObserveCircuitBreaker(ComponentPostgres, adapter.postgresBreaker)
+		adapter.metrics.ObserveCircuitBreaker(ComponentRedis, adapter.redisBreaker)
+	}
+
+	var repos Repositories
+	if cfg.Backend == BackendPostgres {
+		if err := adapter.initPostgres(options, &repos); err != nil {
+			return nil, err
+		}
+		if err := adapter.initRedis(options, &repos); err != nil {
+			return nil, err
+		}
+	} else {
+		if err := adapter.initBackend(cfg.Backend, &repos); err != nil {
+			return nil, err
+		}
 	}
 
-	// Initialize PostgreSQL
-	if cfg.PostgresURL != "" {
-		postgresDB, err := database.NewPostgresDB(cfg, logger)
+	// The local tier only fronts the built-in cache
+	repos.applyOverrides(options.overrides)
+	if options.overrides.Cache != nil {
+		adapter.tieredCache = nil
+	}
+
+	// Serve repository reads through the cache when both stores are available
+	if cfg.CacheRepositories && repos.Cache != nil && repos.Position != nil && repos.Balance != nil {
+		repos.Position = newCachedPositionRepository(repos.Position, repos.Cache, adapter.settings.cacheTTL, logger)
+		repos.Balance = newCachedBalanceRepository(repos.Balance, repos.Cache, adapter.settings.cacheTTL, logger)
+	}
+
+	// Symbols are normalized before anything else sees the write
+	if cfg.AssetChecksEnabled && repos.Asset != nil {
+		if repos.Position != nil {
+			repos.Position = NewAssetCheckedPositionRepository(repos.Position, repos.Asset)
+		}
+		if repos.Balance != nil {
+			repos.Balance = NewAssetCheckedBalanceRepository(repos.Balance, repos.Asset)
+		}
+		if repos.Settlement != nil {
+			repos.Settlement = NewAssetCheckedSettlementRepository(repos.Settlement, repos.Asset)
+		}
+	}
+
+	if cfg.InstanceClaimEnabled && cfg.Backend == BackendPostgres {
+		namespaces := []string{cfg.RedisNamespace}
+		if cfg.CacheNamespace != cfg.RedisNamespace {
+			namespaces = append(namespaces, cfg.CacheNamespace)
+		}
+		adapter.claims = newInstanceClaims(cfg.ServiceInstanceName, cfg.SchemaName, namespaces,
+			adapter.settings.serviceTTL, adapter.settings.heartbeatInterval, logger)
+		if adapter.postgresDB != nil {
+			adapter.claims.db = adapter.postgresDB.DB
+		}
+		if adapter.redisClient != nil {
+			adapter.claims.client = adapter.redisClient.Client
+		}
+	}
+
+	repos.applyDecorators(options)
+
+	adapter.positionRepo = repos.Position
+	adapter.settlementRepo = repos.Settlement
+	adapter.balanceRepo = repos.Balance
+	adapter.accountRepo = repos.Account
+	adapter.assetRepo = repos.Asset
+	adapter.serviceDiscoveryRepo = repos.ServiceDiscovery
+	adapter.cacheRepo = repos.Cache
+	adapter.lockRepo = repos.Lock
+
+	return adapter, nil
+}
+
+// initPostgres creates the PostgreSQL repositories from the pool supplied as
+// an option, or from a pool opened with PostgresURL
+func (a *CustodianDataAdapter) initPostgres(options *adapterOptions, repos *Repositories) error {
+	switch {
+	case options.db != nil:
+		a.postgresDB = database.NewPostgresDBFromDB(options.db, a.config, a.logger)
+	case a.config.PostgresURL != "":
+		postgresDB, err := database.NewPostgresDB(a.config, options.postgresCredentials, a.logger)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create PostgreSQL client: %w", err)
+			return fmt.Errorf("failed to create PostgreSQL client: %w", err)
 		}
-		adapter.postgresDB = postgresDB
+		a.postgresDB = postgresDB
+	default:
+		a.logger.Warn("PostgreSQL URL not configured, repositories will not be available")
+		return nil
+	}
 
-		// Initialize PostgreSQL repositories
-		adapter.positionRepo = NewPostgresPositionRepository(postgresDB.DB, logger)
-		adapter.settlementRepo = NewPostgresSettlementRepository(postgresDB.DB, logger)
-		adapter.balanceRepo = NewPostgresBalanceRepository(postgresDB.DB, logger)
-	} else {
-		logger.Warn("PostgreSQL URL not configured, repositories will not be available")
+	a.reads = newPrimaryReads(a.postgresDB.DB)
+	if replicaURLs := a.config.ReplicaURLs(); len(replicaURLs) > 0 {
+		replicas := map[string]*sql.DB{}
+		for _, replicaURL := range replicaURLs {
+			replicaConfig := *a.config
+			replicaConfig.PostgresURL = replicaURL
+			replicaDB, err := database.NewPostgresDB(&replicaConfig, options.postgresCredentials, a.logger)
+			if err != nil {
+				return fmt.Errorf("failed to create PostgreSQL replica client: %w", err)
+			}
+			a.postgresReplicas = append(a.postgresReplicas, replicaDB)
+			replicas[replicaName(replicaURL)] = replicaDB.DB
+		}
+		a.reads = newReadRouter(a.postgresDB.DB, replicas, a.config.PostgresReplicaMaxLag, a.config.PostgresReplicaCheckInterval, a.logger)
+	}
+
+	db := a.postgresDB.DB
+	interceptor := a.storeInterceptor(DBSystemPostgres, a.postgresBreaker, false)
+	positions := newPostgresPositionRepository(db, a.reads, a.config.SchemaName, a.logger)
+	settlements := newPostgresSettlementRepository(db, a.reads, a.config.SchemaName, a.logger)
+	if a.config.AccountChecksEnabled {
+		positions.accounts = qualifiedTable(a.config.SchemaName, "accounts")
+		settlements.accounts = positions.accounts
+	}
+	repos.Position = NewInterceptedPositionRepository(positions, interceptor)
+	repos.Settlement = NewInterceptedSettlementRepository(settlements, interceptor)
+	repos.Balance = NewInterceptedBalanceRepository(newPostgresBalanceRepository(db, a.reads, a.config.SchemaName, a.logger), interceptor)
+	repos.Account = NewInterceptedAccountRepository(newPostgresAccountRepository(db, a.reads, a.config.SchemaName, a.logger), interceptor)
+	repos.Asset = NewInterceptedAssetRepository(newPostgresAssetRepository(db, a.reads, a.config.SchemaName, a.logger), interceptor)
+	a.metrics.ObservePostgresPool(a.postgresDB.DB.Stats)
+	return nil
+}
+
+// initRedis creates the Redis repositories from the client supplied as an
+// option, or from a client created with RedisURL
+func (a *CustodianDataAdapter) initRedis(options *adapterOptions, repos *Repositories) error {
+	switch {
+	case options.redisClient != nil:
+		a.redisClient = cache.NewRedisClientFromClient(options.redisClient, a.config, a.logger)
+	case a.config.RedisURL != "":
+		redisClient, err := cache.NewRedisClient(a.config, options.redisCredentials, a.logger)
+		if err != nil {
+			return fmt.Errorf("failed to create Redis client: %w", err)
+		}
+		a.redisClient = redisClient
+	default:
+		a.logger.Warn("Redis URL not configured, cache and service discovery will not be available")
+		return nil
 	}
 
-	// Initialize Redis
-	if cfg.RedisURL != "" {
-		redisClient, err := cache.NewRedisClient(cfg, logger)
+	cfg := a.config
+	client := a.redisClient.Client
+
+	// Interceptors sit below the local tier, so metrics and spans cover calls
+	// that reach Redis and local hits are still served while the breaker is open
+	interceptor := a.storeInterceptor(DBSystemRedis, a.redisBreaker, false)
+	repos.ServiceDiscovery = NewInterceptedServiceDiscoveryRepository(NewRedisServiceDiscovery(client, cfg.ServiceDiscoveryNamespace, a.logger), interceptor)
+	repos.Cache = NewInterceptedCacheRepository(NewRedisCacheRepository(client, cfg.CacheNamespace, a.logger), a.storeInterceptor(DBSystemRedis, a.redisBreaker, cfg.CacheFailOpen))
+	repos.Lock = NewInterceptedLockRepository(NewRedisLockRepository(client, cfg.CacheNamespace, a.logger), interceptor)
+	a.metrics.ObserveRedisPool(client.PoolStats)
+
+	if cfg.LocalCacheMaxEntries > 0 {
+		tieredCache, err := NewTieredCacheRepository(repos.Cache, client, cfg.CacheNamespace, TieredCacheConfig{
+			MaxEntries: cfg.LocalCacheMaxEntries,
+			LocalTTL:   cfg.LocalCacheTTL,
+		}, a.logger)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create Redis client: %w", err)
+			return fmt.Errorf("failed to create local cache tier: %w", err)
 		}
-		adapter.redisClient = redisClient
+		a.tieredCache = tieredCache
+		repos.Cache = tieredCache
+	}
+	return nil
+}
 
-		// Initialize Redis repositories
-		adapter.serviceDiscoveryRepo = NewRedisServiceDiscovery(redisClient.Client, cfg.ServiceDiscoveryNamespace, logger)
-		adapter.cacheRepo = NewRedisCacheRepository(redisClient.Client, cfg.CacheNamespace, logger)
-	} else {
-		logger.Warn("Redis URL not configured, cache and service discovery will not be available")
+// initBackend creates the repositories of a registered third-party backend.
+// Its calls are traced and measured; failure handling is left to the backend.
+func (a *CustodianDataAdapter) initBackend(name string, repos *Repositories) error {
+	factory, err := lookupBackend(name)
+	if err != nil {
+		return err
 	}
 
-	return adapter, nil
+	backend, err := factory(newBackendConfig(a.config), a.logger)
+	if err != nil {
+		return fmt.Errorf("failed to create %s backend: %w", name, err)
+	}
+	a.backend = backend
+
+	*repos = backend.Repositories()
+	interceptor := a.storeInterceptor(name, nil, false)
+	if repos.Position != nil {
+		repos.Position = NewInterceptedPositionRepository(repos.Position, interceptor)
+	}
+	if repos.Settlement != nil {
+		repos.Settlement = NewInterceptedSettlementRepository(repos.Settlement, interceptor)
+	}
+	if repos.Balance != nil {
+		repos.Balance = NewInterceptedBalanceRepository(repos.Balance, interceptor)
+	}
+	if repos.Account != nil {
+		repos.Account = NewInterceptedAccountRepository(repos.Account, interceptor)
+	}
+	if repos.Asset != nil {
+		repos.Asset = NewInterceptedAssetRepository(repos.Asset, interceptor)
+	}
+	if repos.ServiceDiscovery != nil {
+		repos.ServiceDiscovery = NewInterceptedServiceDiscoveryRepository(repos.ServiceDiscovery, interceptor)
+	}
+	if repos.Cache != nil {
+		repos.Cache = NewInterceptedCacheRepository(repos.Cache, interceptor)
+	}
+	if repos.Lock != nil {
+		repos.Lock = NewInterceptedLockRepository(repos.Lock, interceptor)
+	}
+	return nil
 }
 
-func NewCustodianDataAdapterFromEnv(logger *logrus.Logger) (DataAdapter, error) {
+// replicaName identifies a replica in logs without its credentials
+func replicaName(replicaURL string) string {
+	parsed, err := url.Parse(replicaURL)
+	if err != nil {
+		return "replica"
+	}
+	return parsed.Host + parsed.Path
+}
+
+// storeInterceptor traces and records metrics for calls to a store and, if
+// the breaker is enabled, guards them with it
+func (a *CustodianDataAdapter) storeInterceptor(system string, breaker *CircuitBreaker, failOpenReads bool) CallInterceptor {
+	interceptors := []CallInterceptor{
+		a.tracer.interceptor(system, a.config.ServiceInstanceName),
+		a.metrics.Interceptor(),
+	}
+	if breaker != nil {
+		interceptors = append(interceptors, breaker.Interceptor(failOpenReads))
+	}
+	return ChainInterceptors(interceptors...)
+}
+
+// NewCustodianDataAdapterFromEnv creates an adapter from LoadConfig. With
+// CONFIG_FILE and CONFIG_WATCH_INTERVAL set, the file is watched as by
+// NewCustodianDataAdapterFromWatcher.
+func NewCustodianDataAdapterFromEnv(opts ...Option) (DataAdapter, error) {
 	cfg, err := config.LoadConfig()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
 
-	return NewCustodianDataAdapter(cfg, logger)
+	// LoadConfig has applied any .env, which may set the watch variables
+	path, interval, err := watchedConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	if path != "" {
+		watcher, err := NewConfigWatcher(path, interval)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load config: %w", err)
+		}
+		return NewCustodianDataAdapterFromWatcher(watcher, opts...)
+	}
+
+	return NewCustodianDataAdapter(cfg, opts...)
 }
 
+// Connect reaches every configured dependency according to the connect policy
 func (a *CustodianDataAdapter) Connect(ctx context.Context) error {
-	// Connect to PostgreSQL
-	if a.postgresDB != nil {
-		if err := a.postgresDB.Connect(ctx); err != nil {
-			a.logger.WithError(err).Warn("Failed to connect to PostgreSQL (stub mode)")
+	dependencies := a.dependencies()
+
+	switch a.connectPolicy {
+	case ConnectPolicyStrict:
+		for _, dep := range dependencies {
+			if err := a.connectDependency(ctx, dep); err != nil {
+				return fmt.Errorf("failed to connect to %s: %w", dep.name, err)
+			}
+		}
+
+	case ConnectPolicyRetry:
+		deadline := time.Now().Add(durationOrDefault(a.config.ConnectRetryDeadline, defaultConnectRetryDeadline))
+		for _, dep := range dependencies {
+			b := newBackoff(a.config.ConnectInitialBackoff, a.config.ConnectMaxBackoff)
+			err := retryWithBackoff(ctx, deadline, b, func(ctx context.Context) error {
+				err := a.connectDependency(ctx, dep)
+				if err != nil {
+					contextLogger(ctx, a.logger).WithError(err).WithField("dependency", dep.name).Warn("Failed to connect, retrying")
+				}
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("failed to connect to %s: %w", dep.name, err)
+			}
 		}
+
+	default:
+		pending := []dependency{}
+		for _, dep := range dependencies {
+			if err := a.connectDependency(ctx, dep); err != nil {
+				// Reconnecting cannot serve another instance's storage or move legacy data
+				if isPermanentConnectError(err) {
+					return fmt.Errorf("failed to connect to %s: %w", dep.name, err)
+				}
+				contextLogger(ctx, a.logger).WithError(err).WithField("dependency", dep.name).Warn("Failed to connect (stub mode), reconnecting in background")
+				pending = append(pending, dep)
+			}
+		}
+		a.startReconnector(pending)
+	}
+
+	// Replicas are optional; reads use the primary until one passes its lag check
+	if a.reads != nil {
+		a.reads.start(ctx)
+	}
+	if a.claims != nil {
+		a.claims.start()
+	}
+	if a.watcher != nil {
+		a.watcher.Start(context.Background())
+	}
+
+	contextLogger(ctx, a.logger).Info("Custodian data adapter connected")
+	return nil
+}
+
+func (a *CustodianDataAdapter) dependencies() []dependency {
+	dependencies := []dependency{}
+
+	if a.postgresDB != nil {
+		dependencies = append(dependencies, dependency{
+			name: componentDisplayName(ComponentPostgres),
+			connect: func(ctx context.Context) error {
+				if err := a.postgresDB.Connect(ctx); err != nil {
+					return err
+				}
+				if a.legacySchema != "" {
+					if err := checkLegacySchema(ctx, a.postgresDB.DB, a.config.SchemaName, a.legacySchema, a.legacyNamespace); err != nil {
+						return err
+					}
+				}
+				if a.config.SchemaAutoMigrate {
+					if err := a.migrateSchema(ctx); err != nil {
+						return err
+					}
+				}
+				if err := checkSchemaTables(ctx, a.postgresDB.DB, a.config.SchemaName, a.schemaRequirements()); err != nil {
+					return err
+				}
+				if a.claims != nil {
+					return a.claims.claimSchema(ctx)
+				}
+				return nil
+			},
+			connected: &a.postgresConnected,
+		})
 	}
 
-	// Connect to Redis
 	if a.redisClient != nil {
-		if err := a.redisClient.Connect(ctx); err != nil {
-			a.logger.WithError(err).Warn("Failed to connect to Redis (stub mode)")
-		}
+		dependencies = append(dependencies, dependency{
+			name: componentDisplayName(ComponentRedis),
+			connect: func(ctx context.Context) error {
+				if err := a.redisClient.Connect(ctx); err != nil {
+					return err
+				}
+				if a.claims != nil {
+					return a.claims.claimNamespace(ctx)
+				}
+				return nil
+			},
+			connected: &a.redisConnected,
+			onConnected: func(ctx context.Context) {
+				if a.tieredCache == nil {
+					return
+				}
+				if err := a.tieredCache.Start(ctx); err != nil {
+					a.logger.WithError(err).Warn("Failed to start cache invalidation listener")
+				}
+			},
+		})
+	}
+
+	if a.backend != nil {
+		dependencies = append(dependencies, dependency{
+			name:      a.config.Backend,
+			connect:   a.backend.Connect,
+			connected: &a.backendConnected,
+		})
 	}
 
-	a.logger.Info("Custodian data adapter connected")
+	return dependencies
+}
+
+func (a *CustodianDataAdapter) connectDependency(ctx context.Context, dep dependency) error {
+	if err := dep.connect(ctx); err != nil {
+		return err
+	}
+	dep.connected.Store(true)
+	if dep.onConnected != nil {
+		dep.onConnected(ctx)
+	}
 	return nil
 }
 
+// startReconnector keeps retrying unreachable dependencies until they connect
+// or the adapter disconnects. Readiness flips once each one comes back.
+func (a *CustodianDataAdapter) startReconnector(pending []dependency) {
+	if len(pending) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	a.stopReconnect = cancel
+
+	for _, dep := range pending {
+		a.reconnectWG.Add(1)
+		go func(dep dependency) {
+			defer a.reconnectWG.Done()
+
+			b := newBackoff(a.config.ConnectInitialBackoff, a.config.ConnectMaxBackoff)
+			err := retryWithBackoff(ctx, time.Time{}, b, func(ctx context.Context) error {
+				return a.connectDependency(ctx, dep)
+			})
+			if err == nil {
+				a.logger.WithField("dependency", dep.name).Info("Reconnected")
+			}
+		}(dep)
+	}
+}
+
+func durationOrDefault(d, fallback time.Duration) time.Duration {
+	if d <= 0 {
+		return fallback
+	}
+	return d
+}
+
 func (a *CustodianDataAdapter) Disconnect(ctx context.Context) error {
 	var errors []error
 
+	if a.watcher != nil {
+		a.watcher.Stop()
+	}
+
+	// Stop background reconnection before closing the clients it uses
+	if a.stopReconnect != nil {
+		a.stopReconnect()
+		a.reconnectWG.Wait()
+	}
+	if a.claims != nil {
+		a.claims.close()
+	}
+
 	// Disconnect from PostgreSQL
+	if a.reads != nil {
+		a.reads.close()
+	}
+	for _, replica := range a.postgresReplicas {
+		if err := replica.Disconnect(ctx); err != nil {
+			errors = append(errors, fmt.Errorf("PostgreSQL replica disconnect error: %w", err))
+		}
+	}
 	if a.postgresDB != nil {
 		if err := a.postgresDB.Disconnect(ctx); err != nil {
 			errors = append(errors, fmt.Errorf("PostgreSQL disconnect error: %w", err))
 		}
 	}
 
+	if a.backend != nil {
+		if err := a.backend.Disconnect(ctx); err != nil {
+			errors = append(errors, fmt.Errorf("%s backend disconnect error: %w", a.config.Backend, err))
+		}
+	}
+
 	// Disconnect from Redis
+	if a.tieredCache != nil {
+		if err := a.tieredCache.Stop(); err != nil {
+			errors = append(errors, fmt.Errorf("local cache tier stop error: %w", err))
+		}
+	}
 	if a.redisClient != nil {
 		if err := a.redisClient.Disconnect(ctx); err != nil {
 			errors = append(errors, fmt.Errorf("Redis disconnect error: %w", err))
 		}
 	}
 
+	a.disconnected.Store(true)
+
 	if len(errors) > 0 {
 		return fmt.Errorf("disconnect errors: %v", errors)
 	}
@@ -160,21 +729,137 @@ func (a *CustodianDataAdapter) Disconnect(ctx context.Context) error {
 }
 
 func (a *CustodianDataAdapter) HealthCheck(ctx context.Context) error {
-	// Check PostgreSQL health
-	if a.postgresDB != nil {
-		if err := a.postgresDB.HealthCheck(ctx); err != nil {
-			return fmt.Errorf("PostgreSQL health check failed: %w", err)
+	report := a.HealthReport(ctx)
+
+	for _, component := range report.Components {
+		if component.Status == ComponentStatusDown {
+			return fmt.Errorf("%s health check failed: %s", componentDisplayName(component.Name), component.Error)
 		}
 	}
 
-	// Check Redis health
+	return nil
+}
+
+// HealthReport checks every configured dependency in parallel, each bounded
+// by HealthCheckTimeout
+func (a *CustodianDataAdapter) HealthReport(ctx context.Context) *HealthReport {
+	checks := []componentCheck{}
+
+	if a.postgresDB != nil {
+		checks = append(checks, componentCheck{
+			name:    ComponentPostgres,
+			ping:    func(ctx context.Context) error { return a.postgresDB.DB.PingContext(ctx) },
+			circuit: a.postgresBreaker,
+			stats: func() *PoolStats {
+				stats := a.postgresDB.DB.Stats()
+				return &PoolStats{
+					MaxOpen:      stats.MaxOpenConnections,
+					Open:         stats.OpenConnections,
+					InUse:        stats.InUse,
+					Idle:         stats.Idle,
+					WaitCount:    stats.WaitCount,
+					WaitDuration: stats.WaitDuration,
+				}
+			},
+		})
+	}
+
 	if a.redisClient != nil {
-		if err := a.redisClient.HealthCheck(ctx); err != nil {
-			return fmt.Errorf("Redis health check failed: %w", err)
+		checks = append(checks, componentCheck{
+			name:    ComponentRedis,
+			ping:    func(ctx context.Context) error { return a.redisClient.Client.Ping(ctx).Err() },
+			circuit: a.redisBreaker,
+			stats: func() *PoolStats {
+				stats := a.redisClient.Client.PoolStats()
+				return &PoolStats{
+					MaxOpen:  a.config.RedisPoolSize,
+					Open:     int(stats.TotalConns),
+					InUse:    int(stats.TotalConns) - int(stats.IdleConns),
+					Idle:     int(stats.IdleConns),
+					Timeouts: int64(stats.Timeouts),
+				}
+			},
+		})
+	}
+
+	if a.backend != nil {
+		checks = append(checks, componentCheck{
+			name: a.config.Backend,
+			ping: a.backend.HealthCheck,
+		})
+	}
+
+	return runHealthChecks(ctx, checks, a.config.HealthCheckTimeout, a.config.HealthDegradedLatency, a.health)
+}
+
+// Liveness reports whether the adapter itself is usable. It does not depend on
+// Postgres or Redis being reachable, so an orchestrator will not restart the
+// process for a dependency outage.
+func (a *CustodianDataAdapter) Liveness(ctx context.Context) *ProbeResult {
+	if a.disconnected.Load() {
+		return &ProbeResult{Healthy: false, Reasons: []string{"adapter disconnected"}}
+	}
+	return &ProbeResult{Healthy: true}
+}
+
+// Readiness reports whether the repositories can serve requests: every
+// configured dependency must be reachable and the Postgres-backed
+// repositories must be configured. Degraded components do not fail readiness.
+func (a *CustodianDataAdapter) Readiness(ctx context.Context) *ProbeResult {
+	result := a.Liveness(ctx)
+	if !result.Healthy {
+		return result
+	}
+
+	report := a.HealthReport(ctx)
+	result.Report = report
+
+	if a.positionRepo == nil {
+		if a.backend != nil {
+			result.Reasons = append(result.Reasons, fmt.Sprintf("%s backend provides no position repository", a.config.Backend))
+		} else {
+			result.Reasons = append(result.Reasons, "PostgreSQL not configured, repositories unavailable")
+		}
+	}
+	if a.postgresDB != nil && !a.postgresConnected.Load() {
+		result.Reasons = append(result.Reasons, "PostgreSQL not connected")
+	}
+	if a.redisClient != nil && !a.redisConnected.Load() {
+		result.Reasons = append(result.Reasons, "Redis not connected")
+	}
+	if a.backend != nil && !a.backendConnected.Load() {
+		result.Reasons = append(result.Reasons, fmt.Sprintf("%s backend not connected", a.config.Backend))
+	}
+	for _, component := range report.Components {
+		if component.Status == ComponentStatusDown {
+			result.Reasons = append(result.Reasons, fmt.Sprintf("%s down: %s", componentDisplayName(component.Name), component.Error))
 		}
 	}
 
-	return nil
+	result.Healthy = len(result.Reasons) == 0
+	return result
+}
+
+func componentDisplayName(name string) string {
+	switch name {
+	case ComponentPostgres:
+		return "PostgreSQL"
+	case ComponentRedis:
+		return "Redis"
+	}
+	return name
+}
+
+// MetricsHandler serves repository call and connection pool metrics in the
+// Prometheus text exposition format
+func (a *CustodianDataAdapter) MetricsHandler() http.Handler {
+	return a.metrics
+}
+
+// SetTracer installs the tracer invoked around every repository call; nil
+// disables tracing. It may be called after the repositories are in use.
+func (a *CustodianDataAdapter) SetTracer(tracer interfaces.Tracer) {
+	a.tracer.set(tracer)
 }
 
 // Repository access methods
@@ -190,6 +875,14 @@ func (a *CustodianDataAdapter) BalanceRepository() interfaces.BalanceRepository
 	return a.balanceRepo
 }
 
+func (a *CustodianDataAdapter) AccountRepository() interfaces.AccountRepository {
+	return a.accountRepo
+}
+
+func (a *CustodianDataAdapter) AssetRepository() interfaces.AssetRepository {
+	return a.assetRepo
+}
+
 func (a *CustodianDataAdapter) ServiceDiscoveryRepository() interfaces.ServiceDiscoveryRepository {
 	return a.serviceDiscoveryRepo
 }
@@ -198,9 +891,47 @@ func (a *CustodianDataAdapter) CacheRepository() interfaces.CacheRepository {
 	return a.cacheRepo
 }
 
+func (a *CustodianDataAdapter) LockRepository() interfaces.LockRepository {
+	return a.lockRepo
+}
+
+// NewLeaderElector creates a leader election candidate scoped to this
+// instance's Redis namespace, so each custodian instance elects independently
+func (a *CustodianDataAdapter) NewLeaderElector(election string, cfg LeaderElectionConfig) (interfaces.LeaderElector, error) {
+	if a.redisClient == nil {
+		return nil, fmt.Errorf("leader election requires Redis")
+	}
+	return NewRedisLeaderElection(a.redisClient.Client, a.config.RedisNamespace, election, cfg, a.logger)
+}
+
+// maxIdentifierLength is PostgreSQL's identifier limit; longer names are truncated
+const maxIdentifierLength = 63
+
+// checkDerivableInstanceName rejects multi-instance names whose derived schema
+// or namespace could equal another instance's: derivation maps hyphens to
+// underscores and colons, so those characters may not appear themselves, and
+// PostgreSQL would truncate a long schema name. Names differing only in case
+// share a schema; the ownership claim on connect (INSTANCE_CLAIM_ENABLED)
+// catches those.
+func checkDerivableInstanceName(serviceName, instanceName string) error {
+	if serviceName == instanceName {
+		return nil
+	}
+	for _, r := range instanceName {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-') {
+			return fmt.Errorf("instance name %q is ambiguous: only letters, digits and hyphens can derive a unique schema (set SCHEMA_NAME and REDIS_NAMESPACE explicitly)", instanceName)
+		}
+	}
+	if schema := deriveSchemaName(serviceName, instanceName); len(schema) > maxIdentifierLength {
+		return fmt.Errorf("schema name %q derived from instance %q exceeds %d characters", schema, instanceName, maxIdentifierLength)
+	}
+	return nil
+}
+
 // deriveSchemaName determines PostgreSQL schema based on service instance pattern
 // Singleton: custodian-simulator == custodian-simulator → "custodian"
 // Multi-instance: custodian-Komainu → "custodian_komainu"
+// Every part is kept, so custodian-Komainu-EU → "custodian_komainu_eu"
 func deriveSchemaName(serviceName, instanceName string) string {
 	if serviceName == instanceName {
 		// Singleton service pattern
@@ -214,16 +945,13 @@ func deriveSchemaName(serviceName, instanceName string) string {
 
 	// Multi-instance service pattern
 	// Example: "custodian-Komainu" -> "custodian_komainu"
-	parts := strings.Split(instanceName, "-")
-	if len(parts) >= 2 {
-		return strings.ToLower(parts[0] + "_" + parts[1])
-	}
-	return strings.ToLower(instanceName)
+	return strings.ToLower(strings.ReplaceAll(instanceName, "-", "_"))
 }
 
 // deriveRedisNamespace determines Redis key prefix based on service instance pattern
 // Singleton: custodian-simulator == custodian-simulator → "custodian"
 // Multi-instance: custodian-Komainu → "custodian:Komainu"
+// Every part is kept, so custodian-Komainu-EU → "custodian:Komainu:EU"
 func deriveRedisNamespace(serviceName, instanceName string) string {
 	if serviceName == instanceName {
 		// Singleton service pattern
@@ -237,9 +965,5 @@ func deriveRedisNamespace(serviceName, instanceName string) string {
 
 	// Multi-instance service pattern
 	// Example: "custodian-Komainu" -> "custodian:Komainu"
-	parts := strings.Split(instanceName, "-")
-	if len(parts) >= 2 {
-		return parts[0] + ":" + parts[1]
-	}
-	return instanceName
+	return strings.ReplaceAll(instanceName, "-", ":")
 }
@@ -1,7 +1,8 @@
 package adapters
 
 import (
-	"io"
+	"bytes"
+	"strings"
 	"testing"
 
 	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/internal/config"
@@ -61,7 +62,7 @@ func TestDeriveSchemaName(t *testing.T) {
 			name:         "edge case: three part instance",
 			serviceName:  "custodian-simulator",
 			instanceName: "custodian-Komainu-Primary",
-			expected:     "custodian_komainu",
+			expected:     "custodian_komainu_primary",
 		},
 		{
 			name:         "edge case: uppercase service",
@@ -135,7 +136,7 @@ func TestDeriveRedisNamespace(t *testing.T) {
 			name:         "edge case: three part instance",
 			serviceName:  "custodian-simulator",
 			instanceName: "custodian-Komainu-Primary",
-			expected:     "custodian:Komainu",
+			expected:     "custodian:Komainu:Primary",
 		},
 		{
 			name:         "edge case: uppercase service",
@@ -156,6 +157,38 @@ func TestDeriveRedisNamespace(t *testing.T) {
 	}
 }
 
+// TestCheckDerivableInstanceName tests that names deriving ambiguous storage are rejected
+func TestCheckDerivableInstanceName(t *testing.T) {
+	tests := []struct {
+		name         string
+		serviceName  string
+		instanceName string
+		valid        bool
+	}{
+		{"regional instances", "custodian-simulator", "custodian-Komainu-EU", true},
+		{"singleton", "custodian_simulator", "custodian_simulator", true},
+		{"underscore", "custodian-simulator", "custodian-Komainu_EU", false},
+		{"colon", "custodian-simulator", "custodian-Komainu:EU", false},
+		{"too long", "custodian-simulator", "custodian-" + strings.Repeat("x", 60), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkDerivableInstanceName(tt.serviceName, tt.instanceName)
+			if tt.valid && err != nil {
+				t.Errorf("checkDerivableInstanceName(%s) failed: %v", tt.instanceName, err)
+			}
+			if !tt.valid && err == nil {
+				t.Errorf("checkDerivableInstanceName(%s) succeeded, expected an error", tt.instanceName)
+			}
+		})
+	}
+
+	if deriveSchemaName("custodian-simulator", "custodian-Komainu-EU") == deriveSchemaName("custodian-simulator", "custodian-Komainu-US") {
+		t.Error("regional instances derive the same schema")
+	}
+}
+
 // TestNewCustodianDataAdapter tests the factory with derivation
 func TestNewCustodianDataAdapter(t *testing.T) {
 	tests := []struct {
@@ -163,6 +196,9 @@ func TestNewCustodianDataAdapter(t *testing.T) {
 		config            *config.Config
 		expectedSchema    string
 		expectedNamespace string
+		expectedCache     string
+		expectedWarning   string
+		errorMsg          string
 	}{
 		{
 			name: "uses derived schema when not provided",
@@ -174,6 +210,7 @@ func TestNewCustodianDataAdapter(t *testing.T) {
 			},
 			expectedSchema:    "custodian_komainu",
 			expectedNamespace: "custodian:Komainu",
+			expectedCache:     "custodian:Komainu",
 		},
 		{
 			name: "uses derived namespace when not provided",
@@ -185,6 +222,7 @@ func TestNewCustodianDataAdapter(t *testing.T) {
 			},
 			expectedSchema:    "custodian_fireblocks",
 			expectedNamespace: "custodian:Fireblocks",
+			expectedCache:     "custodian:Fireblocks",
 		},
 		{
 			name: "uses provided values when both specified",
@@ -196,16 +234,47 @@ func TestNewCustodianDataAdapter(t *testing.T) {
 			},
 			expectedSchema:    "explicit_schema",
 			expectedNamespace: "explicit:namespace",
+			expectedCache:     "explicit:namespace",
+		},
+		{
+			name: "singleton keeps the shared cache namespace",
+			config: &config.Config{
+				ServiceName:         "custodian-simulator",
+				ServiceInstanceName: "custodian-simulator",
+				CacheNamespace:      "custodian",
+			},
+			expectedSchema:    "custodian",
+			expectedNamespace: "custodian",
+			expectedCache:     "custodian",
+		},
+		{
+			name: "warns about shared cache namespace for instance schema",
+			config: &config.Config{
+				ServiceName:         "custodian-simulator",
+				ServiceInstanceName: "custodian-Komainu",
+				CacheNamespace:      "custodian",
+			},
+			expectedSchema:    "custodian_komainu",
+			expectedNamespace: "custodian:Komainu",
+			expectedCache:     "custodian",
+			expectedWarning:   "Cache namespace is shared by the singleton instance",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
 			logger := logrus.New()
-			logger.SetOutput(io.Discard) // Suppress log output in tests
+			logger.SetOutput(&logs)
 
-			adapter, err := NewCustodianDataAdapter(tt.config, logger)
+			adapter, err := NewCustodianDataAdapter(tt.config, WithLogger(NewLogrusLogger(logger)))
 
+			if tt.errorMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+					t.Fatalf("NewCustodianDataAdapter error = %v, expected %q", err, tt.errorMsg)
+				}
+				return
+			}
 			if err != nil {
 				t.Fatalf("NewCustodianDataAdapter failed: %v", err)
 			}
@@ -222,6 +291,16 @@ func TestNewCustodianDataAdapter(t *testing.T) {
 					tt.config.RedisNamespace, tt.expectedNamespace)
 			}
 
+			// Verify cache namespace follows the instance
+			if tt.config.CacheNamespace != tt.expectedCache {
+				t.Errorf("Cache namespace = %s, expected %s",
+					tt.config.CacheNamespace, tt.expectedCache)
+			}
+
+			if tt.expectedWarning != "" && !strings.Contains(logs.String(), tt.expectedWarning) {
+				t.Errorf("expected warning %q, logged %q", tt.expectedWarning, logs.String())
+			}
+
 			_ = adapter // Suppress unused variable warning
 		})
 	}
@@ -0,0 +1,207 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ComponentStatus is the health of one dependency or of the adapter overall
+type ComponentStatus string
+
+const (
+	ComponentStatusUp       ComponentStatus = "up"
+	ComponentStatusDegraded ComponentStatus = "degraded"
+	ComponentStatusDown     ComponentStatus = "down"
+)
+
+// defaultHealthCheckTimeout applies when no per-component timeout is configured
+const defaultHealthCheckTimeout = 5 * time.Second
+
+// Health component names
+const (
+	ComponentPostgres = "postgres"
+	ComponentRedis    = "redis"
+)
+
+// PoolStats is a snapshot of a connection pool
+type PoolStats struct {
+	MaxOpen      int           `json:"max_open"`
+	Open         int           `json:"open"`
+	InUse        int           `json:"in_use"`
+	Idle         int           `json:"idle"`
+	WaitCount    int64         `json:"wait_count"`
+	WaitDuration time.Duration `json:"wait_duration"`
+	Timeouts     int64         `json:"timeouts"`
+}
+
+// ComponentHealth is the result of checking one dependency
+type ComponentHealth struct {
+	Name        string           `json:"name"`
+	Status      ComponentStatus  `json:"status"`
+	Latency     time.Duration    `json:"latency"`
+	Pool        *PoolStats       `json:"pool,omitempty"`
+	Circuit     *CircuitSnapshot `json:"circuit,omitempty"`
+	Error       string           `json:"error,omitempty"`
+	LastError   string           `json:"last_error,omitempty"`
+	LastErrorAt *time.Time       `json:"last_error_at,omitempty"`
+	CheckedAt   time.Time        `json:"checked_at"`
+}
+
+// HealthReport aggregates component checks. Status is the worst component status.
+type HealthReport struct {
+	Status     ComponentStatus   `json:"status"`
+	Components []ComponentHealth `json:"components"`
+	CheckedAt  time.Time         `json:"checked_at"`
+}
+
+// Component returns the named component, or nil if it is not configured
+func (r *HealthReport) Component(name string) *ComponentHealth {
+	for i := range r.Components {
+		if r.Components[i].Name == name {
+			return &r.Components[i]
+		}
+	}
+	return nil
+}
+
+// ProbeResult answers a liveness or readiness probe
+type ProbeResult struct {
+	Healthy bool          `json:"healthy"`
+	Reasons []string      `json:"reasons,omitempty"`
+	Report  *HealthReport `json:"report,omitempty"`
+}
+
+// Err summarizes an unhealthy probe, or returns nil
+func (p *ProbeResult) Err() error {
+	if p.Healthy {
+		return nil
+	}
+	return fmt.Errorf("%s", strings.Join(p.Reasons, "; "))
+}
+
+// componentCheck probes one dependency
+type componentCheck struct {
+	name    string
+	ping    func(ctx context.Context) error
+	stats   func() *PoolStats
+	circuit *CircuitBreaker
+}
+
+// healthTracker remembers the last error seen per component across checks
+type healthTracker struct {
+	mu         sync.Mutex
+	lastErrors map[string]lastComponentError
+}
+
+type lastComponentError struct {
+	message string
+	at      time.Time
+}
+
+func newHealthTracker() *healthTracker {
+	return &healthTracker{lastErrors: make(map[string]lastComponentError)}
+}
+
+func (t *healthTracker) record(name string, err error, at time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.lastErrors[name] = lastComponentError{message: err.Error(), at: at}
+}
+
+func (t *healthTracker) last(name string) (lastComponentError, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	last, ok := t.lastErrors[name]
+	return last, ok
+}
+
+// runHealthChecks runs all checks in parallel, each bounded by timeout.
+// A check slower than degradedLatency (if set), whose pool is saturated or
+// whose circuit breaker is not closed is degraded.
+func runHealthChecks(ctx context.Context, checks []componentCheck, timeout, degradedLatency time.Duration, tracker *healthTracker) *HealthReport {
+	if timeout <= 0 {
+		timeout = defaultHealthCheckTimeout
+	}
+
+	report := &HealthReport{
+		Status:     ComponentStatusUp,
+		Components: make([]ComponentHealth, len(checks)),
+		CheckedAt:  time.Now(),
+	}
+
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check componentCheck) {
+			defer wg.Done()
+			report.Components[i] = runHealthCheck(ctx, check, timeout, degradedLatency, tracker)
+		}(i, check)
+	}
+	wg.Wait()
+
+	sort.Slice(report.Components, func(i, j int) bool {
+		return report.Components[i].Name < report.Components[j].Name
+	})
+	for _, component := range report.Components {
+		report.Status = worseStatus(report.Status, component.Status)
+	}
+
+	return report
+}
+
+func runHealthCheck(ctx context.Context, check componentCheck, timeout, degradedLatency time.Duration, tracker *healthTracker) ComponentHealth {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check.ping(ctx)
+	health := ComponentHealth{
+		Name:      check.name,
+		Status:    ComponentStatusUp,
+		Latency:   time.Since(start),
+		CheckedAt: start,
+	}
+	if check.stats != nil {
+		health.Pool = check.stats()
+	}
+	if check.circuit != nil {
+		health.Circuit = check.circuit.Snapshot()
+	}
+
+	switch {
+	case err != nil:
+		health.Status = ComponentStatusDown
+		health.Error = err.Error()
+		tracker.record(check.name, err, start)
+	case degradedLatency > 0 && health.Latency > degradedLatency:
+		health.Status = ComponentStatusDegraded
+	case health.Pool != nil && health.Pool.MaxOpen > 0 && health.Pool.InUse >= health.Pool.MaxOpen:
+		health.Status = ComponentStatusDegraded
+	case health.Circuit != nil && health.Circuit.State != CircuitClosed:
+		health.Status = ComponentStatusDegraded
+	}
+
+	if last, ok := tracker.last(check.name); ok {
+		at := last.at
+		health.LastError = last.message
+		health.LastErrorAt = &at
+	}
+
+	return health
+}
+
+func worseStatus(a, b ComponentStatus) ComponentStatus {
+	rank := map[ComponentStatus]int{
+		ComponentStatusUp:       0,
+		ComponentStatusDegraded: 1,
+		ComponentStatusDown:     2,
+	}
+	if rank[b] > rank[a] {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,79 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestRunHealthChecks tests per-component status, aggregation and last-error tracking
+func TestRunHealthChecks(t *testing.T) {
+	tracker := newHealthTracker()
+	redisErr := errors.New("connection refused")
+
+	checks := []componentCheck{
+		{
+			name: ComponentRedis,
+			ping: func(ctx context.Context) error { return redisErr },
+		},
+		{
+			name: ComponentPostgres,
+			ping: func(ctx context.Context) error { return nil },
+			stats: func() *PoolStats {
+				return &PoolStats{MaxOpen: 10, Open: 10, InUse: 10}
+			},
+		},
+	}
+
+	report := runHealthChecks(context.Background(), checks, time.Second, time.Second, tracker)
+
+	if report.Status != ComponentStatusDown {
+		t.Errorf("Status = %s, expected %s", report.Status, ComponentStatusDown)
+	}
+	if report.Components[0].Name != ComponentPostgres {
+		t.Errorf("components not sorted by name: %s first", report.Components[0].Name)
+	}
+
+	postgres := report.Component(ComponentPostgres)
+	if postgres.Status != ComponentStatusDegraded {
+		t.Errorf("saturated pool status = %s, expected %s", postgres.Status, ComponentStatusDegraded)
+	}
+
+	redis := report.Component(ComponentRedis)
+	if redis.Status != ComponentStatusDown || redis.Error != redisErr.Error() {
+		t.Errorf("redis = %+v, expected down with error", redis)
+	}
+
+	// The last error survives a subsequent successful check
+	checks[0].ping = func(ctx context.Context) error { return nil }
+	report = runHealthChecks(context.Background(), checks, time.Second, time.Second, tracker)
+	redis = report.Component(ComponentRedis)
+	if redis.Status != ComponentStatusUp {
+		t.Errorf("recovered redis status = %s, expected %s", redis.Status, ComponentStatusUp)
+	}
+	if redis.LastError != redisErr.Error() || redis.LastErrorAt == nil {
+		t.Errorf("LastError = %q, expected %q", redis.LastError, redisErr.Error())
+	}
+}
+
+// TestRunHealthChecksTimeout tests that a hung check is bounded by its timeout
+func TestRunHealthChecksTimeout(t *testing.T) {
+	checks := []componentCheck{{
+		name: ComponentPostgres,
+		ping: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	}}
+
+	start := time.Now()
+	report := runHealthChecks(context.Background(), checks, 50*time.Millisecond, 0, newHealthTracker())
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("health checks took %s, expected timeout to bound them", elapsed)
+	}
+	if report.Status != ComponentStatusDown {
+		t.Errorf("Status = %s, expected %s", report.Status, ComponentStatusDown)
+	}
+}
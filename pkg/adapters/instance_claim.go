@@ -0,0 +1,178 @@
+package adapters
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/internal/database"
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/interfaces"
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrInstanceConflict is returned by Connect when another live instance owns
+// this instance's schema or one of its Redis namespaces
+var ErrInstanceConflict = errors.New("storage owned by another instance")
+
+// claimNamespaceScript sets the owner key unless another instance holds it,
+// returning the owner it found ("" when the claim succeeded)
+var claimNamespaceScript = redis.NewScript(`
+local owner = redis.call("GET", KEYS[1])
+if owner and owner ~= ARGV[1] then
+	return owner
+end
+redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+return ""
+`)
+
+// instanceClaims registers the instance as owner of its schema and Redis
+// namespaces (its own and, if separate, its cache's) on connect and refreshes
+// them every interval. Replicas of one instance share the claims; a claim
+// lapses ttl after its last refresh, so a stopped instance's storage can be
+// taken over once the ttl has passed.
+type instanceClaims struct {
+	instance   string
+	schema     string
+	namespaces []string
+	db         *sql.DB
+	client     redis.UniversalClient
+
+	ttl      *durationSetting
+	interval *durationSetting
+	logger   interfaces.Logger
+
+	mu            sync.Mutex
+	schemaClaimed bool
+	redisClaimed  bool
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func newInstanceClaims(instance, schema string, namespaces []string, ttl, interval *durationSetting, logger interfaces.Logger) *instanceClaims {
+	return &instanceClaims{
+		instance:   instance,
+		schema:     schema,
+		namespaces: namespaces,
+		ttl:        ttl,
+		interval:   interval,
+		logger:     logger,
+	}
+}
+
+func (c *instanceClaims) ownerKey(namespace string) string {
+	return namespacePrefix(c.client, namespace) + "instance-owner"
+}
+
+// claimSchema claims the schema, or fails with ErrInstanceConflict. Without
+// the privileges to keep a registry the check is skipped with a warning.
+func (c *instanceClaims) claimSchema(ctx context.Context) error {
+	owner, err := database.ClaimSchema(ctx, c.db, c.schema, c.instance, c.ttl.Load())
+	if errors.Is(err, database.ErrClaimUnavailable) {
+		contextLogger(ctx, c.logger).WithError(err).WithField("schema_name", c.schema).Warn("Cannot check schema ownership, continuing without a claim")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if owner != c.instance {
+		return c.conflict(ctx, "schema", c.schema, owner)
+	}
+
+	c.mu.Lock()
+	c.schemaClaimed = true
+	c.mu.Unlock()
+	return nil
+}
+
+// claimNamespace claims the Redis namespaces, or fails with ErrInstanceConflict
+func (c *instanceClaims) claimNamespace(ctx context.Context) error {
+	for _, namespace := range c.namespaces {
+		owner, err := claimNamespaceScript.Run(ctx, c.client, []string{c.ownerKey(namespace)}, c.instance, c.ttl.Load().Milliseconds()).Text()
+		if err != nil {
+			return fmt.Errorf("failed to claim Redis namespace %s: %w", namespace, err)
+		}
+		if owner != "" {
+			return c.conflict(ctx, "Redis namespace", namespace, owner)
+		}
+	}
+
+	c.mu.Lock()
+	c.redisClaimed = true
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *instanceClaims) conflict(ctx context.Context, kind, name, owner string) error {
+	contextLogger(ctx, c.logger).WithFields(interfaces.LogFields{
+		"instance_name": c.instance,
+		"owner":         owner,
+	}).Error(fmt.Sprintf("%s %s is owned by another instance", kind, name))
+	return fmt.Errorf("%w: %s %s is owned by instance %s", ErrInstanceConflict, kind, name, owner)
+}
+
+// start refreshes the claims made so far until close
+func (c *instanceClaims) start() {
+	if c.stop != nil {
+		return
+	}
+	c.stop = make(chan struct{})
+	c.wg.Add(1)
+
+	go func() {
+		defer c.wg.Done()
+
+		interval := c.interval.Load()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-c.stop:
+				return
+			case <-ticker.C:
+				c.refresh(context.Background())
+
+				// Pick up a reloaded heartbeat interval
+				if next := c.interval.Load(); next != interval {
+					interval = next
+					ticker.Reset(interval)
+				}
+			}
+		}
+	}()
+}
+
+// refresh renews each claim held; losing one is logged, as the instance is
+// already serving
+func (c *instanceClaims) refresh(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, c.interval.Load())
+	defer cancel()
+
+	c.mu.Lock()
+	schemaClaimed, redisClaimed := c.schemaClaimed, c.redisClaimed
+	c.mu.Unlock()
+
+	if schemaClaimed {
+		if err := c.claimSchema(ctx); err != nil && !errors.Is(err, ErrInstanceConflict) {
+			c.logger.WithError(err).Warn("Failed to refresh schema claim")
+		}
+	}
+	if redisClaimed {
+		if err := c.claimNamespace(ctx); err != nil && !errors.Is(err, ErrInstanceConflict) {
+			c.logger.WithError(err).Warn("Failed to refresh Redis namespace claim")
+		}
+	}
+}
+
+func (c *instanceClaims) close() {
+	if c.stop == nil {
+		return
+	}
+	close(c.stop)
+	c.wg.Wait()
+	c.stop = nil
+}
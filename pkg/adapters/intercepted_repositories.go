@@ -0,0 +1,651 @@
+package adapters
+
+import (
+	"context"
+	"time"
+
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/interfaces"
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/models"
+)
+
+// The decorators below route every repository method through a
+// CallInterceptor. HealthCheck methods bypass the interceptor so probes keep
+// reaching the store while, for example, a circuit breaker is open.
+
+type interceptedPositionRepository struct {
+	interceptedRepository
+	inner interfaces.PositionRepository
+}
+
+// NewInterceptedPositionRepository routes every call of inner through interceptor
+func NewInterceptedPositionRepository(inner interfaces.PositionRepository, interceptor CallInterceptor) interfaces.PositionRepository {
+	return &interceptedPositionRepository{
+		interceptedRepository: interceptedRepository{repository: RepositoryPosition, interceptor: interceptor},
+		inner:                 inner,
+	}
+}
+
+func (r *interceptedPositionRepository) Create(ctx context.Context, position *models.Position) error {
+	return r.write(ctx, "Create", func(ctx context.Context) error {
+		return r.inner.Create(ctx, position)
+	})
+}
+
+func (r *interceptedPositionRepository) GetByID(ctx context.Context, positionID string) (position *models.Position, err error) {
+	err = r.read(ctx, "GetByID", func(ctx context.Context) error {
+		position, err = r.inner.GetByID(ctx, positionID)
+		return err
+	})
+	return position, err
+}
+
+func (r *interceptedPositionRepository) GetByAccountAndSymbol(ctx context.Context, accountID, symbol string) (position *models.Position, err error) {
+	err = r.read(ctx, "GetByAccountAndSymbol", func(ctx context.Context) error {
+		position, err = r.inner.GetByAccountAndSymbol(ctx, accountID, symbol)
+		return err
+	})
+	return position, err
+}
+
+func (r *interceptedPositionRepository) Query(ctx context.Context, query *models.PositionQuery) (positions []*models.Position, err error) {
+	err = r.read(ctx, "Query", func(ctx context.Context) error {
+		positions, err = r.inner.Query(ctx, query)
+		return err
+	})
+	return positions, err
+}
+
+func (r *interceptedPositionRepository) Update(ctx context.Context, position *models.Position) error {
+	return r.write(ctx, "Update", func(ctx context.Context) error {
+		return r.inner.Update(ctx, position)
+	})
+}
+
+func (r *interceptedPositionRepository) UpdateAvailableQuantity(ctx context.Context, positionID string, availableQty, lockedQty float64) error {
+	return r.write(ctx, "UpdateAvailableQuantity", func(ctx context.Context) error {
+		return r.inner.UpdateAvailableQuantity(ctx, positionID, availableQty, lockedQty)
+	})
+}
+
+func (r *interceptedPositionRepository) Delete(ctx context.Context, positionID string) error {
+	return r.write(ctx, "Delete", func(ctx context.Context) error {
+		return r.inner.Delete(ctx, positionID)
+	})
+}
+
+func (r *interceptedPositionRepository) GetByAccount(ctx context.Context, accountID string) (positions []*models.Position, err error) {
+	err = r.read(ctx, "GetByAccount", func(ctx context.Context) error {
+		positions, err = r.inner.GetByAccount(ctx, accountID)
+		return err
+	})
+	return positions, err
+}
+
+type interceptedSettlementRepository struct {
+	interceptedRepository
+	inner interfaces.SettlementRepository
+}
+
+// NewInterceptedSettlementRepository routes every call of inner through interceptor
+func NewInterceptedSettlementRepository(inner interfaces.SettlementRepository, interceptor CallInterceptor) interfaces.SettlementRepository {
+	return &interceptedSettlementRepository{
+		interceptedRepository: interceptedRepository{repository: RepositorySettlement, interceptor: interceptor},
+		inner:                 inner,
+	}
+}
+
+func (r *interceptedSettlementRepository) Create(ctx context.Context, settlement *models.Settlement) error {
+	return r.write(ctx, "Create", func(ctx context.Context) error {
+		return r.inner.Create(ctx, settlement)
+	})
+}
+
+func (r *interceptedSettlementRepository) GetByID(ctx context.Context, settlementID string) (settlement *models.Settlement, err error) {
+	err = r.read(ctx, "GetByID", func(ctx context.Context) error {
+		settlement, err = r.inner.GetByID(ctx, settlementID)
+		return err
+	})
+	return settlement, err
+}
+
+func (r *interceptedSettlementRepository) GetByExternalID(ctx context.Context, externalID string) (settlement *models.Settlement, err error) {
+	err = r.read(ctx, "GetByExternalID", func(ctx context.Context) error {
+		settlement, err = r.inner.GetByExternalID(ctx, externalID)
+		return err
+	})
+	return settlement, err
+}
+
+func (r *interceptedSettlementRepository) Query(ctx context.Context, query *models.SettlementQuery) (settlements []*models.Settlement, err error) {
+	err = r.read(ctx, "Query", func(ctx context.Context) error {
+		settlements, err = r.inner.Query(ctx, query)
+		return err
+	})
+	return settlements, err
+}
+
+func (r *interceptedSettlementRepository) UpdateStatus(ctx context.Context, settlementID string, status models.SettlementStatus) error {
+	return r.write(ctx, "UpdateStatus", func(ctx context.Context) error {
+		return r.inner.UpdateStatus(ctx, settlementID, status)
+	})
+}
+
+func (r *interceptedSettlementRepository) Complete(ctx context.Context, settlementID string) error {
+	return r.write(ctx, "Complete", func(ctx context.Context) error {
+		return r.inner.Complete(ctx, settlementID)
+	})
+}
+
+func (r *interceptedSettlementRepository) Cancel(ctx context.Context, settlementID string) error {
+	return r.write(ctx, "Cancel", func(ctx context.Context) error {
+		return r.inner.Cancel(ctx, settlementID)
+	})
+}
+
+func (r *interceptedSettlementRepository) GetPendingByAccount(ctx context.Context, accountID string) (settlements []*models.Settlement, err error) {
+	err = r.read(ctx, "GetPendingByAccount", func(ctx context.Context) error {
+		settlements, err = r.inner.GetPendingByAccount(ctx, accountID)
+		return err
+	})
+	return settlements, err
+}
+
+type interceptedBalanceRepository struct {
+	interceptedRepository
+	inner interfaces.BalanceRepository
+}
+
+// NewInterceptedBalanceRepository routes every call of inner through interceptor
+func NewInterceptedBalanceRepository(inner interfaces.BalanceRepository, interceptor CallInterceptor) interfaces.BalanceRepository {
+	return &interceptedBalanceRepository{
+		interceptedRepository: interceptedRepository{repository: RepositoryBalance, interceptor: interceptor},
+		inner:                 inner,
+	}
+}
+
+func (r *interceptedBalanceRepository) Upsert(ctx context.Context, balance *models.Balance) error {
+	return r.write(ctx, "Upsert", func(ctx context.Context) error {
+		return r.inner.Upsert(ctx, balance)
+	})
+}
+
+func (r *interceptedBalanceRepository) GetByID(ctx context.Context, balanceID string) (balance *models.Balance, err error) {
+	err = r.read(ctx, "GetByID", func(ctx context.Context) error {
+		balance, err = r.inner.GetByID(ctx, balanceID)
+		return err
+	})
+	return balance, err
+}
+
+func (r *interceptedBalanceRepository) GetByAccountAndCurrency(ctx context.Context, accountID, currency string) (balance *models.Balance, err error) {
+	err = r.read(ctx, "GetByAccountAndCurrency", func(ctx context.Context) error {
+		balance, err = r.inner.GetByAccountAndCurrency(ctx, accountID, currency)
+		return err
+	})
+	return balance, err
+}
+
+func (r *interceptedBalanceRepository) Query(ctx context.Context, query *models.BalanceQuery) (balances []*models.Balance, err error) {
+	err = r.read(ctx, "Query", func(ctx context.Context) error {
+		balances, err = r.inner.Query(ctx, query)
+		return err
+	})
+	return balances, err
+}
+
+func (r *interceptedBalanceRepository) UpdateAvailableBalance(ctx context.Context, balanceID string, availableBalance, lockedBalance float64) error {
+	return r.write(ctx, "UpdateAvailableBalance", func(ctx context.Context) error {
+		return r.inner.UpdateAvailableBalance(ctx, balanceID, availableBalance, lockedBalance)
+	})
+}
+
+func (r *interceptedBalanceRepository) GetByAccount(ctx context.Context, accountID string) (balances []*models.Balance, err error) {
+	err = r.read(ctx, "GetByAccount", func(ctx context.Context) error {
+		balances, err = r.inner.GetByAccount(ctx, accountID)
+		return err
+	})
+	return balances, err
+}
+
+func (r *interceptedBalanceRepository) AtomicUpdate(ctx context.Context, accountID, currency string, availableDelta, lockedDelta float64) error {
+	return r.write(ctx, "AtomicUpdate", func(ctx context.Context) error {
+		return r.inner.AtomicUpdate(ctx, accountID, currency, availableDelta, lockedDelta)
+	})
+}
+
+type interceptedAccountRepository struct {
+	interceptedRepository
+	inner interfaces.AccountRepository
+}
+
+// NewInterceptedAccountRepository routes every call of inner through interceptor
+func NewInterceptedAccountRepository(inner interfaces.AccountRepository, interceptor CallInterceptor) interfaces.AccountRepository {
+	return &interceptedAccountRepository{
+		interceptedRepository: interceptedRepository{repository: RepositoryAccount, interceptor: interceptor},
+		inner:                 inner,
+	}
+}
+
+func (r *interceptedAccountRepository) Create(ctx context.Context, account *models.Account) error {
+	return r.write(ctx, "Create", func(ctx context.Context) error {
+		return r.inner.Create(ctx, account)
+	})
+}
+
+func (r *interceptedAccountRepository) GetByID(ctx context.Context, accountID string) (account *models.Account, err error) {
+	err = r.read(ctx, "GetByID", func(ctx context.Context) error {
+		account, err = r.inner.GetByID(ctx, accountID)
+		return err
+	})
+	return account, err
+}
+
+func (r *interceptedAccountRepository) Query(ctx context.Context, query *models.AccountQuery) (accounts []*models.Account, err error) {
+	err = r.read(ctx, "Query", func(ctx context.Context) error {
+		accounts, err = r.inner.Query(ctx, query)
+		return err
+	})
+	return accounts, err
+}
+
+func (r *interceptedAccountRepository) Update(ctx context.Context, account *models.Account) error {
+	return r.write(ctx, "Update", func(ctx context.Context) error {
+		return r.inner.Update(ctx, account)
+	})
+}
+
+func (r *interceptedAccountRepository) UpdateStatus(ctx context.Context, accountID string, status models.AccountStatus) error {
+	return r.write(ctx, "UpdateStatus", func(ctx context.Context) error {
+		return r.inner.UpdateStatus(ctx, accountID, status)
+	})
+}
+
+func (r *interceptedAccountRepository) GetChildren(ctx context.Context, accountID string) (accounts []*models.Account, err error) {
+	err = r.read(ctx, "GetChildren", func(ctx context.Context) error {
+		accounts, err = r.inner.GetChildren(ctx, accountID)
+		return err
+	})
+	return accounts, err
+}
+
+func (r *interceptedAccountRepository) GetDescendants(ctx context.Context, accountID string) (accounts []*models.Account, err error) {
+	err = r.read(ctx, "GetDescendants", func(ctx context.Context) error {
+		accounts, err = r.inner.GetDescendants(ctx, accountID)
+		return err
+	})
+	return accounts, err
+}
+
+type interceptedAssetRepository struct {
+	interceptedRepository
+	inner interfaces.AssetRepository
+}
+
+// NewInterceptedAssetRepository routes every call of inner through interceptor
+func NewInterceptedAssetRepository(inner interfaces.AssetRepository, interceptor CallInterceptor) interfaces.AssetRepository {
+	return &interceptedAssetRepository{
+		interceptedRepository: interceptedRepository{repository: RepositoryAsset, interceptor: interceptor},
+		inner:                 inner,
+	}
+}
+
+func (r *interceptedAssetRepository) Create(ctx context.Context, asset *models.Asset) error {
+	return r.write(ctx, "Create", func(ctx context.Context) error {
+		return r.inner.Create(ctx, asset)
+	})
+}
+
+func (r *interceptedAssetRepository) GetBySymbol(ctx context.Context, symbol string) (asset *models.Asset, err error) {
+	err = r.read(ctx, "GetBySymbol", func(ctx context.Context) error {
+		asset, err = r.inner.GetBySymbol(ctx, symbol)
+		return err
+	})
+	return asset, err
+}
+
+func (r *interceptedAssetRepository) Query(ctx context.Context, query *models.AssetQuery) (assets []*models.Asset, err error) {
+	err = r.read(ctx, "Query", func(ctx context.Context) error {
+		assets, err = r.inner.Query(ctx, query)
+		return err
+	})
+	return assets, err
+}
+
+func (r *interceptedAssetRepository) Update(ctx context.Context, asset *models.Asset) error {
+	return r.write(ctx, "Update", func(ctx context.Context) error {
+		return r.inner.Update(ctx, asset)
+	})
+}
+
+func (r *interceptedAssetRepository) SetEnabled(ctx context.Context, symbol string, enabled bool) error {
+	return r.write(ctx, "SetEnabled", func(ctx context.Context) error {
+		return r.inner.SetEnabled(ctx, symbol, enabled)
+	})
+}
+
+type interceptedServiceDiscoveryRepository struct {
+	interceptedRepository
+	inner interfaces.ServiceDiscoveryRepository
+}
+
+// NewInterceptedServiceDiscoveryRepository routes every call of inner through interceptor
+func NewInterceptedServiceDiscoveryRepository(inner interfaces.ServiceDiscoveryRepository, interceptor CallInterceptor) interfaces.ServiceDiscoveryRepository {
+	return &interceptedServiceDiscoveryRepository{
+		interceptedRepository: interceptedRepository{repository: RepositoryServiceDiscovery, interceptor: interceptor},
+		inner:                 inner,
+	}
+}
+
+func (r *interceptedServiceDiscoveryRepository) Register(ctx context.Context, info *interfaces.ServiceInfo) error {
+	return r.write(ctx, "Register", func(ctx context.Context) error {
+		return r.inner.Register(ctx, info)
+	})
+}
+
+func (r *interceptedServiceDiscoveryRepository) Deregister(ctx context.Context, serviceID string) error {
+	return r.write(ctx, "Deregister", func(ctx context.Context) error {
+		return r.inner.Deregister(ctx, serviceID)
+	})
+}
+
+func (r *interceptedServiceDiscoveryRepository) Heartbeat(ctx context.Context, serviceID string) error {
+	return r.write(ctx, "Heartbeat", func(ctx context.Context) error {
+		return r.inner.Heartbeat(ctx, serviceID)
+	})
+}
+
+func (r *interceptedServiceDiscoveryRepository) UpdateStatus(ctx context.Context, serviceID string, status interfaces.ServiceStatus) error {
+	return r.write(ctx, "UpdateStatus", func(ctx context.Context) error {
+		return r.inner.UpdateStatus(ctx, serviceID, status)
+	})
+}
+
+func (r *interceptedServiceDiscoveryRepository) Discover(ctx context.Context, serviceName string) (services []*interfaces.ServiceInfo, err error) {
+	err = r.read(ctx, "Discover", func(ctx context.Context) error {
+		services, err = r.inner.Discover(ctx, serviceName)
+		return err
+	})
+	return services, err
+}
+
+func (r *interceptedServiceDiscoveryRepository) Query(ctx context.Context, query *interfaces.DiscoverQuery) (services []*interfaces.ServiceInfo, err error) {
+	err = r.read(ctx, "Query", func(ctx context.Context) error {
+		services, err = r.inner.Query(ctx, query)
+		return err
+	})
+	return services, err
+}
+
+func (r *interceptedServiceDiscoveryRepository) GetServiceInfo(ctx context.Context, serviceID string) (info *interfaces.ServiceInfo, err error) {
+	err = r.read(ctx, "GetServiceInfo", func(ctx context.Context) error {
+		info, err = r.inner.GetServiceInfo(ctx, serviceID)
+		return err
+	})
+	return info, err
+}
+
+func (r *interceptedServiceDiscoveryRepository) ListServices(ctx context.Context) (services []*interfaces.ServiceInfo, err error) {
+	err = r.read(ctx, "ListServices", func(ctx context.Context) error {
+		services, err = r.inner.ListServices(ctx)
+		return err
+	})
+	return services, err
+}
+
+func (r *interceptedServiceDiscoveryRepository) HealthCheck(ctx context.Context) error {
+	return r.inner.HealthCheck(ctx)
+}
+
+type interceptedCacheRepository struct {
+	interceptedRepository
+	inner interfaces.CacheRepository
+}
+
+// NewInterceptedCacheRepository routes every call of inner through interceptor
+func NewInterceptedCacheRepository(inner interfaces.CacheRepository, interceptor CallInterceptor) interfaces.CacheRepository {
+	return &interceptedCacheRepository{
+		interceptedRepository: interceptedRepository{repository: RepositoryCache, interceptor: interceptor},
+		inner:                 inner,
+	}
+}
+
+func (r *interceptedCacheRepository) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	return r.write(ctx, "Set", func(ctx context.Context) error {
+		return r.inner.Set(ctx, key, value, ttl)
+	})
+}
+
+func (r *interceptedCacheRepository) Get(ctx context.Context, key string) (value string, err error) {
+	err = r.read(ctx, "Get", func(ctx context.Context) error {
+		value, err = r.inner.Get(ctx, key)
+		return err
+	})
+	return value, err
+}
+
+func (r *interceptedCacheRepository) Delete(ctx context.Context, key string) error {
+	return r.write(ctx, "Delete", func(ctx context.Context) error {
+		return r.inner.Delete(ctx, key)
+	})
+}
+
+func (r *interceptedCacheRepository) Exists(ctx context.Context, key string) (exists bool, err error) {
+	err = r.read(ctx, "Exists", func(ctx context.Context) error {
+		exists, err = r.inner.Exists(ctx, key)
+		return err
+	})
+	return exists, err
+}
+
+func (r *interceptedCacheRepository) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	return r.write(ctx, "Expire", func(ctx context.Context) error {
+		return r.inner.Expire(ctx, key, ttl)
+	})
+}
+
+func (r *interceptedCacheRepository) TTL(ctx context.Context, key string) (ttl time.Duration, err error) {
+	err = r.read(ctx, "TTL", func(ctx context.Context) error {
+		ttl, err = r.inner.TTL(ctx, key)
+		return err
+	})
+	return ttl, err
+}
+
+func (r *interceptedCacheRepository) Keys(ctx context.Context, pattern string) (keys []string, err error) {
+	err = r.read(ctx, "Keys", func(ctx context.Context) error {
+		keys, err = r.inner.Keys(ctx, pattern)
+		return err
+	})
+	return keys, err
+}
+
+func (r *interceptedCacheRepository) DeletePattern(ctx context.Context, pattern string) error {
+	return r.write(ctx, "DeletePattern", func(ctx context.Context) error {
+		return r.inner.DeletePattern(ctx, pattern)
+	})
+}
+
+func (r *interceptedCacheRepository) MGet(ctx context.Context, keys []string) (values map[string]string, err error) {
+	err = r.read(ctx, "MGet", func(ctx context.Context) error {
+		values, err = r.inner.MGet(ctx, keys)
+		return err
+	})
+	return values, err
+}
+
+func (r *interceptedCacheRepository) MSet(ctx context.Context, values map[string]interface{}, ttl time.Duration) error {
+	return r.write(ctx, "MSet", func(ctx context.Context) error {
+		return r.inner.MSet(ctx, values, ttl)
+	})
+}
+
+func (r *interceptedCacheRepository) SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (set bool, err error) {
+	err = r.write(ctx, "SetNX", func(ctx context.Context) error {
+		set, err = r.inner.SetNX(ctx, key, value, ttl)
+		return err
+	})
+	return set, err
+}
+
+func (r *interceptedCacheRepository) CompareAndSwap(ctx context.Context, key, expected string, value interface{}, ttl time.Duration) (swapped bool, err error) {
+	err = r.write(ctx, "CompareAndSwap", func(ctx context.Context) error {
+		swapped, err = r.inner.CompareAndSwap(ctx, key, expected, value, ttl)
+		return err
+	})
+	return swapped, err
+}
+
+func (r *interceptedCacheRepository) HSet(ctx context.Context, key string, fields map[string]interface{}) error {
+	return r.write(ctx, "HSet", func(ctx context.Context) error {
+		return r.inner.HSet(ctx, key, fields)
+	})
+}
+
+func (r *interceptedCacheRepository) HGet(ctx context.Context, key, field string) (value string, err error) {
+	err = r.read(ctx, "HGet", func(ctx context.Context) error {
+		value, err = r.inner.HGet(ctx, key, field)
+		return err
+	})
+	return value, err
+}
+
+func (r *interceptedCacheRepository) HGetAll(ctx context.Context, key string) (fields map[string]string, err error) {
+	err = r.read(ctx, "HGetAll", func(ctx context.Context) error {
+		fields, err = r.inner.HGetAll(ctx, key)
+		return err
+	})
+	return fields, err
+}
+
+func (r *interceptedCacheRepository) HDel(ctx context.Context, key string, fields ...string) error {
+	return r.write(ctx, "HDel", func(ctx context.Context) error {
+		return r.inner.HDel(ctx, key, fields...)
+	})
+}
+
+func (r *interceptedCacheRepository) Incr(ctx context.Context, key string, delta int64, ttl time.Duration) (value int64, err error) {
+	err = r.write(ctx, "Incr", func(ctx context.Context) error {
+		value, err = r.inner.Incr(ctx, key, delta, ttl)
+		return err
+	})
+	return value, err
+}
+
+func (r *interceptedCacheRepository) IncrByFloat(ctx context.Context, key string, delta float64, ttl time.Duration) (value float64, err error) {
+	err = r.write(ctx, "IncrByFloat", func(ctx context.Context) error {
+		value, err = r.inner.IncrByFloat(ctx, key, delta, ttl)
+		return err
+	})
+	return value, err
+}
+
+func (r *interceptedCacheRepository) SetWithTags(ctx context.Context, key string, value interface{}, ttl time.Duration, tags ...string) error {
+	return r.write(ctx, "SetWithTags", func(ctx context.Context) error {
+		return r.inner.SetWithTags(ctx, key, value, ttl, tags...)
+	})
+}
+
+func (r *interceptedCacheRepository) TagFence(ctx context.Context) (fence int64, err error) {
+	err = r.read(ctx, "TagFence", func(ctx context.Context) error {
+		fence, err = r.inner.TagFence(ctx)
+		return err
+	})
+	return fence, err
+}
+
+func (r *interceptedCacheRepository) SetWithTagsSince(ctx context.Context, fence int64, key string, value interface{}, ttl time.Duration, tags ...string) (stored bool, err error) {
+	err = r.write(ctx, "SetWithTagsSince", func(ctx context.Context) error {
+		stored, err = r.inner.SetWithTagsSince(ctx, fence, key, value, ttl, tags...)
+		return err
+	})
+	return stored, err
+}
+
+func (r *interceptedCacheRepository) InvalidateTag(ctx context.Context, tag string) (keys []string, err error) {
+	err = r.write(ctx, "InvalidateTag", func(ctx context.Context) error {
+		keys, err = r.inner.InvalidateTag(ctx, tag)
+		return err
+	})
+	return keys, err
+}
+
+func (r *interceptedCacheRepository) PruneTag(ctx context.Context, tag string) (removed int, err error) {
+	err = r.write(ctx, "PruneTag", func(ctx context.Context) error {
+		removed, err = r.inner.PruneTag(ctx, tag)
+		return err
+	})
+	return removed, err
+}
+
+func (r *interceptedCacheRepository) HealthCheck(ctx context.Context) error {
+	return r.inner.HealthCheck(ctx)
+}
+
+type interceptedLockRepository struct {
+	interceptedRepository
+	inner interfaces.LockRepository
+}
+
+// NewInterceptedLockRepository routes every call of inner through interceptor
+func NewInterceptedLockRepository(inner interfaces.LockRepository, interceptor CallInterceptor) interfaces.LockRepository {
+	return &interceptedLockRepository{
+		interceptedRepository: interceptedRepository{repository: RepositoryLock, interceptor: interceptor},
+		inner:                 inner,
+	}
+}
+
+func (r *interceptedLockRepository) TryLock(ctx context.Context, key string, ttl time.Duration) (lock *interfaces.Lock, err error) {
+	err = r.write(ctx, "TryLock", func(ctx context.Context) error {
+		lock, err = r.inner.TryLock(ctx, key, ttl)
+		return err
+	})
+	return lock, err
+}
+
+func (r *interceptedLockRepository) Lock(ctx context.Context, key string, ttl, timeout time.Duration) (lock *interfaces.Lock, err error) {
+	err = r.write(ctx, "Lock", func(ctx context.Context) error {
+		lock, err = r.inner.Lock(ctx, key, ttl, timeout)
+		return err
+	})
+	return lock, err
+}
+
+func (r *interceptedLockRepository) Unlock(ctx context.Context, lock *interfaces.Lock) error {
+	return r.write(ctx, "Unlock", func(ctx context.Context) error {
+		return r.inner.Unlock(ctx, lock)
+	})
+}
+
+func (r *interceptedLockRepository) ExtendLock(ctx context.Context, lock *interfaces.Lock, ttl time.Duration) error {
+	return r.write(ctx, "ExtendLock", func(ctx context.Context) error {
+		return r.inner.ExtendLock(ctx, lock, ttl)
+	})
+}
+
+func (r *interceptedLockRepository) TryAcquireSemaphore(ctx context.Context, name string, limit int, ttl time.Duration) (permit *interfaces.SemaphorePermit, err error) {
+	err = r.write(ctx, "TryAcquireSemaphore", func(ctx context.Context) error {
+		permit, err = r.inner.TryAcquireSemaphore(ctx, name, limit, ttl)
+		return err
+	})
+	return permit, err
+}
+
+func (r *interceptedLockRepository) AcquireSemaphore(ctx context.Context, name string, limit int, ttl, timeout time.Duration) (permit *interfaces.SemaphorePermit, err error) {
+	err = r.write(ctx, "AcquireSemaphore", func(ctx context.Context) error {
+		permit, err = r.inner.AcquireSemaphore(ctx, name, limit, ttl, timeout)
+		return err
+	})
+	return permit, err
+}
+
+func (r *interceptedLockRepository) ReleaseSemaphore(ctx context.Context, permit *interfaces.SemaphorePermit) error {
+	return r.write(ctx, "ReleaseSemaphore", func(ctx context.Context) error {
+		return r.inner.ReleaseSemaphore(ctx, permit)
+	})
+}
+
+func (r *interceptedLockRepository) ExtendSemaphore(ctx context.Context, permit *interfaces.SemaphorePermit, ttl time.Duration) error {
+	return r.write(ctx, "ExtendSemaphore", func(ctx context.Context) error {
+		return r.inner.ExtendSemaphore(ctx, permit, ttl)
+	})
+}
@@ -0,0 +1,67 @@
+package adapters
+
+import "context"
+
+// Repository names reported to interceptors
+const (
+	RepositoryPosition         = "position"
+	RepositorySettlement       = "settlement"
+	RepositoryBalance          = "balance"
+	RepositoryAccount          = "account"
+	RepositoryAsset            = "asset"
+	RepositoryCache            = "cache"
+	RepositoryServiceDiscovery = "service_discovery"
+	RepositoryLock             = "lock"
+)
+
+// RepositoryCall describes one repository method invocation
+type RepositoryCall struct {
+	Repository string
+	Operation  string
+
+	// ReadOnly is set for calls that do not modify the store
+	ReadOnly bool
+}
+
+// CallInterceptor wraps a repository call. It must call next at most once and
+// return its error, or return its own error without calling next.
+type CallInterceptor func(ctx context.Context, call RepositoryCall, next func(ctx context.Context) error) error
+
+// ChainInterceptors combines interceptors; the first one is outermost
+func ChainInterceptors(interceptors ...CallInterceptor) CallInterceptor {
+	active := make([]CallInterceptor, 0, len(interceptors))
+	for _, interceptor := range interceptors {
+		if interceptor != nil {
+			active = append(active, interceptor)
+		}
+	}
+
+	return func(ctx context.Context, call RepositoryCall, next func(ctx context.Context) error) error {
+		for i := len(active) - 1; i >= 0; i-- {
+			interceptor, inner := active[i], next
+			next = func(ctx context.Context) error {
+				return interceptor(ctx, call, inner)
+			}
+		}
+		return next(ctx)
+	}
+}
+
+// interceptedRepository dispatches a repository's calls through an interceptor
+type interceptedRepository struct {
+	repository  string
+	interceptor CallInterceptor
+}
+
+func (r interceptedRepository) call(ctx context.Context, operation string, readOnly bool, fn func(ctx context.Context) error) error {
+	call := RepositoryCall{Repository: r.repository, Operation: operation, ReadOnly: readOnly}
+	return r.interceptor(ctx, call, fn)
+}
+
+func (r interceptedRepository) read(ctx context.Context, operation string, fn func(ctx context.Context) error) error {
+	return r.call(ctx, operation, true, fn)
+}
+
+func (r interceptedRepository) write(ctx context.Context, operation string, fn func(ctx context.Context) error) error {
+	return r.call(ctx, operation, false, fn)
+}
@@ -0,0 +1,248 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/interfaces"
+)
+
+// DefaultRedactedFields are masked by NewRedactingLogger unless configured otherwise
+var DefaultRedactedFields = []string{
+	"account_id",
+	"source_account",
+	"destination_account",
+	"metadata",
+	"key",
+	"tag",
+	"pattern",
+}
+
+// RedactionConfig controls which log fields are masked
+type RedactionConfig struct {
+	// Fields whose values are masked (DefaultRedactedFields if empty)
+	Fields []string
+
+	// RevealAtDebug logs the unmasked values in debug messages, which are
+	// normally only enabled while troubleshooting
+	RevealAtDebug bool
+}
+
+// accountSegment matches the account ID inside cache keys and tags
+var accountSegment = regexp.MustCompile(`(account:)([^:*]+)`)
+
+// accountRef formats an account ID in error text. accountErrorf records such
+// arguments so the redacting logger can mask them in the message however the
+// error is wrapped later.
+type accountRef string
+
+// identifiedError is an error whose message contains account IDs
+type identifiedError struct {
+	err        error
+	accountIDs []string
+}
+
+func (e *identifiedError) Error() string { return e.err.Error() }
+func (e *identifiedError) Unwrap() error { return e.err }
+
+// accountErrorf is fmt.Errorf that records its accountRef arguments
+func accountErrorf(format string, args ...interface{}) error {
+	err := &identifiedError{err: fmt.Errorf(format, args...)}
+	for _, arg := range args {
+		if id, ok := arg.(accountRef); ok && id != "" {
+			err.accountIDs = append(err.accountIDs, string(id))
+		}
+	}
+	return err
+}
+
+// errorAccountIDs collects the account IDs recorded anywhere in err's chain
+func errorAccountIDs(err error) []string {
+	var ids []string
+	for err != nil {
+		if identified, ok := err.(*identifiedError); ok {
+			ids = append(ids, identified.accountIDs...)
+		}
+		switch wrapped := err.(type) {
+		case interface{ Unwrap() []error }:
+			for _, inner := range wrapped.Unwrap() {
+				ids = append(ids, errorAccountIDs(inner)...)
+			}
+			return ids
+		case interface{ Unwrap() error }:
+			err = wrapped.Unwrap()
+		default:
+			return ids
+		}
+	}
+	return ids
+}
+
+// redactedError masks identifiers in the message of the error it wraps
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e *redactedError) Error() string { return e.msg }
+func (e *redactedError) Unwrap() error { return e.err }
+
+// redactingLogger holds sensitive fields and errors back from the wrapped
+// logger and masks them when a line is emitted, depending on the line's level
+type redactingLogger struct {
+	inner     interfaces.Logger
+	sensitive map[string]bool
+	reveal    bool
+	pending   interfaces.LogFields
+	err       error
+}
+
+// NewRedactingLogger masks sensitive fields before they reach inner.
+// Account identifiers keep their last four characters so lines can still be
+// correlated; metadata is dropped entirely.
+func NewRedactingLogger(inner interfaces.Logger, cfg RedactionConfig) interfaces.Logger {
+	fields := cfg.Fields
+	if len(fields) == 0 {
+		fields = DefaultRedactedFields
+	}
+
+	sensitive := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		sensitive[field] = true
+	}
+
+	return &redactingLogger{inner: inner, sensitive: sensitive, reveal: cfg.RevealAtDebug}
+}
+
+func (l *redactingLogger) with(inner interfaces.Logger, pending interfaces.LogFields) *redactingLogger {
+	return &redactingLogger{inner: inner, sensitive: l.sensitive, reveal: l.reveal, pending: pending, err: l.err}
+}
+
+func (l *redactingLogger) WithField(key string, value interface{}) interfaces.Logger {
+	return l.WithFields(interfaces.LogFields{key: value})
+}
+
+func (l *redactingLogger) WithFields(fields interfaces.LogFields) interfaces.Logger {
+	plain := interfaces.LogFields{}
+	pending := l.pending
+	copied := false
+	for key, value := range fields {
+		if !l.sensitive[key] {
+			plain[key] = value
+			continue
+		}
+		if !copied {
+			pending = make(interfaces.LogFields, len(l.pending)+1)
+			for k, v := range l.pending {
+				pending[k] = v
+			}
+			copied = true
+		}
+		pending[key] = value
+	}
+
+	inner := l.inner
+	if len(plain) > 0 {
+		inner = inner.WithFields(plain)
+	}
+	return l.with(inner, pending)
+}
+
+func (l *redactingLogger) WithError(err error) interfaces.Logger {
+	held := l.with(l.inner, l.pending)
+	held.err = err
+	return held
+}
+
+func (l *redactingLogger) WithContext(ctx context.Context) interfaces.Logger {
+	return l.with(l.inner.WithContext(ctx), l.pending)
+}
+
+func (l *redactingLogger) Debug(msg string) { l.emit(interfaces.LogLevelDebug).Debug(msg) }
+func (l *redactingLogger) Info(msg string)  { l.emit(interfaces.LogLevelInfo).Info(msg) }
+func (l *redactingLogger) Warn(msg string)  { l.emit(interfaces.LogLevelWarn).Warn(msg) }
+func (l *redactingLogger) Error(msg string) { l.emit(interfaces.LogLevelError).Error(msg) }
+
+func (l *redactingLogger) Enabled(level interfaces.LogLevel) bool {
+	return l.inner.Enabled(level)
+}
+
+// emit attaches the held-back fields and error, masked unless revealed at
+// this level
+func (l *redactingLogger) emit(level interfaces.LogLevel) interfaces.Logger {
+	inner := l.inner
+	revealed := l.reveal && level == interfaces.LogLevelDebug
+	if l.err != nil {
+		if revealed {
+			inner = inner.WithError(l.err)
+		} else {
+			inner = inner.WithError(l.redactError(l.err))
+		}
+	}
+
+	if len(l.pending) == 0 {
+		return inner
+	}
+	if revealed {
+		return inner.WithFields(l.pending)
+	}
+
+	masked := make(interfaces.LogFields, len(l.pending))
+	for key, value := range l.pending {
+		masked[key] = redactLogValue(key, value)
+	}
+	return inner.WithFields(masked)
+}
+
+// redactError masks the account IDs recorded in err and the values of held
+// back identifier fields wherever they appear in its message
+func (l *redactingLogger) redactError(err error) error {
+	var ids []string
+	if l.sensitive["account_id"] {
+		ids = errorAccountIDs(err)
+	}
+	for key, value := range l.pending {
+		switch key {
+		case "metadata", "key", "tag", "pattern":
+			continue
+		}
+		ids = append(ids, fmt.Sprint(value))
+	}
+	if len(ids) == 0 {
+		return err
+	}
+
+	// Replace longer IDs first so one that contains another is fully masked
+	sort.Slice(ids, func(i, j int) bool { return len(ids[i]) > len(ids[j]) })
+	msg := err.Error()
+	for _, id := range ids {
+		if id != "" {
+			msg = strings.ReplaceAll(msg, id, maskIdentifier(id))
+		}
+	}
+	return &redactedError{msg: msg, err: err}
+}
+
+func redactLogValue(key string, value interface{}) interface{} {
+	switch key {
+	case "metadata":
+		return "[REDACTED]"
+	case "key", "tag", "pattern":
+		return accountSegment.ReplaceAllStringFunc(fmt.Sprint(value), func(segment string) string {
+			parts := accountSegment.FindStringSubmatch(segment)
+			return parts[1] + maskIdentifier(parts[2])
+		})
+	}
+	return maskIdentifier(fmt.Sprint(value))
+}
+
+// maskIdentifier keeps the last four characters of identifiers longer than eight
+func maskIdentifier(id string) string {
+	if len(id) <= 8 {
+		return "****"
+	}
+	return "****" + id[len(id)-4:]
+}
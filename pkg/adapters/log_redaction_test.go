@@ -0,0 +1,131 @@
+package adapters
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/interfaces"
+)
+
+// TestRedactingLogger tests level-aware masking of sensitive fields
+func TestRedactingLogger(t *testing.T) {
+	var buf bytes.Buffer
+	inner := NewSlogLogger(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	lastLine := func() map[string]interface{} {
+		lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+		var line map[string]interface{}
+		if err := json.Unmarshal(lines[len(lines)-1], &line); err != nil {
+			t.Fatalf("invalid log line: %v", err)
+		}
+		return line
+	}
+
+	tests := []struct {
+		name     string
+		reveal   bool
+		debug    bool
+		expected map[string]interface{}
+	}{
+		{
+			name:   "masked at info",
+			reveal: true,
+			expected: map[string]interface{}{
+				"account_id": "****7890",
+				"metadata":   "[REDACTED]",
+				"key":        "position:account:****7890:all",
+				"symbol":     "BTC",
+			},
+		},
+		{
+			name:   "revealed at debug",
+			reveal: true,
+			debug:  true,
+			expected: map[string]interface{}{
+				"account_id": "ACC-1234567890",
+				"key":        "position:account:ACC-1234567890:all",
+			},
+		},
+		{
+			name:   "masked at debug without reveal",
+			reveal: false,
+			debug:  true,
+			expected: map[string]interface{}{
+				"account_id": "****7890",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := NewRedactingLogger(inner, RedactionConfig{RevealAtDebug: tt.reveal}).
+				WithField("account_id", "ACC-1234567890").
+				WithField("symbol", "BTC").
+				WithField("key", "position:account:ACC-1234567890:all").
+				WithField("metadata", `{"iban":"DE00"}`)
+			if tt.debug {
+				logger.Debug("msg")
+			} else {
+				logger.Info("msg")
+			}
+
+			line := lastLine()
+			for key, value := range tt.expected {
+				if line[key] != value {
+					t.Errorf("%s = %v, expected %v", key, line[key], value)
+				}
+			}
+		})
+	}
+}
+
+// TestRedactingLoggerErrors tests that account IDs in error messages are
+// masked however the error was wrapped
+func TestRedactingLoggerErrors(t *testing.T) {
+	closed := fmt.Errorf("failed to create position: %w",
+		accountErrorf("%w: account %s is closed", interfaces.ErrInvalidAccount, accountRef("ACC-1234567890")))
+
+	tests := []struct {
+		name     string
+		reveal   bool
+		debug    bool
+		field    string
+		err      error
+		expected string
+	}{
+		{name: "recorded account ID", err: closed, expected: "failed to create position: invalid account: account ****7890 is closed"},
+		{name: "revealed at debug", reveal: true, debug: true, err: closed, expected: closed.Error()},
+		{name: "joined errors", err: errors.Join(errors.New("rollback failed"), closed), expected: "rollback failed\nfailed to create position: invalid account: account ****7890 is closed"},
+		{name: "held back field value", field: "ACC-0987654321", err: errors.New("no route for ACC-0987654321"), expected: "no route for ****4321"},
+		{name: "unrelated error", err: errors.New("connection refused"), expected: "connection refused"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			inner := NewSlogLogger(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+			logger := NewRedactingLogger(inner, RedactionConfig{RevealAtDebug: tt.reveal})
+			if tt.field != "" {
+				logger = logger.WithField("account_id", tt.field)
+			}
+			logger = logger.WithError(tt.err)
+			if tt.debug {
+				logger.Debug("msg")
+			} else {
+				logger.Error("msg")
+			}
+
+			var line map[string]interface{}
+			if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+				t.Fatalf("invalid log line: %v", err)
+			}
+			if line["error"] != tt.expected {
+				t.Errorf("error = %q, expected %q", line["error"], tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/interfaces"
+	"github.com/sirupsen/logrus"
+)
+
+// logrusLogger adapts a logrus entry to interfaces.Logger
+type logrusLogger struct {
+	entry *logrus.Entry
+}
+
+// NewLogrusLogger adapts logger to interfaces.Logger
+func NewLogrusLogger(logger *logrus.Logger) interfaces.Logger {
+	return logrusLogger{entry: logrus.NewEntry(logger)}
+}
+
+func (l logrusLogger) WithField(key string, value interface{}) interfaces.Logger {
+	return logrusLogger{entry: l.entry.WithField(key, value)}
+}
+
+func (l logrusLogger) WithFields(fields interfaces.LogFields) interfaces.Logger {
+	return logrusLogger{entry: l.entry.WithFields(logrus.Fields(fields))}
+}
+
+func (l logrusLogger) WithError(err error) interfaces.Logger {
+	return logrusLogger{entry: l.entry.WithError(err)}
+}
+
+func (l logrusLogger) WithContext(ctx context.Context) interfaces.Logger {
+	return logrusLogger{entry: l.entry.WithContext(ctx)}
+}
+
+func (l logrusLogger) Debug(msg string) { l.entry.Debug(msg) }
+func (l logrusLogger) Info(msg string)  { l.entry.Info(msg) }
+func (l logrusLogger) Warn(msg string)  { l.entry.Warn(msg) }
+func (l logrusLogger) Error(msg string) { l.entry.Error(msg) }
+
+func (l logrusLogger) Enabled(level interfaces.LogLevel) bool {
+	return l.entry.Logger.IsLevelEnabled(logrusLevel(level))
+}
+
+// SetLevel changes the level of the underlying logrus logger
+func (l logrusLogger) SetLevel(level interfaces.LogLevel) {
+	l.entry.Logger.SetLevel(logrusLevel(level))
+}
+
+func logrusLevel(level interfaces.LogLevel) logrus.Level {
+	switch level {
+	case interfaces.LogLevelDebug:
+		return logrus.DebugLevel
+	case interfaces.LogLevelInfo:
+		return logrus.InfoLevel
+	case interfaces.LogLevelWarn:
+		return logrus.WarnLevel
+	}
+	return logrus.ErrorLevel
+}
+
+// slogLogger adapts a log/slog logger to interfaces.Logger
+type slogLogger struct {
+	logger *slog.Logger
+	ctx    context.Context
+}
+
+// NewSlogLogger adapts logger to interfaces.Logger; nil uses slog.Default()
+func NewSlogLogger(logger *slog.Logger) interfaces.Logger {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return slogLogger{logger: logger, ctx: context.Background()}
+}
+
+func (l slogLogger) WithField(key string, value interface{}) interfaces.Logger {
+	return slogLogger{logger: l.logger.With(key, value), ctx: l.ctx}
+}
+
+func (l slogLogger) WithFields(fields interfaces.LogFields) interfaces.Logger {
+	args := make([]any, 0, 2*len(fields))
+	for key, value := range fields {
+		args = append(args, key, value)
+	}
+	return slogLogger{logger: l.logger.With(args...), ctx: l.ctx}
+}
+
+func (l slogLogger) WithError(err error) interfaces.Logger {
+	return slogLogger{logger: l.logger.With("error", err), ctx: l.ctx}
+}
+
+func (l slogLogger) WithContext(ctx context.Context) interfaces.Logger {
+	return slogLogger{logger: l.logger, ctx: ctx}
+}
+
+func (l slogLogger) Debug(msg string) { l.logger.DebugContext(l.ctx, msg) }
+func (l slogLogger) Info(msg string)  { l.logger.InfoContext(l.ctx, msg) }
+func (l slogLogger) Warn(msg string)  { l.logger.WarnContext(l.ctx, msg) }
+func (l slogLogger) Error(msg string) { l.logger.ErrorContext(l.ctx, msg) }
+
+func (l slogLogger) Enabled(level interfaces.LogLevel) bool {
+	return l.logger.Enabled(l.ctx, slogLevel(level))
+}
+
+func slogLevel(level interfaces.LogLevel) slog.Level {
+	switch level {
+	case interfaces.LogLevelDebug:
+		return slog.LevelDebug
+	case interfaces.LogLevelInfo:
+		return slog.LevelInfo
+	case interfaces.LogLevelWarn:
+		return slog.LevelWarn
+	}
+	return slog.LevelError
+}
+
+// ParseLogLevel parses a LOG_LEVEL value: debug, info, warn or error
+func ParseLogLevel(value string) (interfaces.LogLevel, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "debug":
+		return interfaces.LogLevelDebug, nil
+	case "info":
+		return interfaces.LogLevelInfo, nil
+	case "warn", "warning":
+		return interfaces.LogLevelWarn, nil
+	case "error":
+		return interfaces.LogLevelError, nil
+	}
+	return interfaces.LogLevelInfo, fmt.Errorf("unknown log level %q (expected debug, info, warn or error)", value)
+}
@@ -0,0 +1,273 @@
+package adapters
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/interfaces"
+	"github.com/redis/go-redis/v9"
+)
+
+// metricsPrefix namespaces every exported metric name
+const metricsPrefix = "custodian_data_adapter_"
+
+// Call outcomes recorded by Metrics
+const (
+	OutcomeSuccess     = "success"
+	OutcomeNotFound    = "not_found"
+	OutcomeNotAcquired = "not_acquired"
+	OutcomeRejected    = "rejected"
+	OutcomeError       = "error"
+)
+
+// defaultLatencyBuckets are histogram upper bounds in seconds
+var defaultLatencyBuckets = []float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// callSeries identifies one counter and histogram series
+type callSeries struct {
+	repository string
+	operation  string
+	outcome    string
+}
+
+type callStats struct {
+	count   uint64
+	sum     float64
+	buckets []uint64 // non-cumulative counts per bucket; the last one is +Inf
+}
+
+// Metrics records repository call counts and latencies and exposes them,
+// with connection pool and circuit breaker gauges, in the Prometheus text
+// exposition format. It has no dependency on a Prometheus client library.
+type Metrics struct {
+	instance string
+	buckets  []float64
+
+	mu    sync.Mutex
+	calls map[callSeries]*callStats
+
+	collectorsMu sync.Mutex
+	collectors   []func(w *metricsWriter)
+}
+
+// NewMetrics creates a registry whose series carry the instance label
+func NewMetrics(instance string) *Metrics {
+	return &Metrics{
+		instance: instance,
+		buckets:  defaultLatencyBuckets,
+		calls:    make(map[callSeries]*callStats),
+	}
+}
+
+// Interceptor records every call passing through it. Place it outside the
+// circuit breaker so rejected calls are counted.
+func (m *Metrics) Interceptor() CallInterceptor {
+	return func(ctx context.Context, call RepositoryCall, next func(ctx context.Context) error) error {
+		start := time.Now()
+		err := next(ctx)
+		m.Observe(call.Repository, call.Operation, callOutcome(err), time.Since(start))
+		return err
+	}
+}
+
+// Observe records one call
+func (m *Metrics) Observe(repository, operation, outcome string, duration time.Duration) {
+	series := callSeries{repository: repository, operation: operation, outcome: outcome}
+	seconds := duration.Seconds()
+	bucket := sort.SearchFloat64s(m.buckets, seconds)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats, ok := m.calls[series]
+	if !ok {
+		stats = &callStats{buckets: make([]uint64, len(m.buckets)+1)}
+		m.calls[series] = stats
+	}
+	stats.count++
+	stats.sum += seconds
+	stats.buckets[bucket]++
+}
+
+// ObservePostgresPool exports database/sql pool statistics on every scrape
+func (m *Metrics) ObservePostgresPool(stats func() sql.DBStats) {
+	m.addCollector(func(w *metricsWriter) {
+		s := stats()
+		w.gauge("postgres_connections", "PostgreSQL connections by state", float64(s.OpenConnections), "state", "open")
+		w.gauge("postgres_connections", "", float64(s.InUse), "state", "in_use")
+		w.gauge("postgres_connections", "", float64(s.Idle), "state", "idle")
+		w.gauge("postgres_max_open_connections", "Maximum open PostgreSQL connections", float64(s.MaxOpenConnections))
+		w.counter("postgres_wait_count_total", "Connections waited for", float64(s.WaitCount))
+		w.counter("postgres_wait_duration_seconds_total", "Time blocked waiting for a connection", s.WaitDuration.Seconds())
+		w.counter("postgres_closed_connections_total", "Connections closed by reason", float64(s.MaxIdleClosed), "reason", "max_idle")
+		w.counter("postgres_closed_connections_total", "", float64(s.MaxIdleTimeClosed), "reason", "max_idle_time")
+		w.counter("postgres_closed_connections_total", "", float64(s.MaxLifetimeClosed), "reason", "max_lifetime")
+	})
+}
+
+// ObserveRedisPool exports go-redis pool statistics on every scrape
+func (m *Metrics) ObserveRedisPool(stats func() *redis.PoolStats) {
+	m.addCollector(func(w *metricsWriter) {
+		s := stats()
+		w.gauge("redis_connections", "Redis connections by state", float64(s.TotalConns), "state", "total")
+		w.gauge("redis_connections", "", float64(s.IdleConns), "state", "idle")
+		w.counter("redis_pool_hits_total", "Free connection found in the pool", float64(s.Hits))
+		w.counter("redis_pool_misses_total", "Free connection not found in the pool", float64(s.Misses))
+		w.counter("redis_pool_timeouts_total", "Pool wait timeouts", float64(s.Timeouts))
+		w.counter("redis_pool_stale_connections_total", "Stale connections removed from the pool", float64(s.StaleConns))
+	})
+}
+
+// ObserveCircuitBreaker exports a breaker's state as 0 (closed), 1 (half-open) or 2 (open)
+func (m *Metrics) ObserveCircuitBreaker(dependency string, cb *CircuitBreaker) {
+	m.addCollector(func(w *metricsWriter) {
+		value := 0.0
+		switch cb.State() {
+		case CircuitHalfOpen:
+			value = 1
+		case CircuitOpen:
+			value = 2
+		}
+		w.gauge("circuit_breaker_state", "Circuit breaker state (0 closed, 1 half-open, 2 open)", value, "dependency", dependency)
+	})
+}
+
+func (m *Metrics) addCollector(collect func(w *metricsWriter)) {
+	m.collectorsMu.Lock()
+	defer m.collectorsMu.Unlock()
+	m.collectors = append(m.collectors, collect)
+}
+
+// ServeHTTP writes all metrics in the text exposition format
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = m.WriteText(w)
+}
+
+// WriteText writes all metrics in the text exposition format
+func (m *Metrics) WriteText(out io.Writer) error {
+	w := &metricsWriter{out: bufio.NewWriter(out), instance: m.instance, described: map[string]bool{}}
+
+	m.writeCalls(w)
+
+	m.collectorsMu.Lock()
+	collectors := append([]func(w *metricsWriter){}, m.collectors...)
+	m.collectorsMu.Unlock()
+	for _, collect := range collectors {
+		collect(w)
+	}
+
+	return w.out.Flush()
+}
+
+func (m *Metrics) writeCalls(w *metricsWriter) {
+	m.mu.Lock()
+	series := make([]callSeries, 0, len(m.calls))
+	snapshot := make(map[callSeries]callStats, len(m.calls))
+	for s, stats := range m.calls {
+		series = append(series, s)
+		snapshot[s] = callStats{count: stats.count, sum: stats.sum, buckets: append([]uint64{}, stats.buckets...)}
+	}
+	m.mu.Unlock()
+
+	sort.Slice(series, func(i, j int) bool {
+		a, b := series[i], series[j]
+		if a.repository != b.repository {
+			return a.repository < b.repository
+		}
+		if a.operation != b.operation {
+			return a.operation < b.operation
+		}
+		return a.outcome < b.outcome
+	})
+
+	for _, s := range series {
+		w.counter("repository_calls_total", "Repository calls by outcome", float64(snapshot[s].count),
+			"repository", s.repository, "operation", s.operation, "outcome", s.outcome)
+	}
+
+	for _, s := range series {
+		stats := snapshot[s]
+		labels := []string{"repository", s.repository, "operation", s.operation, "outcome", s.outcome}
+		w.describe("repository_call_duration_seconds", "histogram", "Repository call latency")
+
+		cumulative := uint64(0)
+		for i, bound := range m.buckets {
+			cumulative += stats.buckets[i]
+			w.sample("repository_call_duration_seconds_bucket", float64(cumulative), append(labels, "le", formatFloat(bound))...)
+		}
+		w.sample("repository_call_duration_seconds_bucket", float64(stats.count), append(labels, "le", "+Inf")...)
+		w.sample("repository_call_duration_seconds_sum", stats.sum, labels...)
+		w.sample("repository_call_duration_seconds_count", float64(stats.count), labels...)
+	}
+}
+
+// callOutcome classifies a repository call result
+func callOutcome(err error) string {
+	switch {
+	case err == nil:
+		return OutcomeSuccess
+	case errors.Is(err, ErrCircuitOpen):
+		return OutcomeRejected
+	case errors.Is(err, interfaces.ErrNotFound), errors.Is(err, interfaces.ErrCacheMiss):
+		return OutcomeNotFound
+	case errors.Is(err, interfaces.ErrLockNotAcquired):
+		return OutcomeNotAcquired
+	}
+	return OutcomeError
+}
+
+// metricsWriter writes samples, emitting HELP and TYPE once per metric
+type metricsWriter struct {
+	out       *bufio.Writer
+	instance  string
+	described map[string]bool
+}
+
+func (w *metricsWriter) counter(name, help string, value float64, labels ...string) {
+	w.describe(name, "counter", help)
+	w.sample(name, value, labels...)
+}
+
+func (w *metricsWriter) gauge(name, help string, value float64, labels ...string) {
+	w.describe(name, "gauge", help)
+	w.sample(name, value, labels...)
+}
+
+func (w *metricsWriter) describe(name, kind, help string) {
+	if w.described[name] {
+		return
+	}
+	w.described[name] = true
+	if help != "" {
+		fmt.Fprintf(w.out, "# HELP %s%s %s\n", metricsPrefix, name, help)
+	}
+	fmt.Fprintf(w.out, "# TYPE %s%s %s\n", metricsPrefix, name, kind)
+}
+
+// sample writes one line; labels are name/value pairs
+func (w *metricsWriter) sample(name string, value float64, labels ...string) {
+	pairs := make([]string, 0, len(labels)/2+1)
+	for i := 0; i+1 < len(labels); i += 2 {
+		pairs = append(pairs, labels[i]+`="`+labelValueEscaper.Replace(labels[i+1])+`"`)
+	}
+	pairs = append(pairs, `instance="`+labelValueEscaper.Replace(w.instance)+`"`)
+	fmt.Fprintf(w.out, "%s%s{%s} %s\n", metricsPrefix, name, strings.Join(pairs, ","), formatFloat(value))
+}
+
+// labelValueEscaper applies the escaping the text format requires in label values
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
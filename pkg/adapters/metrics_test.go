@@ -0,0 +1,68 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/interfaces"
+)
+
+// TestCallOutcome tests the outcome label assigned to call results
+func TestCallOutcome(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected string
+	}{
+		{nil, OutcomeSuccess},
+		{fmt.Errorf("position %w: p1", interfaces.ErrNotFound), OutcomeNotFound},
+		{fmt.Errorf("%w: key", interfaces.ErrCacheMiss), OutcomeNotFound},
+		{fmt.Errorf("%w: %w", interfaces.ErrCacheMiss, ErrCircuitOpen), OutcomeRejected},
+		{interfaces.ErrLockNotAcquired, OutcomeNotAcquired},
+		{errors.New("connection refused"), OutcomeError},
+	}
+
+	for _, tt := range tests {
+		if got := callOutcome(tt.err); got != tt.expected {
+			t.Errorf("callOutcome(%v) = %s, expected %s", tt.err, got, tt.expected)
+		}
+	}
+}
+
+// TestMetricsExposition tests counters and histograms in the text format
+func TestMetricsExposition(t *testing.T) {
+	metrics := NewMetrics("custodian-komainu")
+	cache := NewInterceptedCacheRepository(newMemoryCache(), metrics.Interceptor())
+	ctx := context.Background()
+
+	_ = cache.Set(ctx, "key", "value", 0)
+	_, _ = cache.Get(ctx, "key")
+	_, _ = cache.Get(ctx, "missing")
+	metrics.Observe(RepositoryPosition, "GetByID", OutcomeSuccess, 3*time.Millisecond)
+
+	recorder := httptest.NewRecorder()
+	metrics.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	body := recorder.Body.String()
+
+	for _, expected := range []string{
+		"# TYPE custodian_data_adapter_repository_calls_total counter",
+		`custodian_data_adapter_repository_calls_total{repository="cache",operation="Get",outcome="not_found",instance="custodian-komainu"} 1`,
+		`custodian_data_adapter_repository_calls_total{repository="cache",operation="Set",outcome="success",instance="custodian-komainu"} 1`,
+		"# TYPE custodian_data_adapter_repository_call_duration_seconds histogram",
+		`custodian_data_adapter_repository_call_duration_seconds_bucket{repository="position",operation="GetByID",outcome="success",le="0.0025",instance="custodian-komainu"} 0`,
+		`custodian_data_adapter_repository_call_duration_seconds_bucket{repository="position",operation="GetByID",outcome="success",le="0.005",instance="custodian-komainu"} 1`,
+		`custodian_data_adapter_repository_call_duration_seconds_count{repository="position",operation="GetByID",outcome="success",instance="custodian-komainu"} 1`,
+	} {
+		if !strings.Contains(body, expected+"\n") {
+			t.Errorf("exposition missing %q:\n%s", expected, body)
+		}
+	}
+
+	if strings.Count(body, "# TYPE custodian_data_adapter_repository_calls_total") != 1 {
+		t.Errorf("TYPE line repeated:\n%s", body)
+	}
+}
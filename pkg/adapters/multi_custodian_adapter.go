@@ -0,0 +1,348 @@
+package adapters
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/interfaces"
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/models"
+)
+
+// InstancePosition is a position tagged with the custodian instance holding it
+type InstancePosition struct {
+	Instance string `json:"instance"`
+	*models.Position
+}
+
+// InstanceBalance is a balance tagged with the custodian instance holding it
+type InstanceBalance struct {
+	Instance string `json:"instance"`
+	*models.Balance
+}
+
+// InstanceFailure records an instance whose part of a read failed
+type InstanceFailure struct {
+	Instance string `json:"instance"`
+	Err      error  `json:"-"`
+}
+
+func (f InstanceFailure) Error() string {
+	return fmt.Sprintf("%s: %v", f.Instance, f.Err)
+}
+
+// AggregatedPositions is the merged result of a position read. Failures lists
+// the instances missing from Positions.
+type AggregatedPositions struct {
+	Positions []*InstancePosition `json:"positions"`
+	Failures  []InstanceFailure   `json:"failures,omitempty"`
+}
+
+// Partial reports whether some instances failed
+func (r *AggregatedPositions) Partial() bool {
+	return len(r.Failures) > 0
+}
+
+// AggregatedBalances is the merged result of a balance read. Failures lists
+// the instances missing from Balances.
+type AggregatedBalances struct {
+	Balances []*InstanceBalance `json:"balances"`
+	Failures []InstanceFailure  `json:"failures,omitempty"`
+}
+
+// Partial reports whether some instances failed
+func (r *AggregatedBalances) Partial() bool {
+	return len(r.Failures) > 0
+}
+
+// MultiCustodianAdapter gives a consolidated view over several custodian
+// instances. Reads fan out to every instance concurrently and are merged into
+// one ordered page; an instance that fails is reported in the result's
+// Failures and the call only fails when every instance does.
+type MultiCustodianAdapter struct {
+	instances map[string]DataAdapter
+	names     []string
+	logger    interfaces.Logger
+}
+
+// NewMultiCustodianAdapter creates an aggregating adapter over adapters,
+// keyed by instance name. The adapters are owned by the caller but may be
+// connected and disconnected through the aggregate.
+func NewMultiCustodianAdapter(adapters map[string]DataAdapter, logger interfaces.Logger) (*MultiCustodianAdapter, error) {
+	if len(adapters) == 0 {
+		return nil, fmt.Errorf("at least one custodian instance is required")
+	}
+
+	names := make([]string, 0, len(adapters))
+	for name, adapter := range adapters {
+		if name == "" || adapter == nil {
+			return nil, fmt.Errorf("custodian instances need a name and an adapter")
+		}
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	return &MultiCustodianAdapter{
+		instances: adapters,
+		names:     names,
+		logger:    logger,
+	}, nil
+}
+
+// Instances returns the instance names in order
+func (m *MultiCustodianAdapter) Instances() []string {
+	return slices.Clone(m.names)
+}
+
+// Adapter returns the adapter of one instance
+func (m *MultiCustodianAdapter) Adapter(instance string) (DataAdapter, bool) {
+	adapter, ok := m.instances[instance]
+	return adapter, ok
+}
+
+// Connect connects every instance, returning the failures joined
+func (m *MultiCustodianAdapter) Connect(ctx context.Context) error {
+	return m.each(ctx, "connect", DataAdapter.Connect)
+}
+
+// Disconnect disconnects every instance, returning the failures joined
+func (m *MultiCustodianAdapter) Disconnect(ctx context.Context) error {
+	return m.each(ctx, "disconnect", DataAdapter.Disconnect)
+}
+
+// HealthCheck checks every instance, returning the failures joined
+func (m *MultiCustodianAdapter) HealthCheck(ctx context.Context) error {
+	return m.each(ctx, "health check", DataAdapter.HealthCheck)
+}
+
+func (m *MultiCustodianAdapter) each(ctx context.Context, op string, fn func(DataAdapter, context.Context) error) error {
+	_, failures := fanOut(ctx, m, func(ctx context.Context, _ string, adapter DataAdapter) ([]struct{}, error) {
+		return nil, fn(adapter, ctx)
+	})
+	if len(failures) == 0 {
+		return nil
+	}
+
+	errs := make([]error, len(failures))
+	for i, failure := range failures {
+		errs[i] = failure
+	}
+	return fmt.Errorf("%s failed for %d of %d custodian instances: %w", op, len(failures), len(m.names), errors.Join(errs...))
+}
+
+// QueryPositions runs query on every instance and returns the requested page
+// of the merged results; a nil query selects everything. Results are ordered
+// as the repositories order them: by query.SortBy, or by last_updated
+// descending, with ties broken by instance.
+func (m *MultiCustodianAdapter) QueryPositions(ctx context.Context, query *models.PositionQuery) (*AggregatedPositions, error) {
+	if query == nil {
+		query = &models.PositionQuery{}
+	}
+	compare, err := positionComparator(query.SortBy, query.SortOrder)
+	if err != nil {
+		return nil, err
+	}
+
+	// Each instance must return every row that could land on the page
+	perInstance := *query
+	perInstance.Offset = 0
+	if query.Limit > 0 {
+		perInstance.Limit = query.Offset + query.Limit
+	}
+
+	merged, failures := fanOut(ctx, m, func(ctx context.Context, instance string, adapter DataAdapter) ([]*InstancePosition, error) {
+		positions, err := adapter.PositionRepository().Query(ctx, &perInstance)
+		if err != nil {
+			return nil, err
+		}
+		results := make([]*InstancePosition, len(positions))
+		for i, position := range positions {
+			results[i] = &InstancePosition{Instance: instance, Position: position}
+		}
+		return results, nil
+	})
+	result := &AggregatedPositions{Failures: failures}
+	if err := m.allFailed(ctx, "positions", failures); err != nil {
+		return result, err
+	}
+
+	slices.SortStableFunc(merged, func(a, b *InstancePosition) int {
+		if c := compare(a.Position, b.Position); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Instance, b.Instance)
+	})
+	result.Positions = paginate(merged, query.Offset, query.Limit)
+	return result, nil
+}
+
+// GetPositionsByAccount returns the account's positions across all instances
+func (m *MultiCustodianAdapter) GetPositionsByAccount(ctx context.Context, accountID string) (*AggregatedPositions, error) {
+	return m.QueryPositions(ctx, &models.PositionQuery{AccountID: &accountID})
+}
+
+// QueryBalances runs query on every instance and returns the requested page
+// of the merged results; a nil query selects everything. Results are ordered
+// by last_updated descending as the balance repositories order them, with
+// ties broken by instance.
+func (m *MultiCustodianAdapter) QueryBalances(ctx context.Context, query *models.BalanceQuery) (*AggregatedBalances, error) {
+	if query == nil {
+		query = &models.BalanceQuery{}
+	}
+	perInstance := *query
+	perInstance.Offset = 0
+	if query.Limit > 0 {
+		perInstance.Limit = query.Offset + query.Limit
+	}
+
+	merged, failures := fanOut(ctx, m, func(ctx context.Context, instance string, adapter DataAdapter) ([]*InstanceBalance, error) {
+		balances, err := adapter.BalanceRepository().Query(ctx, &perInstance)
+		if err != nil {
+			return nil, err
+		}
+		results := make([]*InstanceBalance, len(balances))
+		for i, balance := range balances {
+			results[i] = &InstanceBalance{Instance: instance, Balance: balance}
+		}
+		return results, nil
+	})
+	result := &AggregatedBalances{Failures: failures}
+	if err := m.allFailed(ctx, "balances", failures); err != nil {
+		return result, err
+	}
+
+	slices.SortStableFunc(merged, func(a, b *InstanceBalance) int {
+		if c := b.LastUpdated.Compare(a.LastUpdated); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Instance, b.Instance)
+	})
+	result.Balances = paginate(merged, query.Offset, query.Limit)
+	return result, nil
+}
+
+// GetBalancesByAccount returns the account's balances across all instances
+func (m *MultiCustodianAdapter) GetBalancesByAccount(ctx context.Context, accountID string) (*AggregatedBalances, error) {
+	return m.QueryBalances(ctx, &models.BalanceQuery{AccountID: &accountID})
+}
+
+// allFailed logs partial failures and returns an error when no instance
+// answered
+func (m *MultiCustodianAdapter) allFailed(ctx context.Context, resource string, failures []InstanceFailure) error {
+	if len(failures) == 0 {
+		return nil
+	}
+
+	errs := make([]error, len(failures))
+	for i, failure := range failures {
+		errs[i] = failure
+		contextLogger(ctx, m.logger).WithError(failure.Err).WithFields(interfaces.LogFields{
+			"instance_name": failure.Instance,
+			"resource":      resource,
+		}).Warn("Custodian instance failed in aggregated read")
+	}
+	if len(failures) < len(m.names) {
+		return nil
+	}
+	return fmt.Errorf("failed to query %s on all custodian instances: %w", resource, errors.Join(errs...))
+}
+
+// fanOut calls fn on every instance concurrently and merges the results,
+// collecting the failures in instance order
+func fanOut[T any](ctx context.Context, m *MultiCustodianAdapter, fn func(ctx context.Context, instance string, adapter DataAdapter) ([]T, error)) ([]T, []InstanceFailure) {
+	results := make([][]T, len(m.names))
+	errs := make([]error, len(m.names))
+
+	var wg sync.WaitGroup
+	for i, name := range m.names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			results[i], errs[i] = fn(ctx, name, m.instances[name])
+		}(i, name)
+	}
+	wg.Wait()
+
+	var merged []T
+	var failures []InstanceFailure
+	for i, name := range m.names {
+		if errs[i] != nil {
+			failures = append(failures, InstanceFailure{Instance: name, Err: errs[i]})
+			continue
+		}
+		merged = append(merged, results[i]...)
+	}
+	return merged, failures
+}
+
+// paginate returns the page of items at offset, up to limit items (all when
+// limit is not positive)
+func paginate[T any](items []T, offset, limit int) []T {
+	if offset >= len(items) {
+		return []T{}
+	}
+	items = items[max(offset, 0):]
+	if limit > 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
+
+// positionComparator orders positions by a repository sort column, defaulting
+// to last_updated descending like PostgresPositionRepository
+func positionComparator(sortBy, sortOrder string) (func(a, b *models.Position) int, error) {
+	if sortBy == "" {
+		return func(a, b *models.Position) int { return b.LastUpdated.Compare(a.LastUpdated) }, nil
+	}
+
+	var compare func(a, b *models.Position) int
+	switch sortBy {
+	case "position_id":
+		compare = func(a, b *models.Position) int { return strings.Compare(a.PositionID, b.PositionID) }
+	case "account_id":
+		compare = func(a, b *models.Position) int { return strings.Compare(a.AccountID, b.AccountID) }
+	case "symbol":
+		compare = func(a, b *models.Position) int { return strings.Compare(a.Symbol, b.Symbol) }
+	case "currency":
+		compare = func(a, b *models.Position) int { return strings.Compare(a.Currency, b.Currency) }
+	case "quantity":
+		compare = func(a, b *models.Position) int { return cmp.Compare(a.Quantity, b.Quantity) }
+	case "available_quantity":
+		compare = func(a, b *models.Position) int { return cmp.Compare(a.AvailableQuantity, b.AvailableQuantity) }
+	case "locked_quantity":
+		compare = func(a, b *models.Position) int { return cmp.Compare(a.LockedQuantity, b.LockedQuantity) }
+	case "average_cost":
+		compare = func(a, b *models.Position) int { return compareOptional(a.AverageCost, b.AverageCost) }
+	case "market_value":
+		compare = func(a, b *models.Position) int { return compareOptional(a.MarketValue, b.MarketValue) }
+	case "last_updated":
+		compare = func(a, b *models.Position) int { return a.LastUpdated.Compare(b.LastUpdated) }
+	case "created_at":
+		compare = func(a, b *models.Position) int { return a.CreatedAt.Compare(b.CreatedAt) }
+	default:
+		return nil, fmt.Errorf("cannot sort aggregated positions by %q", sortBy)
+	}
+
+	if strings.ToUpper(sortOrder) == "DESC" {
+		return func(a, b *models.Position) int { return compare(b, a) }, nil
+	}
+	return compare, nil
+}
+
+// compareOptional orders nil after every value, as PostgreSQL sorts NULLs
+// last in ascending order
+func compareOptional(a, b *float64) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	}
+	return cmp.Compare(*a, *b)
+}
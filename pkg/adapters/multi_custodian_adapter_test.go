@@ -0,0 +1,216 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/interfaces"
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/models"
+	"github.com/sirupsen/logrus"
+)
+
+type stubQueryPositionRepository struct {
+	interfaces.PositionRepository
+	positions []*models.Position
+	err       error
+	queries   []models.PositionQuery
+}
+
+func (r *stubQueryPositionRepository) Query(ctx context.Context, query *models.PositionQuery) ([]*models.Position, error) {
+	r.queries = append(r.queries, *query)
+	if r.err != nil {
+		return nil, r.err
+	}
+	return r.positions, nil
+}
+
+type stubQueryBalanceRepository struct {
+	interfaces.BalanceRepository
+	balances []*models.Balance
+	err      error
+}
+
+func (r *stubQueryBalanceRepository) Query(ctx context.Context, query *models.BalanceQuery) ([]*models.Balance, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	return r.balances, nil
+}
+
+type stubInstanceAdapter struct {
+	DataAdapter
+	positions *stubQueryPositionRepository
+	balances  *stubQueryBalanceRepository
+}
+
+func (a *stubInstanceAdapter) PositionRepository() interfaces.PositionRepository { return a.positions }
+func (a *stubInstanceAdapter) BalanceRepository() interfaces.BalanceRepository   { return a.balances }
+
+func newStubInstance(positions []*models.Position, balances []*models.Balance, err error) *stubInstanceAdapter {
+	return &stubInstanceAdapter{
+		positions: &stubQueryPositionRepository{positions: positions, err: err},
+		balances:  &stubQueryBalanceRepository{balances: balances, err: err},
+	}
+}
+
+// TestMultiCustodianAdapter tests merging, ordering, pagination and partial
+// failures of aggregated reads
+func TestMultiCustodianAdapter(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	position := func(id string, quantity float64, minutes int) *models.Position {
+		return &models.Position{PositionID: id, Quantity: quantity, LastUpdated: base.Add(time.Duration(minutes) * time.Minute)}
+	}
+	balance := func(id string, minutes int) *models.Balance {
+		return &models.Balance{BalanceID: id, LastUpdated: base.Add(time.Duration(minutes) * time.Minute)}
+	}
+
+	komainu := []*models.Position{position("k1", 5, 30), position("k2", 1, 10)}
+	copper := []*models.Position{position("c1", 3, 20), position("c2", 9, 5)}
+	down := errors.New("connection refused")
+
+	tests := []struct {
+		name      string
+		instances map[string]*stubInstanceAdapter
+		query     models.PositionQuery
+		expected  []string
+		failed    []string
+		errorMsg  string
+	}{
+		{
+			name: "merged by last updated",
+			instances: map[string]*stubInstanceAdapter{
+				"komainu": newStubInstance(komainu, nil, nil),
+				"copper":  newStubInstance(copper, nil, nil),
+			},
+			expected: []string{"komainu/k1", "copper/c1", "komainu/k2", "copper/c2"},
+		},
+		{
+			name: "sorted and paginated",
+			instances: map[string]*stubInstanceAdapter{
+				"komainu": newStubInstance(komainu, nil, nil),
+				"copper":  newStubInstance(copper, nil, nil),
+			},
+			query:    models.PositionQuery{SortBy: "quantity", SortOrder: "desc", Offset: 1, Limit: 2},
+			expected: []string{"komainu/k1", "copper/c1"},
+		},
+		{
+			name: "partial failure",
+			instances: map[string]*stubInstanceAdapter{
+				"komainu":    newStubInstance(komainu, nil, nil),
+				"fireblocks": newStubInstance(nil, nil, down),
+			},
+			expected: []string{"komainu/k1", "komainu/k2"},
+			failed:   []string{"fireblocks"},
+		},
+		{
+			name: "all instances failed",
+			instances: map[string]*stubInstanceAdapter{
+				"komainu":    newStubInstance(nil, nil, down),
+				"fireblocks": newStubInstance(nil, nil, down),
+			},
+			failed:   []string{"fireblocks", "komainu"},
+			errorMsg: "failed to query positions on all custodian instances",
+		},
+		{
+			name: "unknown sort column",
+			instances: map[string]*stubInstanceAdapter{
+				"komainu": newStubInstance(komainu, nil, nil),
+			},
+			query:    models.PositionQuery{SortBy: "quantity; DROP TABLE positions"},
+			errorMsg: "cannot sort aggregated positions",
+		},
+	}
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapters := make(map[string]DataAdapter, len(tt.instances))
+			for name, instance := range tt.instances {
+				adapters[name] = instance
+			}
+			multi, err := NewMultiCustodianAdapter(adapters, NewLogrusLogger(logger))
+			if err != nil {
+				t.Fatalf("NewMultiCustodianAdapter failed: %v", err)
+			}
+
+			result, err := multi.QueryPositions(context.Background(), &tt.query)
+			if tt.errorMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+					t.Fatalf("QueryPositions error = %v, expected %q", err, tt.errorMsg)
+				}
+			} else if err != nil {
+				t.Fatalf("QueryPositions failed: %v", err)
+			}
+			if result == nil {
+				return
+			}
+
+			var got []string
+			for _, p := range result.Positions {
+				got = append(got, p.Instance+"/"+p.PositionID)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("positions = %v, expected %v", got, tt.expected)
+			}
+
+			var failed []string
+			for _, f := range result.Failures {
+				failed = append(failed, f.Instance)
+			}
+			if !reflect.DeepEqual(failed, tt.failed) {
+				t.Errorf("failures = %v, expected %v", failed, tt.failed)
+			}
+			if result.Partial() != (len(tt.failed) > 0) {
+				t.Errorf("Partial() = %v", result.Partial())
+			}
+		})
+	}
+
+	t.Run("instances fetch the whole prefix of the page", func(t *testing.T) {
+		instance := newStubInstance(komainu, nil, nil)
+		multi, _ := NewMultiCustodianAdapter(map[string]DataAdapter{"komainu": instance}, NewLogrusLogger(logger))
+		if _, err := multi.QueryPositions(context.Background(), &models.PositionQuery{Offset: 10, Limit: 5}); err != nil {
+			t.Fatalf("QueryPositions failed: %v", err)
+		}
+		if q := instance.positions.queries[0]; q.Offset != 0 || q.Limit != 15 {
+			t.Errorf("instance query offset = %d, limit = %d, expected 0 and 15", q.Offset, q.Limit)
+		}
+	})
+
+	t.Run("nil query", func(t *testing.T) {
+		multi, _ := NewMultiCustodianAdapter(map[string]DataAdapter{"komainu": newStubInstance(komainu, nil, nil)}, NewLogrusLogger(logger))
+		positions, err := multi.QueryPositions(context.Background(), nil)
+		if err != nil || len(positions.Positions) != 2 {
+			t.Errorf("QueryPositions(nil) = %v, %v", positions, err)
+		}
+		if _, err := multi.QueryBalances(context.Background(), nil); err != nil {
+			t.Errorf("QueryBalances(nil) failed: %v", err)
+		}
+	})
+
+	t.Run("balances", func(t *testing.T) {
+		multi, _ := NewMultiCustodianAdapter(map[string]DataAdapter{
+			"komainu": newStubInstance(nil, []*models.Balance{balance("k1", 10)}, nil),
+			"copper":  newStubInstance(nil, []*models.Balance{balance("c1", 20), balance("c2", 10)}, nil),
+		}, NewLogrusLogger(logger))
+
+		result, err := multi.QueryBalances(context.Background(), &models.BalanceQuery{Limit: 2})
+		if err != nil {
+			t.Fatalf("QueryBalances failed: %v", err)
+		}
+		var got []string
+		for _, b := range result.Balances {
+			got = append(got, b.Instance+"/"+b.BalanceID)
+		}
+		if expected := []string{"copper/c1", "copper/c2"}; !reflect.DeepEqual(got, expected) {
+			t.Errorf("balances = %v, expected %v", got, expected)
+		}
+	})
+}
@@ -0,0 +1,226 @@
+package adapters
+
+import (
+	"database/sql"
+
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/interfaces"
+	"github.com/redis/go-redis/v9"
+)
+
+// Option customizes NewCustodianDataAdapter
+type Option func(*adapterOptions)
+
+type adapterOptions struct {
+	logger interfaces.Logger
+	tracer interfaces.Tracer
+
+	// Existing clients, owned by the caller
+	db          *sql.DB
+	redisClient redis.UniversalClient
+
+	// Rotating credentials for connections the adapter opens
+	postgresCredentials interfaces.CredentialsProvider
+	redisCredentials    interfaces.CredentialsProvider
+
+	// Repositories replacing the backend's
+	overrides Repositories
+
+	// Decorators applied last, in the order given
+	positionDecorators         []func(interfaces.PositionRepository) interfaces.PositionRepository
+	settlementDecorators       []func(interfaces.SettlementRepository) interfaces.SettlementRepository
+	balanceDecorators          []func(interfaces.BalanceRepository) interfaces.BalanceRepository
+	accountDecorators          []func(interfaces.AccountRepository) interfaces.AccountRepository
+	assetDecorators            []func(interfaces.AssetRepository) interfaces.AssetRepository
+	serviceDiscoveryDecorators []func(interfaces.ServiceDiscoveryRepository) interfaces.ServiceDiscoveryRepository
+	cacheDecorators            []func(interfaces.CacheRepository) interfaces.CacheRepository
+	lockDecorators             []func(interfaces.LockRepository) interfaces.LockRepository
+}
+
+// WithLogger sets the logger (slog.Default() if not given)
+func WithLogger(logger interfaces.Logger) Option {
+	return func(o *adapterOptions) { o.logger = logger }
+}
+
+// WithTracer sets the tracer invoked around every repository call
+func WithTracer(tracer interfaces.Tracer) Option {
+	return func(o *adapterOptions) { o.tracer = tracer }
+}
+
+// WithPostgresDB uses an existing PostgreSQL pool instead of opening one from
+// PostgresURL. The pool is not closed on Disconnect.
+func WithPostgresDB(db *sql.DB) Option {
+	return func(o *adapterOptions) { o.db = db }
+}
+
+// WithRedisClient uses an existing Redis client, which may be a sentinel
+// failover or cluster client, instead of creating one from RedisURL. The
+// client is not closed on Disconnect.
+func WithRedisClient(client redis.UniversalClient) Option {
+	return func(o *adapterOptions) { o.redisClient = client }
+}
+
+// WithPostgresCredentials authenticates PostgreSQL connections with the
+// provider's credentials instead of those in PostgresURL
+func WithPostgresCredentials(provider interfaces.CredentialsProvider) Option {
+	return func(o *adapterOptions) { o.postgresCredentials = provider }
+}
+
+// WithRedisCredentials authenticates Redis connections with the provider's
+// credentials instead of those in RedisURL
+func WithRedisCredentials(provider interfaces.CredentialsProvider) Option {
+	return func(o *adapterOptions) { o.redisCredentials = provider }
+}
+
+// WithPositionRepository replaces the backend's position repository
+func WithPositionRepository(repo interfaces.PositionRepository) Option {
+	return func(o *adapterOptions) { o.overrides.Position = repo }
+}
+
+// WithSettlementRepository replaces the backend's settlement repository
+func WithSettlementRepository(repo interfaces.SettlementRepository) Option {
+	return func(o *adapterOptions) { o.overrides.Settlement = repo }
+}
+
+// WithBalanceRepository replaces the backend's balance repository
+func WithBalanceRepository(repo interfaces.BalanceRepository) Option {
+	return func(o *adapterOptions) { o.overrides.Balance = repo }
+}
+
+// WithAccountRepository replaces the backend's account repository
+func WithAccountRepository(repo interfaces.AccountRepository) Option {
+	return func(o *adapterOptions) { o.overrides.Account = repo }
+}
+
+// WithAssetRepository replaces the backend's asset repository
+func WithAssetRepository(repo interfaces.AssetRepository) Option {
+	return func(o *adapterOptions) { o.overrides.Asset = repo }
+}
+
+// WithServiceDiscoveryRepository replaces the backend's service discovery repository
+func WithServiceDiscoveryRepository(repo interfaces.ServiceDiscoveryRepository) Option {
+	return func(o *adapterOptions) { o.overrides.ServiceDiscovery = repo }
+}
+
+// WithCacheRepository replaces the backend's cache repository; the local
+// cache tier is not used with a replaced cache
+func WithCacheRepository(repo interfaces.CacheRepository) Option {
+	return func(o *adapterOptions) { o.overrides.Cache = repo }
+}
+
+// WithLockRepository replaces the backend's lock repository
+func WithLockRepository(repo interfaces.LockRepository) Option {
+	return func(o *adapterOptions) { o.overrides.Lock = repo }
+}
+
+// WithPositionDecorator wraps the position repository after overrides and
+// built-in caching are applied; later decorators wrap earlier ones
+func WithPositionDecorator(decorate func(interfaces.PositionRepository) interfaces.PositionRepository) Option {
+	return func(o *adapterOptions) { o.positionDecorators = append(o.positionDecorators, decorate) }
+}
+
+// WithSettlementDecorator wraps the settlement repository
+func WithSettlementDecorator(decorate func(interfaces.SettlementRepository) interfaces.SettlementRepository) Option {
+	return func(o *adapterOptions) { o.settlementDecorators = append(o.settlementDecorators, decorate) }
+}
+
+// WithBalanceDecorator wraps the balance repository
+func WithBalanceDecorator(decorate func(interfaces.BalanceRepository) interfaces.BalanceRepository) Option {
+	return func(o *adapterOptions) { o.balanceDecorators = append(o.balanceDecorators, decorate) }
+}
+
+// WithAccountDecorator wraps the account repository
+func WithAccountDecorator(decorate func(interfaces.AccountRepository) interfaces.AccountRepository) Option {
+	return func(o *adapterOptions) { o.accountDecorators = append(o.accountDecorators, decorate) }
+}
+
+// WithAssetDecorator wraps the asset repository
+func WithAssetDecorator(decorate func(interfaces.AssetRepository) interfaces.AssetRepository) Option {
+	return func(o *adapterOptions) { o.assetDecorators = append(o.assetDecorators, decorate) }
+}
+
+// WithServiceDiscoveryDecorator wraps the service discovery repository
+func WithServiceDiscoveryDecorator(decorate func(interfaces.ServiceDiscoveryRepository) interfaces.ServiceDiscoveryRepository) Option {
+	return func(o *adapterOptions) { o.serviceDiscoveryDecorators = append(o.serviceDiscoveryDecorators, decorate) }
+}
+
+// WithCacheDecorator wraps the cache repository
+func WithCacheDecorator(decorate func(interfaces.CacheRepository) interfaces.CacheRepository) Option {
+	return func(o *adapterOptions) { o.cacheDecorators = append(o.cacheDecorators, decorate) }
+}
+
+// WithLockDecorator wraps the lock repository
+func WithLockDecorator(decorate func(interfaces.LockRepository) interfaces.LockRepository) Option {
+	return func(o *adapterOptions) { o.lockDecorators = append(o.lockDecorators, decorate) }
+}
+
+// applyOverrides replaces repositories supplied through options
+func (r *Repositories) applyOverrides(overrides Repositories) {
+	if overrides.Position != nil {
+		r.Position = overrides.Position
+	}
+	if overrides.Settlement != nil {
+		r.Settlement = overrides.Settlement
+	}
+	if overrides.Balance != nil {
+		r.Balance = overrides.Balance
+	}
+	if overrides.Account != nil {
+		r.Account = overrides.Account
+	}
+	if overrides.Asset != nil {
+		r.Asset = overrides.Asset
+	}
+	if overrides.ServiceDiscovery != nil {
+		r.ServiceDiscovery = overrides.ServiceDiscovery
+	}
+	if overrides.Cache != nil {
+		r.Cache = overrides.Cache
+	}
+	if overrides.Lock != nil {
+		r.Lock = overrides.Lock
+	}
+}
+
+// applyDecorators wraps every configured repository with the option decorators
+func (r *Repositories) applyDecorators(o *adapterOptions) {
+	for _, decorate := range o.positionDecorators {
+		if r.Position != nil {
+			r.Position = decorate(r.Position)
+		}
+	}
+	for _, decorate := range o.settlementDecorators {
+		if r.Settlement != nil {
+			r.Settlement = decorate(r.Settlement)
+		}
+	}
+	for _, decorate := range o.balanceDecorators {
+		if r.Balance != nil {
+			r.Balance = decorate(r.Balance)
+		}
+	}
+	for _, decorate := range o.accountDecorators {
+		if r.Account != nil {
+			r.Account = decorate(r.Account)
+		}
+	}
+	for _, decorate := range o.assetDecorators {
+		if r.Asset != nil {
+			r.Asset = decorate(r.Asset)
+		}
+	}
+	for _, decorate := range o.serviceDiscoveryDecorators {
+		if r.ServiceDiscovery != nil {
+			r.ServiceDiscovery = decorate(r.ServiceDiscovery)
+		}
+	}
+	for _, decorate := range o.cacheDecorators {
+		if r.Cache != nil {
+			r.Cache = decorate(r.Cache)
+		}
+	}
+	for _, decorate := range o.lockDecorators {
+		if r.Lock != nil {
+			r.Lock = decorate(r.Lock)
+		}
+	}
+}
@@ -0,0 +1,427 @@
+package adapters
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/interfaces"
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/models"
+)
+
+const accountColumns = `account_id, account_type, parent_account_id, status, base_currency,
+		created_at, updated_at, closed_at, metadata`
+
+// PostgresAccountRepository stores accounts and their hierarchy. Sub-accounts
+// can only be opened under, or moved to, a parent that is not closed; a
+// parent cannot be closed while it has open sub-accounts, and closed accounts
+// cannot be changed or reopened.
+type PostgresAccountRepository struct {
+	db     *sql.DB
+	reads  *readRouter
+	table  string
+	logger interfaces.Logger
+}
+
+func NewPostgresAccountRepository(db *sql.DB, logger interfaces.Logger) interfaces.AccountRepository {
+	return newPostgresAccountRepository(db, newPrimaryReads(db), defaultSchemaName, logger)
+}
+
+// newPostgresAccountRepository uses the accounts table in schema and sends
+// read-only queries through reads
+func newPostgresAccountRepository(db *sql.DB, reads *readRouter, schema string, logger interfaces.Logger) *PostgresAccountRepository {
+	return &PostgresAccountRepository{
+		db:     db,
+		reads:  reads,
+		table:  qualifiedTable(schema, "accounts"),
+		logger: logger,
+	}
+}
+
+func (r *PostgresAccountRepository) Create(ctx context.Context, account *models.Account) error {
+	if account.Status == "" {
+		account.Status = models.AccountStatusActive
+	}
+	if err := validateAccount(account); err != nil {
+		return err
+	}
+	if account.Status == models.AccountStatusClosed {
+		return accountErrorf("%w: account %s cannot be created closed", interfaces.ErrInvalidAccount, accountRef(account.AccountID))
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create account: %w", err)
+	}
+	defer tx.Rollback()
+
+	if account.ParentAccountID != nil {
+		if err := r.checkParent(ctx, tx, account.AccountID, *account.ParentAccountID); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	account.CreatedAt, account.UpdatedAt, account.ClosedAt = now, now, nil
+
+	query := fmt.Sprintf(`
+		INSERT INTO %s (%s)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, r.table, accountColumns)
+
+	result, err := tx.ExecContext(ctx, query,
+		account.AccountID, account.AccountType, account.ParentAccountID, account.Status, account.BaseCurrency,
+		account.CreatedAt, account.UpdatedAt, account.ClosedAt, account.Metadata,
+	)
+	if err != nil {
+		contextLogger(ctx, r.logger).WithError(err).WithField("account_id", account.AccountID).Error("Failed to create account")
+		return fmt.Errorf("failed to create account: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to create account: %w", err)
+	}
+
+	recordResult(ctx, result)
+	return nil
+}
+
+func (r *PostgresAccountRepository) GetByID(ctx context.Context, accountID string) (*models.Account, error) {
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE account_id = $1`, accountColumns, r.table)
+
+	account, err := scanAccount(r.reads.QueryRowContext(ctx, query, accountID))
+	if err == sql.ErrNoRows {
+		return nil, accountErrorf("account %w: %s", interfaces.ErrNotFound, accountRef(accountID))
+	}
+	if err != nil {
+		contextLogger(ctx, r.logger).WithError(err).Error("Failed to get account")
+		return nil, fmt.Errorf("failed to get account: %w", err)
+	}
+
+	return account, nil
+}
+
+func (r *PostgresAccountRepository) Query(ctx context.Context, query *models.AccountQuery) ([]*models.Account, error) {
+	sqlQuery := fmt.Sprintf(`SELECT %s FROM %s WHERE 1=1`, accountColumns, r.table)
+
+	args := []interface{}{}
+	argCount := 1
+
+	if query.AccountType != nil {
+		sqlQuery += fmt.Sprintf(" AND account_type = $%d", argCount)
+		args = append(args, *query.AccountType)
+		argCount++
+	}
+
+	if query.Status != nil {
+		sqlQuery += fmt.Sprintf(" AND status = $%d", argCount)
+		args = append(args, *query.Status)
+		argCount++
+	}
+
+	if query.ParentAccountID != nil {
+		sqlQuery += fmt.Sprintf(" AND parent_account_id = $%d", argCount)
+		args = append(args, *query.ParentAccountID)
+		argCount++
+	}
+
+	if query.BaseCurrency != nil {
+		sqlQuery += fmt.Sprintf(" AND base_currency = $%d", argCount)
+		args = append(args, *query.BaseCurrency)
+		argCount++
+	}
+
+	sqlQuery += " ORDER BY account_id"
+
+	if query.Limit > 0 {
+		sqlQuery += fmt.Sprintf(" LIMIT $%d", argCount)
+		args = append(args, query.Limit)
+		argCount++
+	}
+
+	if query.Offset > 0 {
+		sqlQuery += fmt.Sprintf(" OFFSET $%d", argCount)
+		args = append(args, query.Offset)
+	}
+
+	return r.queryAccounts(ctx, sqlQuery, args...)
+}
+
+func (r *PostgresAccountRepository) Update(ctx context.Context, account *models.Account) error {
+	if err := validateAccount(account); err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to update account: %w", err)
+	}
+	defer tx.Rollback()
+
+	status, err := r.lockAccount(ctx, tx, account.AccountID)
+	if err != nil {
+		return err
+	}
+	if status == models.AccountStatusClosed {
+		return accountErrorf("%w: account %s is closed", interfaces.ErrInvalidAccount, accountRef(account.AccountID))
+	}
+	if account.ParentAccountID != nil {
+		if err := r.checkParent(ctx, tx, account.AccountID, *account.ParentAccountID); err != nil {
+			return err
+		}
+	}
+
+	query := fmt.Sprintf(`
+		UPDATE %s
+		SET account_type = $2, parent_account_id = $3, base_currency = $4, metadata = $5, updated_at = $6
+		WHERE account_id = $1
+		RETURNING status, created_at, closed_at
+	`, r.table)
+
+	account.UpdatedAt = time.Now()
+
+	err = tx.QueryRowContext(ctx, query,
+		account.AccountID, account.AccountType, account.ParentAccountID, account.BaseCurrency,
+		account.Metadata, account.UpdatedAt,
+	).Scan(&account.Status, &account.CreatedAt, &account.ClosedAt)
+	if err != nil {
+		contextLogger(ctx, r.logger).WithError(err).WithField("account_id", account.AccountID).Error("Failed to update account")
+		return fmt.Errorf("failed to update account: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to update account: %w", err)
+	}
+
+	recordRowsAffected(ctx, 1)
+	return nil
+}
+
+func (r *PostgresAccountRepository) UpdateStatus(ctx context.Context, accountID string, status models.AccountStatus) error {
+	if !validAccountStatus(status) {
+		return fmt.Errorf("%w: unknown status %q", interfaces.ErrInvalidAccount, status)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to update account status: %w", err)
+	}
+	defer tx.Rollback()
+
+	current, err := r.lockAccount(ctx, tx, accountID)
+	if err != nil {
+		return err
+	}
+	if current == status {
+		return nil
+	}
+	if current == models.AccountStatusClosed {
+		return accountErrorf("%w: account %s is closed and cannot be reopened", interfaces.ErrInvalidAccount, accountRef(accountID))
+	}
+
+	if status == models.AccountStatusClosed {
+		var openChildren bool
+		query := fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM %s WHERE parent_account_id = $1 AND status <> $2)`, r.table)
+		if err := tx.QueryRowContext(ctx, query, accountID, models.AccountStatusClosed).Scan(&openChildren); err != nil {
+			return fmt.Errorf("failed to check sub-accounts: %w", err)
+		}
+		if openChildren {
+			return accountErrorf("%w: account %s has open sub-accounts", interfaces.ErrInvalidAccount, accountRef(accountID))
+		}
+	}
+
+	query := fmt.Sprintf(`
+		UPDATE %s
+		SET status = $2, updated_at = NOW(), closed_at = CASE WHEN $2 = 'CLOSED' THEN NOW() END
+		WHERE account_id = $1
+	`, r.table)
+
+	result, err := tx.ExecContext(ctx, query, accountID, status)
+	if err != nil {
+		contextLogger(ctx, r.logger).WithError(err).Error("Failed to update account status")
+		return fmt.Errorf("failed to update account status: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to update account status: %w", err)
+	}
+
+	recordResult(ctx, result)
+	contextLogger(ctx, r.logger).WithFields(interfaces.LogFields{
+		"account_id": accountID,
+		"from":       current,
+		"to":         status,
+	}).Info("Account status changed")
+	return nil
+}
+
+func (r *PostgresAccountRepository) GetChildren(ctx context.Context, accountID string) ([]*models.Account, error) {
+	return r.Query(ctx, &models.AccountQuery{ParentAccountID: &accountID})
+}
+
+func (r *PostgresAccountRepository) GetDescendants(ctx context.Context, accountID string) ([]*models.Account, error) {
+	query := fmt.Sprintf(`
+		WITH RECURSIVE descendants AS (
+			SELECT %[1]s, 1 AS depth FROM %[2]s WHERE parent_account_id = $1
+			UNION ALL
+			SELECT a.account_id, a.account_type, a.parent_account_id, a.status, a.base_currency,
+				   a.created_at, a.updated_at, a.closed_at, a.metadata, d.depth + 1
+			FROM %[2]s a JOIN descendants d ON a.parent_account_id = d.account_id
+		)
+		SELECT %[1]s FROM descendants ORDER BY depth, account_id
+	`, accountColumns, r.table)
+
+	return r.queryAccounts(ctx, query, accountID)
+}
+
+// lockAccount locks the account row for the rest of tx and returns its status
+func (r *PostgresAccountRepository) lockAccount(ctx context.Context, tx *sql.Tx, accountID string) (models.AccountStatus, error) {
+	var status models.AccountStatus
+	query := fmt.Sprintf(`SELECT status FROM %s WHERE account_id = $1 FOR UPDATE`, r.table)
+	err := tx.QueryRowContext(ctx, query, accountID).Scan(&status)
+	if err == sql.ErrNoRows {
+		return "", accountErrorf("account %w: %s", interfaces.ErrNotFound, accountRef(accountID))
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to lock account: %w", err)
+	}
+	return status, nil
+}
+
+// checkParent verifies that parentID can hold accountID as a sub-account: it
+// exists, is not closed and is not accountID or one of its sub-accounts. The
+// parent stays locked against closing for the rest of tx.
+func (r *PostgresAccountRepository) checkParent(ctx context.Context, tx *sql.Tx, accountID, parentID string) error {
+	if parentID == accountID {
+		return accountErrorf("%w: account %s cannot be its own parent", interfaces.ErrInvalidAccount, accountRef(accountID))
+	}
+
+	var status models.AccountStatus
+	query := fmt.Sprintf(`SELECT status FROM %s WHERE account_id = $1 FOR SHARE`, r.table)
+	err := tx.QueryRowContext(ctx, query, parentID).Scan(&status)
+	if err == sql.ErrNoRows {
+		return accountErrorf("%w: unknown parent account %s", interfaces.ErrInvalidAccount, accountRef(parentID))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check parent account: %w", err)
+	}
+	if status == models.AccountStatusClosed {
+		return accountErrorf("%w: parent account %s is closed", interfaces.ErrInvalidAccount, accountRef(parentID))
+	}
+
+	// Walk up from the parent; meeting the account itself would close a cycle
+	var cycle bool
+	query = fmt.Sprintf(`
+		WITH RECURSIVE ancestors AS (
+			SELECT account_id, parent_account_id FROM %[1]s WHERE account_id = $1
+			UNION
+			SELECT a.account_id, a.parent_account_id
+			FROM %[1]s a JOIN ancestors p ON a.account_id = p.parent_account_id
+		)
+		SELECT EXISTS (SELECT 1 FROM ancestors WHERE account_id = $2)
+	`, r.table)
+	if err := tx.QueryRowContext(ctx, query, parentID, accountID).Scan(&cycle); err != nil {
+		return fmt.Errorf("failed to check account hierarchy: %w", err)
+	}
+	if cycle {
+		return accountErrorf("%w: account %s is an ancestor of %s", interfaces.ErrInvalidAccount, accountRef(accountID), accountRef(parentID))
+	}
+	return nil
+}
+
+func (r *PostgresAccountRepository) queryAccounts(ctx context.Context, query string, args ...interface{}) ([]*models.Account, error) {
+	rows, err := r.reads.QueryContext(ctx, query, args...)
+	if err != nil {
+		contextLogger(ctx, r.logger).WithError(err).Error("Failed to query accounts")
+		return nil, fmt.Errorf("failed to query accounts: %w", err)
+	}
+	defer rows.Close()
+
+	accounts := []*models.Account{}
+	for rows.Next() {
+		account, err := scanAccount(rows)
+		if err != nil {
+			contextLogger(ctx, r.logger).WithError(err).Error("Failed to scan account")
+			return nil, fmt.Errorf("failed to scan account: %w", err)
+		}
+		accounts = append(accounts, account)
+	}
+
+	recordRowsReturned(ctx, len(accounts))
+	return accounts, nil
+}
+
+// execForAccount runs a position or settlement write that must reference an
+// open account. With checks off (accounts is empty) it runs directly;
+// otherwise it runs in a transaction that first share-locks the account row
+// and fails with ErrInvalidAccount if the account is unknown or closed. The
+// lock is held until the write commits, so a concurrent close either waits
+// for it or has already committed and is seen.
+func execForAccount(ctx context.Context, db *sql.DB, accounts, accountID, query string, args ...interface{}) (sql.Result, error) {
+	if accounts == "" {
+		return db.ExecContext(ctx, query, args...)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var status models.AccountStatus
+	lock := fmt.Sprintf(`SELECT status FROM %s WHERE account_id = $1 FOR SHARE`, accounts)
+	err = tx.QueryRowContext(ctx, lock, accountID).Scan(&status)
+	if err == sql.ErrNoRows {
+		return nil, accountErrorf("%w: unknown account %s", interfaces.ErrInvalidAccount, accountRef(accountID))
+	}
+	if err != nil {
+		return nil, accountErrorf("failed to check account %s: %w", accountRef(accountID), err)
+	}
+	if status == models.AccountStatusClosed {
+		return nil, accountErrorf("%w: account %s is closed", interfaces.ErrInvalidAccount, accountRef(accountID))
+	}
+
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return result, tx.Commit()
+}
+
+func scanAccount(row interface{ Scan(...interface{}) error }) (*models.Account, error) {
+	account := &models.Account{}
+	err := row.Scan(
+		&account.AccountID, &account.AccountType, &account.ParentAccountID, &account.Status, &account.BaseCurrency,
+		&account.CreatedAt, &account.UpdatedAt, &account.ClosedAt, &account.Metadata,
+	)
+	return account, err
+}
+
+// validateAccount checks the fields the caller supplies
+func validateAccount(account *models.Account) error {
+	switch {
+	case account.AccountID == "":
+		return fmt.Errorf("%w: account ID is required", interfaces.ErrInvalidAccount)
+	case !validAccountType(account.AccountType):
+		return fmt.Errorf("%w: unknown account type %q", interfaces.ErrInvalidAccount, account.AccountType)
+	case account.Status != "" && !validAccountStatus(account.Status):
+		return fmt.Errorf("%w: unknown status %q", interfaces.ErrInvalidAccount, account.Status)
+	case account.BaseCurrency == "":
+		return fmt.Errorf("%w: base currency is required", interfaces.ErrInvalidAccount)
+	}
+	return nil
+}
+
+func validAccountType(accountType models.AccountType) bool {
+	switch accountType {
+	case models.AccountTypeClient, models.AccountTypeOmnibus, models.AccountTypeHouse, models.AccountTypeFee:
+		return true
+	}
+	return false
+}
+
+func validAccountStatus(status models.AccountStatus) bool {
+	switch status {
+	case models.AccountStatusActive, models.AccountStatusFrozen, models.AccountStatusClosed:
+		return true
+	}
+	return false
+}
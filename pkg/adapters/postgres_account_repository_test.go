@@ -0,0 +1,127 @@
+package adapters
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/interfaces"
+)
+
+// scriptedDatabase answers every query with status (no rows if empty) and
+// records the statements run, marking commits and rollbacks
+type scriptedDatabase struct {
+	status     string
+	statements []string
+}
+
+func (d *scriptedDatabase) Connect(ctx context.Context) (driver.Conn, error) {
+	return &scriptedConn{d}, nil
+}
+func (d *scriptedDatabase) Driver() driver.Driver { return nil }
+
+type scriptedConn struct {
+	db *scriptedDatabase
+}
+
+func (c *scriptedConn) Prepare(query string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (c *scriptedConn) Close() error                              { return nil }
+func (c *scriptedConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *scriptedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	c.db.statements = append(c.db.statements, "BEGIN")
+	return scriptedTx{c.db}, nil
+}
+
+func (c *scriptedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.db.statements = append(c.db.statements, strings.TrimSpace(query))
+	if c.db.status == "" {
+		return &fakeRows{read: true}, nil
+	}
+	return &fakeRows{value: c.db.status}, nil
+}
+
+func (c *scriptedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.db.statements = append(c.db.statements, strings.TrimSpace(query))
+	return driver.RowsAffected(1), nil
+}
+
+type scriptedTx struct {
+	db *scriptedDatabase
+}
+
+func (t scriptedTx) Commit() error {
+	t.db.statements = append(t.db.statements, "COMMIT")
+	return nil
+}
+
+func (t scriptedTx) Rollback() error {
+	t.db.statements = append(t.db.statements, "ROLLBACK")
+	return nil
+}
+
+// TestExecForAccount tests that account-checked writes lock the account row
+// in the write's transaction and never run for unknown or closed accounts
+func TestExecForAccount(t *testing.T) {
+	const write = "INSERT INTO positions"
+	lock := `SELECT status FROM "custodian".accounts WHERE account_id = $1 FOR SHARE`
+
+	tests := []struct {
+		name       string
+		accounts   string
+		status     string
+		errorMsg   string
+		statements []string
+	}{
+		{name: "checks disabled", statements: []string{write}},
+		{
+			name:       "open account",
+			accounts:   qualifiedTable(defaultSchemaName, "accounts"),
+			status:     "ACTIVE",
+			statements: []string{"BEGIN", lock, write, "COMMIT"},
+		},
+		{
+			name:       "frozen account",
+			accounts:   qualifiedTable(defaultSchemaName, "accounts"),
+			status:     "FROZEN",
+			statements: []string{"BEGIN", lock, write, "COMMIT"},
+		},
+		{
+			name:       "closed account",
+			accounts:   qualifiedTable(defaultSchemaName, "accounts"),
+			status:     "CLOSED",
+			errorMsg:   "account ACC-1 is closed",
+			statements: []string{"BEGIN", lock, "ROLLBACK"},
+		},
+		{
+			name:       "unknown account",
+			accounts:   qualifiedTable(defaultSchemaName, "accounts"),
+			errorMsg:   "unknown account ACC-1",
+			statements: []string{"BEGIN", lock, "ROLLBACK"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			database := &scriptedDatabase{status: tt.status}
+			_, err := execForAccount(context.Background(), sql.OpenDB(database), tt.accounts, "ACC-1", write)
+
+			if tt.errorMsg != "" {
+				if !errors.Is(err, interfaces.ErrInvalidAccount) || !strings.Contains(err.Error(), tt.errorMsg) {
+					t.Errorf("error = %v, expected %q", err, tt.errorMsg)
+				}
+			} else if err != nil {
+				t.Errorf("write failed: %v", err)
+			}
+
+			if strings.Join(database.statements, "\n") != strings.Join(tt.statements, "\n") {
+				t.Errorf("statements = %q, expected %q", database.statements, tt.statements)
+			}
+		})
+	}
+}
@@ -0,0 +1,340 @@
+package adapters
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/interfaces"
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/models"
+)
+
+// maxAssetDecimals is the scale of the quantity and balance columns
+const maxAssetDecimals = 8
+
+// PostgresAssetRepository stores asset reference data. Symbols and aliases
+// are kept in upper case and share one namespace: no alias may name another
+// asset or another asset's alias.
+type PostgresAssetRepository struct {
+	db      *sql.DB
+	reads   *readRouter
+	table   string
+	aliases string
+	logger  interfaces.Logger
+}
+
+func NewPostgresAssetRepository(db *sql.DB, logger interfaces.Logger) interfaces.AssetRepository {
+	return newPostgresAssetRepository(db, newPrimaryReads(db), defaultSchemaName, logger)
+}
+
+// newPostgresAssetRepository uses the asset tables in schema and sends
+// read-only queries through reads
+func newPostgresAssetRepository(db *sql.DB, reads *readRouter, schema string, logger interfaces.Logger) *PostgresAssetRepository {
+	return &PostgresAssetRepository{
+		db:      db,
+		reads:   reads,
+		table:   qualifiedTable(schema, "assets"),
+		aliases: qualifiedTable(schema, "asset_aliases"),
+		logger:  logger,
+	}
+}
+
+// selectAssets selects assets, aliased a, with their aliases
+func (r *PostgresAssetRepository) selectAssets() string {
+	return fmt.Sprintf(`
+		SELECT a.symbol, ARRAY(SELECT alias FROM %s l WHERE l.symbol = a.symbol ORDER BY alias),
+			   a.asset_class, a.decimals, a.network, a.settlement_days, a.enabled, a.created_at, a.updated_at, a.metadata
+		FROM %s a
+	`, r.aliases, r.table)
+}
+
+func (r *PostgresAssetRepository) Create(ctx context.Context, asset *models.Asset) error {
+	if err := normalizeAsset(asset); err != nil {
+		return err
+	}
+
+	tx, err := r.lockAssets(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create asset: %w", err)
+	}
+	defer tx.Rollback()
+
+	var taken bool
+	query := fmt.Sprintf(`
+		SELECT EXISTS (SELECT 1 FROM %s WHERE symbol = $1) OR EXISTS (SELECT 1 FROM %s WHERE alias = $1)
+	`, r.table, r.aliases)
+	if err := tx.QueryRowContext(ctx, query, asset.Symbol).Scan(&taken); err != nil {
+		return fmt.Errorf("failed to create asset: %w", err)
+	}
+	if taken {
+		return fmt.Errorf("%w: symbol %s is already defined", interfaces.ErrInvalidAsset, asset.Symbol)
+	}
+
+	now := time.Now()
+	asset.CreatedAt, asset.UpdatedAt = now, now
+
+	query = fmt.Sprintf(`
+		INSERT INTO %s (symbol, asset_class, decimals, network, settlement_days, enabled, created_at, updated_at, metadata)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, r.table)
+
+	result, err := tx.ExecContext(ctx, query,
+		asset.Symbol, asset.AssetClass, asset.Decimals, asset.Network, asset.SettlementDays,
+		asset.Enabled, asset.CreatedAt, asset.UpdatedAt, asset.Metadata,
+	)
+	if err != nil {
+		contextLogger(ctx, r.logger).WithError(err).WithField("symbol", asset.Symbol).Error("Failed to create asset")
+		return fmt.Errorf("failed to create asset: %w", err)
+	}
+	if err := r.replaceAliases(ctx, tx, asset); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to create asset: %w", err)
+	}
+
+	recordResult(ctx, result)
+	return nil
+}
+
+func (r *PostgresAssetRepository) GetBySymbol(ctx context.Context, symbol string) (*models.Asset, error) {
+	symbol = normalizeSymbol(symbol)
+	query := r.selectAssets() + fmt.Sprintf(`
+		WHERE a.symbol = $1 OR a.symbol = (SELECT symbol FROM %s WHERE alias = $1)
+	`, r.aliases)
+
+	asset, err := scanAsset(r.reads.QueryRowContext(ctx, query, symbol))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("asset %w: %s", interfaces.ErrNotFound, symbol)
+	}
+	if err != nil {
+		contextLogger(ctx, r.logger).WithError(err).Error("Failed to get asset")
+		return nil, fmt.Errorf("failed to get asset: %w", err)
+	}
+
+	return asset, nil
+}
+
+func (r *PostgresAssetRepository) Query(ctx context.Context, query *models.AssetQuery) ([]*models.Asset, error) {
+	sqlQuery := r.selectAssets() + " WHERE 1=1"
+
+	args := []interface{}{}
+	argCount := 1
+
+	if query.AssetClass != nil {
+		sqlQuery += fmt.Sprintf(" AND a.asset_class = $%d", argCount)
+		args = append(args, *query.AssetClass)
+		argCount++
+	}
+
+	if query.Network != nil {
+		sqlQuery += fmt.Sprintf(" AND a.network = $%d", argCount)
+		args = append(args, *query.Network)
+		argCount++
+	}
+
+	if query.Enabled != nil {
+		sqlQuery += fmt.Sprintf(" AND a.enabled = $%d", argCount)
+		args = append(args, *query.Enabled)
+		argCount++
+	}
+
+	sqlQuery += " ORDER BY a.symbol"
+
+	if query.Limit > 0 {
+		sqlQuery += fmt.Sprintf(" LIMIT $%d", argCount)
+		args = append(args, query.Limit)
+		argCount++
+	}
+
+	if query.Offset > 0 {
+		sqlQuery += fmt.Sprintf(" OFFSET $%d", argCount)
+		args = append(args, query.Offset)
+	}
+
+	rows, err := r.reads.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		contextLogger(ctx, r.logger).WithError(err).Error("Failed to query assets")
+		return nil, fmt.Errorf("failed to query assets: %w", err)
+	}
+	defer rows.Close()
+
+	assets := []*models.Asset{}
+	for rows.Next() {
+		asset, err := scanAsset(rows)
+		if err != nil {
+			contextLogger(ctx, r.logger).WithError(err).Error("Failed to scan asset")
+			return nil, fmt.Errorf("failed to scan asset: %w", err)
+		}
+		assets = append(assets, asset)
+	}
+
+	recordRowsReturned(ctx, len(assets))
+	return assets, nil
+}
+
+func (r *PostgresAssetRepository) Update(ctx context.Context, asset *models.Asset) error {
+	if err := normalizeAsset(asset); err != nil {
+		return err
+	}
+
+	tx, err := r.lockAssets(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to update asset: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf(`
+		UPDATE %s
+		SET asset_class = $2, decimals = $3, network = $4, settlement_days = $5, enabled = $6, metadata = $7, updated_at = $8
+		WHERE symbol = $1
+		RETURNING created_at
+	`, r.table)
+
+	asset.UpdatedAt = time.Now()
+
+	err = tx.QueryRowContext(ctx, query,
+		asset.Symbol, asset.AssetClass, asset.Decimals, asset.Network, asset.SettlementDays,
+		asset.Enabled, asset.Metadata, asset.UpdatedAt,
+	).Scan(&asset.CreatedAt)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("asset %w: %s", interfaces.ErrNotFound, asset.Symbol)
+	}
+	if err != nil {
+		contextLogger(ctx, r.logger).WithError(err).WithField("symbol", asset.Symbol).Error("Failed to update asset")
+		return fmt.Errorf("failed to update asset: %w", err)
+	}
+	if err := r.replaceAliases(ctx, tx, asset); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to update asset: %w", err)
+	}
+
+	recordRowsAffected(ctx, 1)
+	return nil
+}
+
+func (r *PostgresAssetRepository) SetEnabled(ctx context.Context, symbol string, enabled bool) error {
+	symbol = normalizeSymbol(symbol)
+	query := fmt.Sprintf(`
+		UPDATE %s SET enabled = $2, updated_at = NOW()
+		WHERE symbol = $1 OR symbol = (SELECT symbol FROM %s WHERE alias = $1)
+	`, r.table, r.aliases)
+
+	result, err := r.db.ExecContext(ctx, query, symbol, enabled)
+	if err != nil {
+		contextLogger(ctx, r.logger).WithError(err).Error("Failed to update asset")
+		return fmt.Errorf("failed to update asset: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	recordRowsAffected(ctx, rowsAffected)
+	if rowsAffected == 0 {
+		return fmt.Errorf("asset %w: %s", interfaces.ErrNotFound, symbol)
+	}
+
+	contextLogger(ctx, r.logger).WithFields(interfaces.LogFields{
+		"symbol":  symbol,
+		"enabled": enabled,
+	}).Info("Asset availability changed")
+	return nil
+}
+
+// lockAssets begins a transaction holding the lock that serializes writes to
+// the asset tables, so symbols and aliases stay unique across both
+func (r *PostgresAssetRepository) lockAssets(ctx context.Context) (*sql.Tx, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, r.table); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return tx, nil
+}
+
+// replaceAliases sets the asset's aliases, rejecting any already taken
+func (r *PostgresAssetRepository) replaceAliases(ctx context.Context, tx *sql.Tx, asset *models.Asset) error {
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE symbol = $1`, r.aliases), asset.Symbol); err != nil {
+		return fmt.Errorf("failed to update asset aliases: %w", err)
+	}
+	if len(asset.Aliases) == 0 {
+		return nil
+	}
+
+	var taken string
+	query := fmt.Sprintf(`
+		SELECT alias FROM %s WHERE alias = ANY($1)
+		UNION ALL
+		SELECT symbol FROM %s WHERE symbol = ANY($1)
+		LIMIT 1
+	`, r.aliases, r.table)
+	err := tx.QueryRowContext(ctx, query, pq.Array(asset.Aliases)).Scan(&taken)
+	if err == nil {
+		return fmt.Errorf("%w: alias %s is already defined", interfaces.ErrInvalidAsset, taken)
+	}
+	if err != sql.ErrNoRows {
+		return fmt.Errorf("failed to check asset aliases: %w", err)
+	}
+
+	query = fmt.Sprintf(`INSERT INTO %s (alias, symbol) SELECT unnest($1::text[]), $2`, r.aliases)
+	if _, err := tx.ExecContext(ctx, query, pq.Array(asset.Aliases), asset.Symbol); err != nil {
+		return fmt.Errorf("failed to update asset aliases: %w", err)
+	}
+	return nil
+}
+
+func scanAsset(row interface{ Scan(...interface{}) error }) (*models.Asset, error) {
+	asset := &models.Asset{}
+	err := row.Scan(
+		&asset.Symbol, pq.Array(&asset.Aliases), &asset.AssetClass, &asset.Decimals, &asset.Network,
+		&asset.SettlementDays, &asset.Enabled, &asset.CreatedAt, &asset.UpdatedAt, &asset.Metadata,
+	)
+	return asset, err
+}
+
+// normalizeSymbol is the stored form of a symbol or alias
+func normalizeSymbol(symbol string) string {
+	return strings.ToUpper(strings.TrimSpace(symbol))
+}
+
+// normalizeAsset upper-cases the symbol and aliases, drops duplicate aliases
+// and checks the definition
+func normalizeAsset(asset *models.Asset) error {
+	asset.Symbol = normalizeSymbol(asset.Symbol)
+
+	aliases := make([]string, 0, len(asset.Aliases))
+	for _, alias := range asset.Aliases {
+		alias = normalizeSymbol(alias)
+		if alias != "" && alias != asset.Symbol && !slices.Contains(aliases, alias) {
+			aliases = append(aliases, alias)
+		}
+	}
+	asset.Aliases = aliases
+
+	switch {
+	case asset.Symbol == "":
+		return fmt.Errorf("%w: symbol is required", interfaces.ErrInvalidAsset)
+	case !validAssetClass(asset.AssetClass):
+		return fmt.Errorf("%w: unknown asset class %q", interfaces.ErrInvalidAsset, asset.AssetClass)
+	case asset.Decimals < 0 || asset.Decimals > maxAssetDecimals:
+		return fmt.Errorf("%w: decimals must be between 0 and %d", interfaces.ErrInvalidAsset, maxAssetDecimals)
+	case asset.SettlementDays < 0:
+		return fmt.Errorf("%w: settlement days cannot be negative", interfaces.ErrInvalidAsset)
+	}
+	return nil
+}
+
+func validAssetClass(assetClass models.AssetClass) bool {
+	switch assetClass {
+	case models.AssetClassCrypto, models.AssetClassStablecoin, models.AssetClassFiat, models.AssetClassSecurity:
+		return true
+	}
+	return false
+}
@@ -8,24 +8,33 @@ import (
 
 	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/interfaces"
 	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/models"
-	"github.com/sirupsen/logrus"
 )
 
 type PostgresBalanceRepository struct {
 	db     *sql.DB
-	logger *logrus.Logger
+	reads  *readRouter
+	table  string
+	logger interfaces.Logger
 }
 
-func NewPostgresBalanceRepository(db *sql.DB, logger *logrus.Logger) interfaces.BalanceRepository {
+func NewPostgresBalanceRepository(db *sql.DB, logger interfaces.Logger) interfaces.BalanceRepository {
+	return newPostgresBalanceRepository(db, newPrimaryReads(db), defaultSchemaName, logger)
+}
+
+// newPostgresBalanceRepository uses the balances table in schema and sends
+// read-only queries through reads
+func newPostgresBalanceRepository(db *sql.DB, reads *readRouter, schema string, logger interfaces.Logger) *PostgresBalanceRepository {
 	return &PostgresBalanceRepository{
 		db:     db,
+		reads:  reads,
+		table:  qualifiedTable(schema, "balances"),
 		logger: logger,
 	}
 }
 
 func (r *PostgresBalanceRepository) Upsert(ctx context.Context, balance *models.Balance) error {
-	query := `
-		INSERT INTO custodian.balances (
+	query := fmt.Sprintf(`
+		INSERT INTO %s (
 			balance_id, account_id, currency, available_balance, locked_balance, total_balance, last_updated, metadata
 		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		ON CONFLICT (account_id, currency)
@@ -35,41 +44,42 @@ func (r *PostgresBalanceRepository) Upsert(ctx context.Context, balance *models.
 			total_balance = EXCLUDED.total_balance,
 			last_updated = EXCLUDED.last_updated,
 			metadata = EXCLUDED.metadata
-	`
+	`, r.table)
 
 	balance.LastUpdated = time.Now()
 
-	_, err := r.db.ExecContext(ctx, query,
+	result, err := r.db.ExecContext(ctx, query,
 		balance.BalanceID, balance.AccountID, balance.Currency, balance.AvailableBalance,
 		balance.LockedBalance, balance.TotalBalance, balance.LastUpdated, balance.Metadata,
 	)
 
 	if err != nil {
-		r.logger.WithError(err).Error("Failed to upsert balance")
+		contextLogger(ctx, r.logger).WithError(err).WithField("account_id", balance.AccountID).Error("Failed to upsert balance")
 		return fmt.Errorf("failed to upsert balance: %w", err)
 	}
 
+	recordResult(ctx, result)
 	return nil
 }
 
 func (r *PostgresBalanceRepository) GetByID(ctx context.Context, balanceID string) (*models.Balance, error) {
-	query := `
+	query := fmt.Sprintf(`
 		SELECT balance_id, account_id, currency, available_balance, locked_balance, total_balance, last_updated, metadata
-		FROM custodian.balances
+		FROM %s
 		WHERE balance_id = $1
-	`
+	`, r.table)
 
 	balance := &models.Balance{}
-	err := r.db.QueryRowContext(ctx, query, balanceID).Scan(
+	err := r.reads.QueryRowContext(ctx, query, balanceID).Scan(
 		&balance.BalanceID, &balance.AccountID, &balance.Currency, &balance.AvailableBalance,
 		&balance.LockedBalance, &balance.TotalBalance, &balance.LastUpdated, &balance.Metadata,
 	)
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("balance not found: %s", balanceID)
+		return nil, fmt.Errorf("balance %w: %s", interfaces.ErrNotFound, balanceID)
 	}
 	if err != nil {
-		r.logger.WithError(err).Error("Failed to get balance")
+		contextLogger(ctx, r.logger).WithError(err).Error("Failed to get balance")
 		return nil, fmt.Errorf("failed to get balance: %w", err)
 	}
 
@@ -77,23 +87,23 @@ func (r *PostgresBalanceRepository) GetByID(ctx context.Context, balanceID strin
 }
 
 func (r *PostgresBalanceRepository) GetByAccountAndCurrency(ctx context.Context, accountID, currency string) (*models.Balance, error) {
-	query := `
+	query := fmt.Sprintf(`
 		SELECT balance_id, account_id, currency, available_balance, locked_balance, total_balance, last_updated, metadata
-		FROM custodian.balances
+		FROM %s
 		WHERE account_id = $1 AND currency = $2
-	`
+	`, r.table)
 
 	balance := &models.Balance{}
-	err := r.db.QueryRowContext(ctx, query, accountID, currency).Scan(
+	err := r.reads.QueryRowContext(ctx, query, accountID, currency).Scan(
 		&balance.BalanceID, &balance.AccountID, &balance.Currency, &balance.AvailableBalance,
 		&balance.LockedBalance, &balance.TotalBalance, &balance.LastUpdated, &balance.Metadata,
 	)
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("balance not found for account %s and currency %s", accountID, currency)
+		return nil, accountErrorf("balance %w for account %s and currency %s", interfaces.ErrNotFound, accountRef(accountID), currency)
 	}
 	if err != nil {
-		r.logger.WithError(err).Error("Failed to get balance")
+		contextLogger(ctx, r.logger).WithError(err).WithField("account_id", accountID).Error("Failed to get balance")
 		return nil, fmt.Errorf("failed to get balance: %w", err)
 	}
 
@@ -101,11 +111,11 @@ func (r *PostgresBalanceRepository) GetByAccountAndCurrency(ctx context.Context,
 }
 
 func (r *PostgresBalanceRepository) Query(ctx context.Context, query *models.BalanceQuery) ([]*models.Balance, error) {
-	sqlQuery := `
+	sqlQuery := fmt.Sprintf(`
 		SELECT balance_id, account_id, currency, available_balance, locked_balance, total_balance, last_updated, metadata
-		FROM custodian.balances
+		FROM %s
 		WHERE 1=1
-	`
+	`, r.table)
 
 	args := []interface{}{}
 	argCount := 1
@@ -130,9 +140,9 @@ func (r *PostgresBalanceRepository) Query(ctx context.Context, query *models.Bal
 		argCount++
 	}
 
-	rows, err := r.db.QueryContext(ctx, sqlQuery, args...)
+	rows, err := r.reads.QueryContext(ctx, sqlQuery, args...)
 	if err != nil {
-		r.logger.WithError(err).Error("Failed to query balances")
+		contextLogger(ctx, r.logger).WithError(err).Error("Failed to query balances")
 		return nil, fmt.Errorf("failed to query balances: %w", err)
 	}
 	defer rows.Close()
@@ -145,31 +155,33 @@ func (r *PostgresBalanceRepository) Query(ctx context.Context, query *models.Bal
 			&balance.LockedBalance, &balance.TotalBalance, &balance.LastUpdated, &balance.Metadata,
 		)
 		if err != nil {
-			r.logger.WithError(err).Error("Failed to scan balance")
+			contextLogger(ctx, r.logger).WithError(err).Error("Failed to scan balance")
 			return nil, fmt.Errorf("failed to scan balance: %w", err)
 		}
 		balances = append(balances, balance)
 	}
 
+	recordRowsReturned(ctx, len(balances))
 	return balances, nil
 }
 
 func (r *PostgresBalanceRepository) UpdateAvailableBalance(ctx context.Context, balanceID string, availableBalance, lockedBalance float64) error {
-	query := `
-		UPDATE custodian.balances
+	query := fmt.Sprintf(`
+		UPDATE %s
 		SET available_balance = $2, locked_balance = $3, total_balance = $2 + $3, last_updated = $4
 		WHERE balance_id = $1
-	`
+	`, r.table)
 
 	result, err := r.db.ExecContext(ctx, query, balanceID, availableBalance, lockedBalance, time.Now())
 	if err != nil {
-		r.logger.WithError(err).Error("Failed to update available balance")
+		contextLogger(ctx, r.logger).WithError(err).Error("Failed to update available balance")
 		return fmt.Errorf("failed to update available balance: %w", err)
 	}
 
 	rowsAffected, _ := result.RowsAffected()
+	recordRowsAffected(ctx, rowsAffected)
 	if rowsAffected == 0 {
-		return fmt.Errorf("balance not found: %s", balanceID)
+		return fmt.Errorf("balance %w: %s", interfaces.ErrNotFound, balanceID)
 	}
 
 	return nil
@@ -184,24 +196,25 @@ func (r *PostgresBalanceRepository) GetByAccount(ctx context.Context, accountID
 }
 
 func (r *PostgresBalanceRepository) AtomicUpdate(ctx context.Context, accountID, currency string, availableDelta, lockedDelta float64) error {
-	query := `
-		UPDATE custodian.balances
+	query := fmt.Sprintf(`
+		UPDATE %s
 		SET available_balance = available_balance + $3,
 			locked_balance = locked_balance + $4,
 			total_balance = available_balance + $3 + locked_balance + $4,
 			last_updated = $5
 		WHERE account_id = $1 AND currency = $2
-	`
+	`, r.table)
 
 	result, err := r.db.ExecContext(ctx, query, accountID, currency, availableDelta, lockedDelta, time.Now())
 	if err != nil {
-		r.logger.WithError(err).Error("Failed to atomic update balance")
+		contextLogger(ctx, r.logger).WithError(err).WithField("account_id", accountID).Error("Failed to atomic update balance")
 		return fmt.Errorf("failed to atomic update balance: %w", err)
 	}
 
 	rowsAffected, _ := result.RowsAffected()
+	recordRowsAffected(ctx, rowsAffected)
 	if rowsAffected == 0 {
-		return fmt.Errorf("balance not found for account %s and currency %s", accountID, currency)
+		return accountErrorf("balance %w for account %s and currency %s", interfaces.ErrNotFound, accountRef(accountID), currency)
 	}
 
 	return nil
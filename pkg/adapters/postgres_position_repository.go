@@ -3,60 +3,77 @@ package adapters
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/interfaces"
 	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/models"
-	"github.com/sirupsen/logrus"
 )
 
 type PostgresPositionRepository struct {
 	db     *sql.DB
-	logger *logrus.Logger
+	reads  *readRouter
+	table  string
+	logger interfaces.Logger
+
+	// accounts is the accounts table writes are checked against (empty disables the check)
+	accounts string
+}
+
+func NewPostgresPositionRepository(db *sql.DB, logger interfaces.Logger) interfaces.PositionRepository {
+	return newPostgresPositionRepository(db, newPrimaryReads(db), defaultSchemaName, logger)
 }
 
-func NewPostgresPositionRepository(db *sql.DB, logger *logrus.Logger) interfaces.PositionRepository {
+// newPostgresPositionRepository uses the positions table in schema and sends
+// read-only queries through reads
+func newPostgresPositionRepository(db *sql.DB, reads *readRouter, schema string, logger interfaces.Logger) *PostgresPositionRepository {
 	return &PostgresPositionRepository{
 		db:     db,
+		reads:  reads,
+		table:  qualifiedTable(schema, "positions"),
 		logger: logger,
 	}
 }
 
 func (r *PostgresPositionRepository) Create(ctx context.Context, position *models.Position) error {
-	query := `
-		INSERT INTO custodian.positions (
+	query := fmt.Sprintf(`
+		INSERT INTO %s (
 			position_id, account_id, symbol, quantity, available_quantity, locked_quantity,
 			average_cost, market_value, currency, last_updated, created_at, metadata
 		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
-	`
+	`, r.table)
 
-	_, err := r.db.ExecContext(ctx, query,
+	result, err := execForAccount(ctx, r.db, r.accounts, position.AccountID, query,
 		position.PositionID, position.AccountID, position.Symbol, position.Quantity,
 		position.AvailableQuantity, position.LockedQuantity, position.AverageCost,
 		position.MarketValue, position.Currency, position.LastUpdated, position.CreatedAt,
 		position.Metadata,
 	)
 
+	if errors.Is(err, interfaces.ErrInvalidAccount) {
+		return err
+	}
 	if err != nil {
-		r.logger.WithError(err).Error("Failed to create position")
+		contextLogger(ctx, r.logger).WithError(err).WithField("account_id", position.AccountID).Error("Failed to create position")
 		return fmt.Errorf("failed to create position: %w", err)
 	}
 
+	recordResult(ctx, result)
 	return nil
 }
 
 func (r *PostgresPositionRepository) GetByID(ctx context.Context, positionID string) (*models.Position, error) {
-	query := `
+	query := fmt.Sprintf(`
 		SELECT position_id, account_id, symbol, quantity, available_quantity, locked_quantity,
 			   average_cost, market_value, currency, last_updated, created_at, metadata
-		FROM custodian.positions
+		FROM %s
 		WHERE position_id = $1
-	`
+	`, r.table)
 
 	position := &models.Position{}
-	err := r.db.QueryRowContext(ctx, query, positionID).Scan(
+	err := r.reads.QueryRowContext(ctx, query, positionID).Scan(
 		&position.PositionID, &position.AccountID, &position.Symbol, &position.Quantity,
 		&position.AvailableQuantity, &position.LockedQuantity, &position.AverageCost,
 		&position.MarketValue, &position.Currency, &position.LastUpdated, &position.CreatedAt,
@@ -64,10 +81,10 @@ func (r *PostgresPositionRepository) GetByID(ctx context.Context, positionID str
 	)
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("position not found: %s", positionID)
+		return nil, fmt.Errorf("position %w: %s", interfaces.ErrNotFound, positionID)
 	}
 	if err != nil {
-		r.logger.WithError(err).Error("Failed to get position by ID")
+		contextLogger(ctx, r.logger).WithError(err).Error("Failed to get position by ID")
 		return nil, fmt.Errorf("failed to get position: %w", err)
 	}
 
@@ -75,15 +92,15 @@ func (r *PostgresPositionRepository) GetByID(ctx context.Context, positionID str
 }
 
 func (r *PostgresPositionRepository) GetByAccountAndSymbol(ctx context.Context, accountID, symbol string) (*models.Position, error) {
-	query := `
+	query := fmt.Sprintf(`
 		SELECT position_id, account_id, symbol, quantity, available_quantity, locked_quantity,
 			   average_cost, market_value, currency, last_updated, created_at, metadata
-		FROM custodian.positions
+		FROM %s
 		WHERE account_id = $1 AND symbol = $2
-	`
+	`, r.table)
 
 	position := &models.Position{}
-	err := r.db.QueryRowContext(ctx, query, accountID, symbol).Scan(
+	err := r.reads.QueryRowContext(ctx, query, accountID, symbol).Scan(
 		&position.PositionID, &position.AccountID, &position.Symbol, &position.Quantity,
 		&position.AvailableQuantity, &position.LockedQuantity, &position.AverageCost,
 		&position.MarketValue, &position.Currency, &position.LastUpdated, &position.CreatedAt,
@@ -91,10 +108,10 @@ func (r *PostgresPositionRepository) GetByAccountAndSymbol(ctx context.Context,
 	)
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("position not found for account %s and symbol %s", accountID, symbol)
+		return nil, accountErrorf("position %w for account %s and symbol %s", interfaces.ErrNotFound, accountRef(accountID), symbol)
 	}
 	if err != nil {
-		r.logger.WithError(err).Error("Failed to get position by account and symbol")
+		contextLogger(ctx, r.logger).WithError(err).WithField("account_id", accountID).Error("Failed to get position by account and symbol")
 		return nil, fmt.Errorf("failed to get position: %w", err)
 	}
 
@@ -102,12 +119,12 @@ func (r *PostgresPositionRepository) GetByAccountAndSymbol(ctx context.Context,
 }
 
 func (r *PostgresPositionRepository) Query(ctx context.Context, query *models.PositionQuery) ([]*models.Position, error) {
-	sqlQuery := `
+	sqlQuery := fmt.Sprintf(`
 		SELECT position_id, account_id, symbol, quantity, available_quantity, locked_quantity,
 			   average_cost, market_value, currency, last_updated, created_at, metadata
-		FROM custodian.positions
+		FROM %s
 		WHERE 1=1
-	`
+	`, r.table)
 
 	args := []interface{}{}
 	argCount := 1
@@ -166,9 +183,9 @@ func (r *PostgresPositionRepository) Query(ctx context.Context, query *models.Po
 		argCount++
 	}
 
-	rows, err := r.db.QueryContext(ctx, sqlQuery, args...)
+	rows, err := r.reads.QueryContext(ctx, sqlQuery, args...)
 	if err != nil {
-		r.logger.WithError(err).Error("Failed to query positions")
+		contextLogger(ctx, r.logger).WithError(err).Error("Failed to query positions")
 		return nil, fmt.Errorf("failed to query positions: %w", err)
 	}
 	defer rows.Close()
@@ -183,77 +200,84 @@ func (r *PostgresPositionRepository) Query(ctx context.Context, query *models.Po
 			&position.Metadata,
 		)
 		if err != nil {
-			r.logger.WithError(err).Error("Failed to scan position row")
+			contextLogger(ctx, r.logger).WithError(err).Error("Failed to scan position row")
 			return nil, fmt.Errorf("failed to scan position: %w", err)
 		}
 		positions = append(positions, position)
 	}
 
+	recordRowsReturned(ctx, len(positions))
 	return positions, nil
 }
 
 func (r *PostgresPositionRepository) Update(ctx context.Context, position *models.Position) error {
-	query := `
-		UPDATE custodian.positions
+	query := fmt.Sprintf(`
+		UPDATE %s
 		SET account_id = $2, symbol = $3, quantity = $4, available_quantity = $5, locked_quantity = $6,
 			average_cost = $7, market_value = $8, currency = $9, last_updated = $10, metadata = $11
 		WHERE position_id = $1
-	`
+	`, r.table)
 
 	position.LastUpdated = time.Now()
 
-	result, err := r.db.ExecContext(ctx, query,
+	result, err := execForAccount(ctx, r.db, r.accounts, position.AccountID, query,
 		position.PositionID, position.AccountID, position.Symbol, position.Quantity,
 		position.AvailableQuantity, position.LockedQuantity, position.AverageCost,
 		position.MarketValue, position.Currency, position.LastUpdated, position.Metadata,
 	)
 
+	if errors.Is(err, interfaces.ErrInvalidAccount) {
+		return err
+	}
 	if err != nil {
-		r.logger.WithError(err).Error("Failed to update position")
+		contextLogger(ctx, r.logger).WithError(err).Error("Failed to update position")
 		return fmt.Errorf("failed to update position: %w", err)
 	}
 
 	rowsAffected, _ := result.RowsAffected()
+	recordRowsAffected(ctx, rowsAffected)
 	if rowsAffected == 0 {
-		return fmt.Errorf("position not found: %s", position.PositionID)
+		return fmt.Errorf("position %w: %s", interfaces.ErrNotFound, position.PositionID)
 	}
 
 	return nil
 }
 
 func (r *PostgresPositionRepository) UpdateAvailableQuantity(ctx context.Context, positionID string, availableQty, lockedQty float64) error {
-	query := `
-		UPDATE custodian.positions
+	query := fmt.Sprintf(`
+		UPDATE %s
 		SET available_quantity = $2, locked_quantity = $3, last_updated = $4
 		WHERE position_id = $1
-	`
+	`, r.table)
 
 	result, err := r.db.ExecContext(ctx, query, positionID, availableQty, lockedQty, time.Now())
 	if err != nil {
-		r.logger.WithError(err).Error("Failed to update available quantity")
+		contextLogger(ctx, r.logger).WithError(err).Error("Failed to update available quantity")
 		return fmt.Errorf("failed to update available quantity: %w", err)
 	}
 
 	rowsAffected, _ := result.RowsAffected()
+	recordRowsAffected(ctx, rowsAffected)
 	if rowsAffected == 0 {
-		return fmt.Errorf("position not found: %s", positionID)
+		return fmt.Errorf("position %w: %s", interfaces.ErrNotFound, positionID)
 	}
 
 	return nil
 }
 
 func (r *PostgresPositionRepository) Delete(ctx context.Context, positionID string) error {
-	query := `DELETE FROM custodian.positions WHERE position_id = $1`
+	query := fmt.Sprintf(`DELETE FROM %s WHERE position_id = $1`, r.table)
 
 	result, err := r.db.ExecContext(ctx, query, positionID)
 	if err != nil {
-		r.logger.WithError(err).Error("Failed to delete position")
+		contextLogger(ctx, r.logger).WithError(err).Error("Failed to delete position")
 		return fmt.Errorf("failed to delete position: %w", err)
 	}
 
 	rowsAffected, _ := result.RowsAffected()
+	recordRowsAffected(ctx, rowsAffected)
 	if rowsAffected == 0 {
-		return fmt.Errorf("position not found: %s", positionID)
+		return fmt.Errorf("position %w: %s", interfaces.ErrNotFound, positionID)
 	}
 
 	return nil
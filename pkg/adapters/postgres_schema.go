@@ -0,0 +1,127 @@
+package adapters
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/lib/pq"
+
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/internal/database"
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/interfaces"
+)
+
+// ErrLegacySchema is returned by Connect when an instance's derived schema
+// does not exist but the one earlier releases derived for it does
+var ErrLegacySchema = errors.New("instance data is in its legacy schema")
+
+// ErrSchemaNotMigrated is returned by Connect when an enabled feature needs
+// a table that the instance's schema lacks
+var ErrSchemaNotMigrated = errors.New("schema is missing tables")
+
+// defaultSchemaName holds the tables of the singleton custodian instance
+const defaultSchemaName = "custodian"
+
+// qualifiedTable returns table in schema, quoting the schema so derived names
+// are used exactly as given
+func qualifiedTable(schema, table string) string {
+	return pq.QuoteIdentifier(schema) + "." + table
+}
+
+// legacyInstanceNames returns the schema and Redis namespace releases before
+// full-name derivation used for instanceName, which kept only its first two
+// parts, or empty names if the derivation has not changed for it
+func legacyInstanceNames(serviceName, instanceName string) (schema, namespace string) {
+	parts := strings.Split(instanceName, "-")
+	if serviceName == instanceName || len(parts) <= 2 {
+		return "", ""
+	}
+	return strings.ToLower(parts[0] + "_" + parts[1]), parts[0] + ":" + parts[1]
+}
+
+// checkLegacySchema fails with ErrLegacySchema if schema does not exist but
+// legacy does, so an upgraded instance does not silently start on an empty
+// schema while its data sits in the old one
+func checkLegacySchema(ctx context.Context, db *sql.DB, schema, legacy, legacyNamespace string) error {
+	exists, _, err := database.SchemaStatus(ctx, db, schema)
+	if err != nil || exists {
+		return err
+	}
+	legacyExists, _, err := database.SchemaStatus(ctx, db, legacy)
+	if err != nil || !legacyExists {
+		return err
+	}
+	return fmt.Errorf("%w: schema %s does not exist, but %s, which earlier releases derived for this instance, does; "+
+		"set SCHEMA_NAME=%s and REDIS_NAMESPACE=%s to keep using it, or move its data to %s",
+		ErrLegacySchema, schema, legacy, legacy, legacyNamespace, schema)
+}
+
+// schemaRequirement is a table an enabled setting depends on
+type schemaRequirement struct {
+	table   string
+	setting string
+}
+
+// checkSchemaTables fails with ErrSchemaNotMigrated if schema lacks a
+// required table, instead of failing every write that needs it
+func checkSchemaTables(ctx context.Context, db *sql.DB, schema string, requirements []schemaRequirement) error {
+	if len(requirements) == 0 {
+		return nil
+	}
+
+	tables := make([]string, 0, len(requirements))
+	for _, r := range requirements {
+		tables = append(tables, r.table)
+	}
+	missing, err := database.MissingTables(ctx, db, schema, tables...)
+	if err != nil || len(missing) == 0 {
+		return err
+	}
+
+	var settings []string
+	for _, r := range requirements {
+		if slices.Contains(missing, r.table) && !slices.Contains(settings, r.setting) {
+			settings = append(settings, r.setting)
+		}
+	}
+	noun := "table"
+	if len(missing) > 1 {
+		noun = "tables"
+	}
+	return fmt.Errorf("%w: schema %s lacks the %s %s needed by %s; apply the migrations "+
+		"with InstanceProvisioner.ProvisionInstance or set SCHEMA_AUTO_MIGRATE=true",
+		ErrSchemaNotMigrated, schema, strings.Join(missing, ", "), noun, strings.Join(settings, ", "))
+}
+
+// migrateSchema applies pending migrations to schema, logging any it applies
+func (a *CustodianDataAdapter) migrateSchema(ctx context.Context) error {
+	applied, err := database.ProvisionSchema(ctx, a.postgresDB.DB, a.config.SchemaName, "")
+	if err != nil {
+		return fmt.Errorf("failed to migrate schema %s: %w", a.config.SchemaName, err)
+	}
+	if applied > 0 {
+		contextLogger(ctx, a.logger).WithFields(interfaces.LogFields{
+			"schema_name": a.config.SchemaName,
+			"migrations":  applied,
+		}).Info("Schema migrations applied")
+	}
+	return nil
+}
+
+// schemaRequirements lists the tables the enabled checks need
+func (a *CustodianDataAdapter) schemaRequirements() []schemaRequirement {
+	var requirements []schemaRequirement
+	if a.config.AccountChecksEnabled {
+		requirements = append(requirements, schemaRequirement{table: "accounts", setting: "ACCOUNT_CHECKS_ENABLED"})
+	}
+	if a.config.AssetChecksEnabled {
+		requirements = append(requirements,
+			schemaRequirement{table: "assets", setting: "ASSET_CHECKS_ENABLED"},
+			schemaRequirement{table: "asset_aliases", setting: "ASSET_CHECKS_ENABLED"},
+		)
+	}
+	return requirements
+}
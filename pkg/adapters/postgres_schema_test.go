@@ -0,0 +1,173 @@
+package adapters
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// schemaCatalog answers SchemaStatus queries from a set of existing schemas
+type schemaCatalog map[string]bool
+
+func (c schemaCatalog) Connect(ctx context.Context) (driver.Conn, error) {
+	return &schemaCatalogConn{c}, nil
+}
+func (c schemaCatalog) Driver() driver.Driver { return nil }
+
+type schemaCatalogConn struct {
+	schemas schemaCatalog
+}
+
+func (c *schemaCatalogConn) Prepare(query string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (c *schemaCatalogConn) Close() error                              { return nil }
+func (c *schemaCatalogConn) Begin() (driver.Tx, error)                 { return nil, driver.ErrSkip }
+
+func (c *schemaCatalogConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	exists := c.schemas[args[0].Value.(string)]
+	return &schemaStatusRows{values: []driver.Value{exists, exists}}, nil
+}
+
+type schemaStatusRows struct {
+	values []driver.Value
+	read   bool
+}
+
+func (r *schemaStatusRows) Columns() []string {
+	return []string{"exists", "provisioned"}[:len(r.values)]
+}
+func (r *schemaStatusRows) Close() error { return nil }
+func (r *schemaStatusRows) Next(dest []driver.Value) error {
+	if r.read {
+		return io.EOF
+	}
+	r.read = true
+	copy(dest, r.values)
+	return nil
+}
+
+// TestLegacyInstanceNames tests which instances had different names derived by earlier releases
+func TestLegacyInstanceNames(t *testing.T) {
+	tests := []struct {
+		instanceName string
+		schema       string
+		namespace    string
+	}{
+		{instanceName: "custodian-simulator"},
+		{instanceName: "custodian-Komainu"},
+		{instanceName: "custodian-Komainu-Primary", schema: "custodian_komainu", namespace: "custodian:Komainu"},
+		{instanceName: "custodian-Copper-EU-2", schema: "custodian_copper", namespace: "custodian:Copper"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.instanceName, func(t *testing.T) {
+			schema, namespace := legacyInstanceNames("custodian-simulator", tt.instanceName)
+			if schema != tt.schema || namespace != tt.namespace {
+				t.Errorf("legacyInstanceNames() = %q, %q, expected %q, %q", schema, namespace, tt.schema, tt.namespace)
+			}
+		})
+	}
+}
+
+// TestCheckLegacySchema tests that an instance whose data is only in its
+// legacy schema fails loudly instead of starting on a new, empty one
+func TestCheckLegacySchema(t *testing.T) {
+	tests := []struct {
+		name     string
+		schemas  schemaCatalog
+		errorMsg string
+	}{
+		{name: "new instance", schemas: schemaCatalog{}},
+		{name: "migrated instance", schemas: schemaCatalog{"custodian_komainu_primary": true, "custodian_komainu": true}},
+		{
+			name:     "data in legacy schema",
+			schemas:  schemaCatalog{"custodian_komainu": true},
+			errorMsg: "set SCHEMA_NAME=custodian_komainu and REDIS_NAMESPACE=custodian:Komainu",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := sql.OpenDB(tt.schemas)
+			err := checkLegacySchema(context.Background(), db, "custodian_komainu_primary", "custodian_komainu", "custodian:Komainu")
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("checkLegacySchema failed: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrLegacySchema) || !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("checkLegacySchema error = %v, expected %q", err, tt.errorMsg)
+			}
+		})
+	}
+}
+
+// tableCatalog answers to_regclass checks from a set of qualified table names
+type tableCatalog map[string]bool
+
+func (c tableCatalog) Connect(ctx context.Context) (driver.Conn, error) {
+	return &tableCatalogConn{c}, nil
+}
+func (c tableCatalog) Driver() driver.Driver { return nil }
+
+type tableCatalogConn struct {
+	tables tableCatalog
+}
+
+func (c *tableCatalogConn) Prepare(query string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (c *tableCatalogConn) Close() error                              { return nil }
+func (c *tableCatalogConn) Begin() (driver.Tx, error)                 { return nil, driver.ErrSkip }
+
+func (c *tableCatalogConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return &schemaStatusRows{values: []driver.Value{c.tables[args[0].Value.(string)]}}, nil
+}
+
+// TestCheckSchemaTables tests that enabled checks fail Connect with guidance
+// when the schema predates the tables they need
+func TestCheckSchemaTables(t *testing.T) {
+	accounts := []schemaRequirement{{table: "accounts", setting: "ACCOUNT_CHECKS_ENABLED"}}
+
+	tests := []struct {
+		name         string
+		tables       tableCatalog
+		requirements []schemaRequirement
+		errorMsg     string
+	}{
+		{name: "no checks enabled", tables: tableCatalog{}},
+		{name: "migrated schema", tables: tableCatalog{`"custodian"."accounts"`: true}, requirements: accounts},
+		{
+			name:         "schema without accounts",
+			tables:       tableCatalog{},
+			requirements: accounts,
+			errorMsg:     "schema custodian lacks the accounts table needed by ACCOUNT_CHECKS_ENABLED",
+		},
+		{
+			name:   "schema without assets",
+			tables: tableCatalog{`"custodian"."accounts"`: true},
+			requirements: append(accounts,
+				schemaRequirement{table: "assets", setting: "ASSET_CHECKS_ENABLED"},
+				schemaRequirement{table: "asset_aliases", setting: "ASSET_CHECKS_ENABLED"},
+			),
+			errorMsg: "schema custodian lacks the assets, asset_aliases tables needed by ASSET_CHECKS_ENABLED;",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkSchemaTables(context.Background(), sql.OpenDB(tt.tables), "custodian", tt.requirements)
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("checkSchemaTables failed: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrSchemaNotMigrated) || !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("checkSchemaTables error = %v, expected %q", err, tt.errorMsg)
+			}
+		})
+	}
+}
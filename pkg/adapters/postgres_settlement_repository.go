@@ -3,59 +3,76 @@ package adapters
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/interfaces"
 	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/models"
-	"github.com/sirupsen/logrus"
 )
 
 type PostgresSettlementRepository struct {
 	db     *sql.DB
-	logger *logrus.Logger
+	reads  *readRouter
+	table  string
+	logger interfaces.Logger
+
+	// accounts is the accounts table writes are checked against (empty disables the check)
+	accounts string
+}
+
+func NewPostgresSettlementRepository(db *sql.DB, logger interfaces.Logger) interfaces.SettlementRepository {
+	return newPostgresSettlementRepository(db, newPrimaryReads(db), defaultSchemaName, logger)
 }
 
-func NewPostgresSettlementRepository(db *sql.DB, logger *logrus.Logger) interfaces.SettlementRepository {
+// newPostgresSettlementRepository uses the settlements table in schema and sends
+// read-only queries through reads
+func newPostgresSettlementRepository(db *sql.DB, reads *readRouter, schema string, logger interfaces.Logger) *PostgresSettlementRepository {
 	return &PostgresSettlementRepository{
 		db:     db,
+		reads:  reads,
+		table:  qualifiedTable(schema, "settlements"),
 		logger: logger,
 	}
 }
 
 func (r *PostgresSettlementRepository) Create(ctx context.Context, settlement *models.Settlement) error {
-	query := `
-		INSERT INTO custodian.settlements (
+	query := fmt.Sprintf(`
+		INSERT INTO %s (
 			settlement_id, external_id, settlement_type, account_id, symbol, quantity, status,
 			source_account, destination_account, initiated_at, completed_at, expected_settlement_date, metadata
 		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
-	`
+	`, r.table)
 
-	_, err := r.db.ExecContext(ctx, query,
+	result, err := execForAccount(ctx, r.db, r.accounts, settlement.AccountID, query,
 		settlement.SettlementID, settlement.ExternalID, settlement.SettlementType, settlement.AccountID,
 		settlement.Symbol, settlement.Quantity, settlement.Status, settlement.SourceAccount,
 		settlement.DestinationAccount, settlement.InitiatedAt, settlement.CompletedAt,
 		settlement.ExpectedSettlementDate, settlement.Metadata,
 	)
 
+	if errors.Is(err, interfaces.ErrInvalidAccount) {
+		return err
+	}
 	if err != nil {
-		r.logger.WithError(err).Error("Failed to create settlement")
+		contextLogger(ctx, r.logger).WithError(err).WithField("account_id", settlement.AccountID).Error("Failed to create settlement")
 		return fmt.Errorf("failed to create settlement: %w", err)
 	}
 
+	recordResult(ctx, result)
 	return nil
 }
 
 func (r *PostgresSettlementRepository) GetByID(ctx context.Context, settlementID string) (*models.Settlement, error) {
-	query := `
+	query := fmt.Sprintf(`
 		SELECT settlement_id, external_id, settlement_type, account_id, symbol, quantity, status,
 			   source_account, destination_account, initiated_at, completed_at, expected_settlement_date, metadata
-		FROM custodian.settlements
+		FROM %s
 		WHERE settlement_id = $1
-	`
+	`, r.table)
 
 	settlement := &models.Settlement{}
-	err := r.db.QueryRowContext(ctx, query, settlementID).Scan(
+	err := r.reads.QueryRowContext(ctx, query, settlementID).Scan(
 		&settlement.SettlementID, &settlement.ExternalID, &settlement.SettlementType, &settlement.AccountID,
 		&settlement.Symbol, &settlement.Quantity, &settlement.Status, &settlement.SourceAccount,
 		&settlement.DestinationAccount, &settlement.InitiatedAt, &settlement.CompletedAt,
@@ -63,10 +80,10 @@ func (r *PostgresSettlementRepository) GetByID(ctx context.Context, settlementID
 	)
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("settlement not found: %s", settlementID)
+		return nil, fmt.Errorf("settlement %w: %s", interfaces.ErrNotFound, settlementID)
 	}
 	if err != nil {
-		r.logger.WithError(err).Error("Failed to get settlement")
+		contextLogger(ctx, r.logger).WithError(err).Error("Failed to get settlement")
 		return nil, fmt.Errorf("failed to get settlement: %w", err)
 	}
 
@@ -74,15 +91,15 @@ func (r *PostgresSettlementRepository) GetByID(ctx context.Context, settlementID
 }
 
 func (r *PostgresSettlementRepository) GetByExternalID(ctx context.Context, externalID string) (*models.Settlement, error) {
-	query := `
+	query := fmt.Sprintf(`
 		SELECT settlement_id, external_id, settlement_type, account_id, symbol, quantity, status,
 			   source_account, destination_account, initiated_at, completed_at, expected_settlement_date, metadata
-		FROM custodian.settlements
+		FROM %s
 		WHERE external_id = $1
-	`
+	`, r.table)
 
 	settlement := &models.Settlement{}
-	err := r.db.QueryRowContext(ctx, query, externalID).Scan(
+	err := r.reads.QueryRowContext(ctx, query, externalID).Scan(
 		&settlement.SettlementID, &settlement.ExternalID, &settlement.SettlementType, &settlement.AccountID,
 		&settlement.Symbol, &settlement.Quantity, &settlement.Status, &settlement.SourceAccount,
 		&settlement.DestinationAccount, &settlement.InitiatedAt, &settlement.CompletedAt,
@@ -90,10 +107,10 @@ func (r *PostgresSettlementRepository) GetByExternalID(ctx context.Context, exte
 	)
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("settlement not found with external ID: %s", externalID)
+		return nil, fmt.Errorf("settlement %w with external ID: %s", interfaces.ErrNotFound, externalID)
 	}
 	if err != nil {
-		r.logger.WithError(err).Error("Failed to get settlement by external ID")
+		contextLogger(ctx, r.logger).WithError(err).Error("Failed to get settlement by external ID")
 		return nil, fmt.Errorf("failed to get settlement: %w", err)
 	}
 
@@ -102,12 +119,12 @@ func (r *PostgresSettlementRepository) GetByExternalID(ctx context.Context, exte
 
 func (r *PostgresSettlementRepository) Query(ctx context.Context, query *models.SettlementQuery) ([]*models.Settlement, error) {
 	// Implementation similar to Position Query (simplified for brevity)
-	sqlQuery := `
+	sqlQuery := fmt.Sprintf(`
 		SELECT settlement_id, external_id, settlement_type, account_id, symbol, quantity, status,
 			   source_account, destination_account, initiated_at, completed_at, expected_settlement_date, metadata
-		FROM custodian.settlements
+		FROM %s
 		WHERE 1=1
-	`
+	`, r.table)
 
 	args := []interface{}{}
 	argCount := 1
@@ -138,9 +155,9 @@ func (r *PostgresSettlementRepository) Query(ctx context.Context, query *models.
 		argCount++
 	}
 
-	rows, err := r.db.QueryContext(ctx, sqlQuery, args...)
+	rows, err := r.reads.QueryContext(ctx, sqlQuery, args...)
 	if err != nil {
-		r.logger.WithError(err).Error("Failed to query settlements")
+		contextLogger(ctx, r.logger).WithError(err).Error("Failed to query settlements")
 		return nil, fmt.Errorf("failed to query settlements: %w", err)
 	}
 	defer rows.Close()
@@ -155,27 +172,29 @@ func (r *PostgresSettlementRepository) Query(ctx context.Context, query *models.
 			&settlement.ExpectedSettlementDate, &settlement.Metadata,
 		)
 		if err != nil {
-			r.logger.WithError(err).Error("Failed to scan settlement")
+			contextLogger(ctx, r.logger).WithError(err).Error("Failed to scan settlement")
 			return nil, fmt.Errorf("failed to scan settlement: %w", err)
 		}
 		settlements = append(settlements, settlement)
 	}
 
+	recordRowsReturned(ctx, len(settlements))
 	return settlements, nil
 }
 
 func (r *PostgresSettlementRepository) UpdateStatus(ctx context.Context, settlementID string, status models.SettlementStatus) error {
-	query := `UPDATE custodian.settlements SET status = $2 WHERE settlement_id = $1`
+	query := fmt.Sprintf(`UPDATE %s SET status = $2 WHERE settlement_id = $1`, r.table)
 
 	result, err := r.db.ExecContext(ctx, query, settlementID, status)
 	if err != nil {
-		r.logger.WithError(err).Error("Failed to update settlement status")
+		contextLogger(ctx, r.logger).WithError(err).Error("Failed to update settlement status")
 		return fmt.Errorf("failed to update settlement status: %w", err)
 	}
 
 	rowsAffected, _ := result.RowsAffected()
+	recordRowsAffected(ctx, rowsAffected)
 	if rowsAffected == 0 {
-		return fmt.Errorf("settlement not found: %s", settlementID)
+		return fmt.Errorf("settlement %w: %s", interfaces.ErrNotFound, settlementID)
 	}
 
 	return nil
@@ -183,34 +202,36 @@ func (r *PostgresSettlementRepository) UpdateStatus(ctx context.Context, settlem
 
 func (r *PostgresSettlementRepository) Complete(ctx context.Context, settlementID string) error {
 	now := time.Now()
-	query := `UPDATE custodian.settlements SET status = $2, completed_at = $3 WHERE settlement_id = $1`
+	query := fmt.Sprintf(`UPDATE %s SET status = $2, completed_at = $3 WHERE settlement_id = $1`, r.table)
 
 	result, err := r.db.ExecContext(ctx, query, settlementID, models.SettlementStatusCompleted, now)
 	if err != nil {
-		r.logger.WithError(err).Error("Failed to complete settlement")
+		contextLogger(ctx, r.logger).WithError(err).Error("Failed to complete settlement")
 		return fmt.Errorf("failed to complete settlement: %w", err)
 	}
 
 	rowsAffected, _ := result.RowsAffected()
+	recordRowsAffected(ctx, rowsAffected)
 	if rowsAffected == 0 {
-		return fmt.Errorf("settlement not found: %s", settlementID)
+		return fmt.Errorf("settlement %w: %s", interfaces.ErrNotFound, settlementID)
 	}
 
 	return nil
 }
 
 func (r *PostgresSettlementRepository) Cancel(ctx context.Context, settlementID string) error {
-	query := `UPDATE custodian.settlements SET status = $2 WHERE settlement_id = $1`
+	query := fmt.Sprintf(`UPDATE %s SET status = $2 WHERE settlement_id = $1`, r.table)
 
 	result, err := r.db.ExecContext(ctx, query, settlementID, models.SettlementStatusCancelled)
 	if err != nil {
-		r.logger.WithError(err).Error("Failed to cancel settlement")
+		contextLogger(ctx, r.logger).WithError(err).Error("Failed to cancel settlement")
 		return fmt.Errorf("failed to cancel settlement: %w", err)
 	}
 
 	rowsAffected, _ := result.RowsAffected()
+	recordRowsAffected(ctx, rowsAffected)
 	if rowsAffected == 0 {
-		return fmt.Errorf("settlement not found: %s", settlementID)
+		return fmt.Errorf("settlement %w: %s", interfaces.ErrNotFound, settlementID)
 	}
 
 	return nil
@@ -0,0 +1,247 @@
+package adapters
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/internal/database"
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/interfaces"
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrDropNotConfirmed is returned by DropInstance unless the confirmation
+// names the schema being dropped
+var ErrDropNotConfirmed = errors.New("drop not confirmed")
+
+// dropScanCount is the SCAN batch size when deleting a namespace's keys
+const dropScanCount = 500
+
+// defaultServiceDiscoveryNamespace is where instances register unless configured otherwise
+const defaultServiceDiscoveryNamespace = "custodian"
+
+// Instance identifies the storage of one custodian instance. SchemaName and
+// RedisNamespace are derived from the names when empty, and the other
+// namespaces default, as the adapter does.
+type Instance struct {
+	ServiceName    string
+	InstanceName   string
+	SchemaName     string
+	RedisNamespace string
+
+	// CacheNamespace holds the instance's cache, locks and semaphores (RedisNamespace if empty)
+	CacheNamespace string
+
+	// ServiceDiscoveryNamespace holds the instance's registration, whose
+	// service ID is the instance name ("custodian" if empty)
+	ServiceDiscoveryNamespace string
+
+	// Role is granted read and write access to the provisioned tables (optional)
+	Role string
+}
+
+// resolve fills in the derived schema and namespace
+func (i Instance) resolve() (Instance, error) {
+	if i.ServiceName == "" || i.InstanceName == "" {
+		return i, fmt.Errorf("service and instance names are required")
+	}
+	if i.SchemaName == "" || i.RedisNamespace == "" {
+		if err := checkDerivableInstanceName(i.ServiceName, i.InstanceName); err != nil {
+			return i, err
+		}
+	}
+	if i.SchemaName == "" {
+		i.SchemaName = deriveSchemaName(i.ServiceName, i.InstanceName)
+	}
+	if i.RedisNamespace == "" {
+		i.RedisNamespace = deriveRedisNamespace(i.ServiceName, i.InstanceName)
+	}
+	if i.CacheNamespace == "" {
+		i.CacheNamespace = i.RedisNamespace
+	}
+	if i.ServiceDiscoveryNamespace == "" {
+		i.ServiceDiscoveryNamespace = defaultServiceDiscoveryNamespace
+	}
+	return i, nil
+}
+
+// InstanceProvisioner creates and tears down the storage of dynamically
+// started instances. It needs a PostgreSQL connection allowed to create
+// schemas and grant access, usually not the adapter's own.
+type InstanceProvisioner struct {
+	db     *sql.DB
+	redis  redis.UniversalClient
+	logger interfaces.Logger
+}
+
+// NewInstanceProvisioner creates a provisioner. client may be nil, in which
+// case DropInstance leaves Redis keys to expire.
+func NewInstanceProvisioner(db *sql.DB, client redis.UniversalClient, logger interfaces.Logger) *InstanceProvisioner {
+	return &InstanceProvisioner{
+		db:     db,
+		redis:  client,
+		logger: logger,
+	}
+}
+
+// ProvisionInstance creates the instance's schema, applies pending migrations
+// and grants the role access. It is safe to repeat, e.g. to migrate an
+// existing instance, including one whose tables were created outside
+// ProvisionInstance: those are adopted as they are. It returns the instance
+// with derived names filled in.
+func (p *InstanceProvisioner) ProvisionInstance(ctx context.Context, instance Instance) (Instance, error) {
+	instance, err := instance.resolve()
+	if err != nil {
+		return instance, err
+	}
+
+	applied, err := database.ProvisionSchema(ctx, p.db, instance.SchemaName, instance.Role)
+	if err != nil {
+		contextLogger(ctx, p.logger).WithError(err).WithField("schema_name", instance.SchemaName).Error("Failed to provision instance")
+		return instance, fmt.Errorf("failed to provision instance %s: %w", instance.InstanceName, err)
+	}
+
+	contextLogger(ctx, p.logger).WithFields(interfaces.LogFields{
+		"instance_name":      instance.InstanceName,
+		"schema_name":        instance.SchemaName,
+		"migrations_applied": applied,
+	}).Info("Instance provisioned")
+	return instance, nil
+}
+
+// DropInstance drops the instance's schema with all its data and deletes its
+// Redis keys: the Redis and cache namespaces, its locks and semaphores and
+// its service registration. confirm must equal the schema name. Only
+// schemas created by ProvisionInstance are dropped; singleton instances and
+// the shared schema are refused.
+func (p *InstanceProvisioner) DropInstance(ctx context.Context, instance Instance, confirm string) error {
+	instance, err := instance.resolve()
+	if err != nil {
+		return err
+	}
+	if err := checkDroppable(instance, confirm); err != nil {
+		return err
+	}
+
+	exists, provisioned, err := database.SchemaStatus(ctx, p.db, instance.SchemaName)
+	if err != nil {
+		return err
+	}
+	if exists && !provisioned {
+		return fmt.Errorf("schema %s was not created by ProvisionInstance; refusing to drop it", instance.SchemaName)
+	}
+	if exists {
+		if err := database.DropSchema(ctx, p.db, instance.SchemaName); err != nil {
+			contextLogger(ctx, p.logger).WithError(err).WithField("schema_name", instance.SchemaName).Error("Failed to drop instance schema")
+			return err
+		}
+	}
+
+	deleted := 0
+	if p.redis != nil {
+		if deleted, err = p.deleteInstanceKeys(ctx, instance); err != nil {
+			contextLogger(ctx, p.logger).WithError(err).WithField("redis_namespace", instance.RedisNamespace).Error("Failed to delete instance keys")
+			return fmt.Errorf("schema %s dropped but Redis cleanup failed: %w", instance.SchemaName, err)
+		}
+	}
+
+	contextLogger(ctx, p.logger).WithFields(interfaces.LogFields{
+		"instance_name":   instance.InstanceName,
+		"schema_name":     instance.SchemaName,
+		"schema_dropped":  exists,
+		"redis_namespace": instance.RedisNamespace,
+		"cache_namespace": instance.CacheNamespace,
+		"keys_deleted":    deleted,
+	}).Warn("Instance dropped")
+	return nil
+}
+
+// checkDroppable guards against dropping shared or system storage
+func checkDroppable(instance Instance, confirm string) error {
+	if confirm != instance.SchemaName {
+		return fmt.Errorf("%w: confirmation must be the schema name %q", ErrDropNotConfirmed, instance.SchemaName)
+	}
+	if instance.ServiceName == instance.InstanceName {
+		return fmt.Errorf("instance %s is a singleton; only multi-instance storage can be dropped", instance.InstanceName)
+	}
+
+	schema := strings.ToLower(instance.SchemaName)
+	if schema == defaultSchemaName || schema == "public" || schema == "information_schema" || strings.HasPrefix(schema, "pg_") {
+		return fmt.Errorf("schema %s is shared; refusing to drop it", instance.SchemaName)
+	}
+	for _, namespace := range []string{instance.RedisNamespace, instance.CacheNamespace} {
+		if namespace == "" || namespace == defaultSchemaName || namespace == instance.ServiceDiscoveryNamespace {
+			return fmt.Errorf("Redis namespace %q is shared; refusing to delete it", namespace)
+		}
+	}
+	return nil
+}
+
+// deleteInstanceKeys deletes the instance's keys wherever they live and
+// returns how many
+func (p *InstanceProvisioner) deleteInstanceKeys(ctx context.Context, instance Instance) (int, error) {
+	patterns := []string{namespacePrefix(p.redis, instance.RedisNamespace) + "*"}
+	if instance.CacheNamespace != instance.RedisNamespace {
+		patterns = append(patterns, namespacePrefix(p.redis, instance.CacheNamespace)+"*")
+	}
+	cache := &RedisCacheRepository{client: p.redis, namespace: instance.CacheNamespace}
+	patterns = append(patterns, cache.lockKey("*"), cache.semaphoreKey("*"), cache.tagKey("*"), cache.tagInvalidatedKey("*"))
+
+	deleted := 0
+	for _, pattern := range patterns {
+		n, err := p.deletePattern(ctx, pattern)
+		deleted += n
+		if err != nil {
+			return deleted, err
+		}
+	}
+
+	discovery := &RedisServiceDiscovery{client: p.redis, namespace: instance.ServiceDiscoveryNamespace}
+	n, err := p.redis.Del(ctx, discovery.serviceKey(instance.InstanceName), discovery.heartbeatKey(instance.InstanceName)).Result()
+	if err != nil {
+		return deleted, fmt.Errorf("failed to delete service registration: %w", err)
+	}
+	return deleted + int(n), nil
+}
+
+// deletePattern deletes every key matching pattern and returns how many. On
+// a cluster a namespace's keys share a slot but SCAN is per node, so every
+// master is scanned.
+func (p *InstanceProvisioner) deletePattern(ctx context.Context, pattern string) (int, error) {
+	if cluster, ok := p.redis.(*redis.ClusterClient); ok {
+		var deleted atomic.Int64
+		err := cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+			n, err := deleteMatching(ctx, node, pattern)
+			deleted.Add(int64(n))
+			return err
+		})
+		return int(deleted.Load()), err
+	}
+	return deleteMatching(ctx, p.redis, pattern)
+}
+
+// deleteMatching scans client for keys matching pattern and deletes them in batches
+func deleteMatching(ctx context.Context, client redis.Cmdable, pattern string) (int, error) {
+	deleted := 0
+	var cursor uint64
+	for {
+		keys, next, err := client.Scan(ctx, cursor, pattern, dropScanCount).Result()
+		if err != nil {
+			return deleted, fmt.Errorf("failed to scan keys: %w", err)
+		}
+		if len(keys) > 0 {
+			n, err := client.Del(ctx, keys...).Result()
+			if err != nil {
+				return deleted, fmt.Errorf("failed to delete keys: %w", err)
+			}
+			deleted += int(n)
+		}
+		if next == 0 {
+			return deleted, nil
+		}
+		cursor = next
+	}
+}
@@ -0,0 +1,135 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/interfaces"
+)
+
+// TestDropInstanceGuards tests that DropInstance refuses unconfirmed and shared drops
+// before touching any store
+func TestDropInstanceGuards(t *testing.T) {
+	copper := Instance{ServiceName: "custodian-simulator", InstanceName: "custodian-Copper"}
+
+	tests := []struct {
+		name         string
+		instance     Instance
+		confirm      string
+		errorMsg     string
+		notConfirmed bool
+	}{
+		{
+			name:         "missing confirmation",
+			instance:     copper,
+			notConfirmed: true,
+		},
+		{
+			name:         "confirmation names another schema",
+			instance:     copper,
+			confirm:      "custodian_komainu",
+			notConfirmed: true,
+		},
+		{
+			name:     "singleton instance",
+			instance: Instance{ServiceName: "custodian-simulator", InstanceName: "custodian-simulator"},
+			confirm:  "custodian",
+			errorMsg: "singleton",
+		},
+		{
+			name:     "shared schema",
+			instance: Instance{ServiceName: "custodian-simulator", InstanceName: "custodian-Copper", SchemaName: "public"},
+			confirm:  "public",
+			errorMsg: "shared",
+		},
+		{
+			name:     "shared namespace",
+			instance: Instance{ServiceName: "custodian-simulator", InstanceName: "custodian-Copper", RedisNamespace: "custodian"},
+			confirm:  "custodian_copper",
+			errorMsg: "shared",
+		},
+		{
+			name:     "shared cache namespace",
+			instance: Instance{ServiceName: "custodian-simulator", InstanceName: "custodian-Copper", CacheNamespace: "custodian"},
+			confirm:  "custodian_copper",
+			errorMsg: "shared",
+		},
+		{
+			name:     "names required",
+			instance: Instance{InstanceName: "custodian-Copper"},
+			confirm:  "custodian_copper",
+			errorMsg: "required",
+		},
+	}
+
+	provisioner := NewInstanceProvisioner(nil, nil, nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := provisioner.DropInstance(context.Background(), tt.instance, tt.confirm)
+			if err == nil {
+				t.Fatal("DropInstance succeeded, expected it to be refused")
+			}
+			if tt.notConfirmed != errors.Is(err, ErrDropNotConfirmed) {
+				t.Errorf("DropInstance error = %v, ErrDropNotConfirmed expected: %v", err, tt.notConfirmed)
+			}
+			if tt.errorMsg != "" && !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("DropInstance error = %v, expected %q", err, tt.errorMsg)
+			}
+		})
+	}
+}
+
+// TestDeleteInstanceKeys tests that dropping an instance deletes its keys
+// in every namespace they live in and leaves other instances' keys alone
+func TestDeleteInstanceKeys(t *testing.T) {
+	server, client := newTestRedis(t)
+	ctx := context.Background()
+	logger := NewSlogLogger(nil)
+
+	copper, err := Instance{ServiceName: "custodian-simulator", InstanceName: "custodian-Copper"}.resolve()
+	if err != nil {
+		t.Fatalf("resolve failed: %v", err)
+	}
+	seed := func(instanceName, namespace string) {
+		if err := NewRedisCacheRepository(client, namespace, logger).SetWithTags(ctx, "position:id:1", "{}", time.Minute, "account:1"); err != nil {
+			t.Fatalf("SetWithTags failed: %v", err)
+		}
+		if _, err := NewRedisLockRepository(client, namespace, logger).TryLock(ctx, "settlement:1", time.Minute); err != nil {
+			t.Fatalf("TryLock failed: %v", err)
+		}
+		if _, err := NewRedisLockRepository(client, namespace, logger).TryAcquireSemaphore(ctx, "withdrawals", 1, time.Minute); err != nil {
+			t.Fatalf("TryAcquireSemaphore failed: %v", err)
+		}
+		server.Set(namespace+":leader:reconciler", instanceName)
+		info := &interfaces.ServiceInfo{ServiceName: "custodian-simulator", ServiceID: instanceName}
+		if err := NewRedisServiceDiscovery(client, defaultServiceDiscoveryNamespace, logger).Register(ctx, info); err != nil {
+			t.Fatalf("Register failed: %v", err)
+		}
+	}
+	seed("custodian-Copper", "custodian:Copper")
+	seed("custodian-Fireblocks", "custodian:Fireblocks")
+	fireblocks := server.Keys()
+
+	deleted, err := NewInstanceProvisioner(nil, client, logger).deleteInstanceKeys(ctx, copper)
+	if err != nil {
+		t.Fatalf("deleteInstanceKeys failed: %v", err)
+	}
+
+	remaining := server.Keys()
+	for _, key := range remaining {
+		if strings.Contains(key, "Copper") {
+			t.Errorf("key %s of the dropped instance survived", key)
+		}
+	}
+	if deleted+len(remaining) != len(fireblocks) {
+		t.Errorf("deleted %d of %d keys, leaving %v", deleted, len(fireblocks), remaining)
+	}
+	for _, key := range []string{"custodian:Fireblocks:position:id:1", "lock:custodian:Fireblocks:settlement:1", "custodian:service:custodian-Fireblocks"} {
+		if !server.Exists(key) {
+			t.Errorf("key %s of another instance was deleted", key)
+		}
+	}
+}
@@ -4,20 +4,20 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/interfaces"
 	"github.com/redis/go-redis/v9"
-	"github.com/sirupsen/logrus"
 )
 
 type RedisCacheRepository struct {
-	client    *redis.Client
+	client    redis.UniversalClient
 	namespace string
-	logger    *logrus.Logger
+	logger    interfaces.Logger
 }
 
-func NewRedisCacheRepository(client *redis.Client, namespace string, logger *logrus.Logger) interfaces.CacheRepository {
+func NewRedisCacheRepository(client redis.UniversalClient, namespace string, logger interfaces.Logger) interfaces.CacheRepository {
 	return &RedisCacheRepository{
 		client:    client,
 		namespace: namespace,
@@ -25,31 +25,49 @@ func NewRedisCacheRepository(client *redis.Client, namespace string, logger *log
 	}
 }
 
-func (r *RedisCacheRepository) keyWithNamespace(key string) string {
-	return fmt.Sprintf("%s:%s", r.namespace, key)
+// namespacePrefix starts every key in a namespace. On a cluster client the
+// namespace is a hash tag ("{ns}:"), so all of its keys share a slot and
+// multi-key commands, scripts and KEYS patterns stay on one node. Standalone
+// and sentinel deployments keep the plain "ns:" layout existing keys and
+// other consumers use.
+func namespacePrefix(client redis.UniversalClient, namespace string) string {
+	if _, ok := client.(*redis.ClusterClient); ok {
+		return fmt.Sprintf("{%s}:", namespace)
+	}
+	return namespace + ":"
 }
 
-func (r *RedisCacheRepository) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
-	fullKey := r.keyWithNamespace(key)
-
-	var data []byte
-	var err error
+func (r *RedisCacheRepository) keyWithNamespace(key string) string {
+	return namespacePrefix(r.client, r.namespace) + key
+}
 
+// encodeValue stores strings and byte slices as-is and everything else as JSON
+func (r *RedisCacheRepository) encodeValue(ctx context.Context, value interface{}) ([]byte, error) {
 	switch v := value.(type) {
 	case string:
-		data = []byte(v)
+		return []byte(v), nil
 	case []byte:
-		data = v
-	default:
-		data, err = json.Marshal(value)
-		if err != nil {
-			r.logger.WithError(err).Error("Failed to marshal value")
-			return fmt.Errorf("failed to marshal value: %w", err)
-		}
+		return v, nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		contextLogger(ctx, r.logger).WithError(err).Error("Failed to marshal value")
+		return nil, fmt.Errorf("failed to marshal value: %w", err)
+	}
+	return data, nil
+}
+
+func (r *RedisCacheRepository) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	fullKey := r.keyWithNamespace(key)
+
+	data, err := r.encodeValue(ctx, value)
+	if err != nil {
+		return err
 	}
 
 	if err := r.client.Set(ctx, fullKey, data, ttl).Err(); err != nil {
-		r.logger.WithError(err).WithField("key", fullKey).Error("Failed to set cache")
+		contextLogger(ctx, r.logger).WithError(err).WithField("key", fullKey).Error("Failed to set cache")
 		return fmt.Errorf("failed to set cache: %w", err)
 	}
 
@@ -61,10 +79,10 @@ func (r *RedisCacheRepository) Get(ctx context.Context, key string) (string, err
 
 	result, err := r.client.Get(ctx, fullKey).Result()
 	if err == redis.Nil {
-		return "", fmt.Errorf("key not found: %s", key)
+		return "", fmt.Errorf("%w: %s", interfaces.ErrCacheMiss, key)
 	}
 	if err != nil {
-		r.logger.WithError(err).WithField("key", fullKey).Error("Failed to get cache")
+		contextLogger(ctx, r.logger).WithError(err).WithField("key", fullKey).Error("Failed to get cache")
 		return "", fmt.Errorf("failed to get cache: %w", err)
 	}
 
@@ -75,7 +93,7 @@ func (r *RedisCacheRepository) Delete(ctx context.Context, key string) error {
 	fullKey := r.keyWithNamespace(key)
 
 	if err := r.client.Del(ctx, fullKey).Err(); err != nil {
-		r.logger.WithError(err).WithField("key", fullKey).Error("Failed to delete cache")
+		contextLogger(ctx, r.logger).WithError(err).WithField("key", fullKey).Error("Failed to delete cache")
 		return fmt.Errorf("failed to delete cache: %w", err)
 	}
 
@@ -87,7 +105,7 @@ func (r *RedisCacheRepository) Exists(ctx context.Context, key string) (bool, er
 
 	count, err := r.client.Exists(ctx, fullKey).Result()
 	if err != nil {
-		r.logger.WithError(err).WithField("key", fullKey).Error("Failed to check existence")
+		contextLogger(ctx, r.logger).WithError(err).WithField("key", fullKey).Error("Failed to check existence")
 		return false, fmt.Errorf("failed to check existence: %w", err)
 	}
 
@@ -98,27 +116,46 @@ func (r *RedisCacheRepository) Expire(ctx context.Context, key string, ttl time.
 	fullKey := r.keyWithNamespace(key)
 
 	if err := r.client.Expire(ctx, fullKey, ttl).Err(); err != nil {
-		r.logger.WithError(err).WithField("key", fullKey).Error("Failed to set expiration")
+		contextLogger(ctx, r.logger).WithError(err).WithField("key", fullKey).Error("Failed to set expiration")
 		return fmt.Errorf("failed to set expiration: %w", err)
 	}
 
 	return nil
 }
 
+func (r *RedisCacheRepository) TTL(ctx context.Context, key string) (time.Duration, error) {
+	fullKey := r.keyWithNamespace(key)
+
+	ttl, err := r.client.PTTL(ctx, fullKey).Result()
+	if err != nil {
+		contextLogger(ctx, r.logger).WithError(err).WithField("key", fullKey).Error("Failed to get TTL")
+		return 0, fmt.Errorf("failed to get TTL: %w", err)
+	}
+
+	// PTTL reports -2 for a missing key and -1 for a key without expiry
+	switch ttl {
+	case -2:
+		return 0, fmt.Errorf("%w: %s", interfaces.ErrCacheMiss, key)
+	case -1:
+		return -1, nil
+	}
+	return ttl, nil
+}
+
 func (r *RedisCacheRepository) Keys(ctx context.Context, pattern string) ([]string, error) {
 	fullPattern := r.keyWithNamespace(pattern)
 
 	keys, err := r.client.Keys(ctx, fullPattern).Result()
 	if err != nil {
-		r.logger.WithError(err).WithField("pattern", fullPattern).Error("Failed to get keys")
+		contextLogger(ctx, r.logger).WithError(err).WithField("pattern", fullPattern).Error("Failed to get keys")
 		return nil, fmt.Errorf("failed to get keys: %w", err)
 	}
 
 	// Remove namespace prefix from keys
-	namespacePrefix := r.namespace + ":"
+	prefix := namespacePrefix(r.client, r.namespace)
 	result := make([]string, len(keys))
 	for i, key := range keys {
-		result[i] = key[len(namespacePrefix):]
+		result[i] = key[len(prefix):]
 	}
 
 	return result, nil
@@ -141,13 +178,370 @@ func (r *RedisCacheRepository) DeletePattern(ctx context.Context, pattern string
 	}
 
 	if err := r.client.Del(ctx, fullKeys...).Err(); err != nil {
-		r.logger.WithError(err).WithField("pattern", pattern).Error("Failed to delete pattern")
+		contextLogger(ctx, r.logger).WithError(err).WithField("pattern", pattern).Error("Failed to delete pattern")
 		return fmt.Errorf("failed to delete pattern: %w", err)
 	}
 
 	return nil
 }
 
+func (r *RedisCacheRepository) MGet(ctx context.Context, keys []string) (map[string]string, error) {
+	result := make(map[string]string, len(keys))
+	if len(keys) == 0 {
+		return result, nil
+	}
+
+	fullKeys := make([]string, len(keys))
+	for i, key := range keys {
+		fullKeys[i] = r.keyWithNamespace(key)
+	}
+
+	values, err := r.client.MGet(ctx, fullKeys...).Result()
+	if err != nil {
+		contextLogger(ctx, r.logger).WithError(err).WithField("keys", len(keys)).Error("Failed to get multiple keys")
+		return nil, fmt.Errorf("failed to get multiple keys: %w", err)
+	}
+
+	for i, value := range values {
+		if s, ok := value.(string); ok {
+			result[keys[i]] = s
+		}
+	}
+
+	return result, nil
+}
+
+func (r *RedisCacheRepository) MSet(ctx context.Context, values map[string]interface{}, ttl time.Duration) error {
+	if len(values) == 0 {
+		return nil
+	}
+
+	pipe := r.client.Pipeline()
+	for key, value := range values {
+		data, err := r.encodeValue(ctx, value)
+		if err != nil {
+			return err
+		}
+		pipe.Set(ctx, r.keyWithNamespace(key), data, ttl)
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		contextLogger(ctx, r.logger).WithError(err).WithField("keys", len(values)).Error("Failed to set multiple keys")
+		return fmt.Errorf("failed to set multiple keys: %w", err)
+	}
+
+	return nil
+}
+
+func (r *RedisCacheRepository) SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	fullKey := r.keyWithNamespace(key)
+
+	data, err := r.encodeValue(ctx, value)
+	if err != nil {
+		return false, err
+	}
+
+	ok, err := r.client.SetNX(ctx, fullKey, data, ttl).Result()
+	if err != nil {
+		contextLogger(ctx, r.logger).WithError(err).WithField("key", fullKey).Error("Failed to set cache if absent")
+		return false, fmt.Errorf("failed to set cache if absent: %w", err)
+	}
+
+	return ok, nil
+}
+
+// compareAndSwapScript replaces the value only if it matches the expected one.
+// A TTL of 0 keeps the key's existing expiry.
+var compareAndSwapScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) ~= ARGV[1] then
+	return 0
+end
+if tonumber(ARGV[3]) > 0 then
+	redis.call("SET", KEYS[1], ARGV[2], "PX", ARGV[3])
+else
+	redis.call("SET", KEYS[1], ARGV[2], "KEEPTTL")
+end
+return 1
+`)
+
+func (r *RedisCacheRepository) CompareAndSwap(ctx context.Context, key, expected string, value interface{}, ttl time.Duration) (bool, error) {
+	fullKey := r.keyWithNamespace(key)
+
+	data, err := r.encodeValue(ctx, value)
+	if err != nil {
+		return false, err
+	}
+
+	swapped, err := compareAndSwapScript.Run(ctx, r.client, []string{fullKey}, expected, data, ttl.Milliseconds()).Int64()
+	if err != nil {
+		contextLogger(ctx, r.logger).WithError(err).WithField("key", fullKey).Error("Failed to compare and swap")
+		return false, fmt.Errorf("failed to compare and swap: %w", err)
+	}
+
+	return swapped == 1, nil
+}
+
+func (r *RedisCacheRepository) HSet(ctx context.Context, key string, fields map[string]interface{}) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	fullKey := r.keyWithNamespace(key)
+
+	values := make([]interface{}, 0, len(fields)*2)
+	for field, value := range fields {
+		data, err := r.encodeValue(ctx, value)
+		if err != nil {
+			return err
+		}
+		values = append(values, field, data)
+	}
+
+	if err := r.client.HSet(ctx, fullKey, values...).Err(); err != nil {
+		contextLogger(ctx, r.logger).WithError(err).WithField("key", fullKey).Error("Failed to set hash fields")
+		return fmt.Errorf("failed to set hash fields: %w", err)
+	}
+
+	return nil
+}
+
+func (r *RedisCacheRepository) HGet(ctx context.Context, key, field string) (string, error) {
+	fullKey := r.keyWithNamespace(key)
+
+	result, err := r.client.HGet(ctx, fullKey, field).Result()
+	if err == redis.Nil {
+		return "", fmt.Errorf("%w: %s[%s]", interfaces.ErrCacheMiss, key, field)
+	}
+	if err != nil {
+		contextLogger(ctx, r.logger).WithError(err).WithField("key", fullKey).Error("Failed to get hash field")
+		return "", fmt.Errorf("failed to get hash field: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *RedisCacheRepository) HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	fullKey := r.keyWithNamespace(key)
+
+	result, err := r.client.HGetAll(ctx, fullKey).Result()
+	if err != nil {
+		contextLogger(ctx, r.logger).WithError(err).WithField("key", fullKey).Error("Failed to get hash")
+		return nil, fmt.Errorf("failed to get hash: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *RedisCacheRepository) HDel(ctx context.Context, key string, fields ...string) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	fullKey := r.keyWithNamespace(key)
+
+	if err := r.client.HDel(ctx, fullKey, fields...).Err(); err != nil {
+		contextLogger(ctx, r.logger).WithError(err).WithField("key", fullKey).Error("Failed to delete hash fields")
+		return fmt.Errorf("failed to delete hash fields: %w", err)
+	}
+
+	return nil
+}
+
+// incrScript increments a counter and sets its TTL only when it has none, so
+// repeated increments do not keep pushing the expiry out. The reply is passed
+// through unchanged: an integer for INCRBY and a string for INCRBYFLOAT, as
+// Lua's tostring would render large integers in exponent notation.
+var incrScript = redis.NewScript(`
+local value = redis.call(ARGV[3], KEYS[1], ARGV[1])
+if tonumber(ARGV[2]) > 0 and redis.call("PTTL", KEYS[1]) < 0 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return value
+`)
+
+func (r *RedisCacheRepository) Incr(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error) {
+	fullKey := r.keyWithNamespace(key)
+
+	result, err := incrScript.Run(ctx, r.client, []string{fullKey}, delta, ttl.Milliseconds(), "INCRBY").Int64()
+	if err != nil {
+		contextLogger(ctx, r.logger).WithError(err).WithField("key", fullKey).Error("Failed to increment counter")
+		return 0, fmt.Errorf("failed to increment counter: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *RedisCacheRepository) IncrByFloat(ctx context.Context, key string, delta float64, ttl time.Duration) (float64, error) {
+	fullKey := r.keyWithNamespace(key)
+
+	result, err := incrScript.Run(ctx, r.client, []string{fullKey}, delta, ttl.Milliseconds(), "INCRBYFLOAT").Text()
+	if err != nil {
+		contextLogger(ctx, r.logger).WithError(err).WithField("key", fullKey).Error("Failed to increment counter")
+		return 0, fmt.Errorf("failed to increment counter: %w", err)
+	}
+
+	return strconv.ParseFloat(result, 64)
+}
+
+// tagKey holds the members of tag. Tag bookkeeping starts ahead of the
+// namespace, like locks, so Keys and DeletePattern never see it and user keys
+// cannot collide with it.
+func (r *RedisCacheRepository) tagKey(tag string) string {
+	return "tag:" + namespacePrefix(r.client, r.namespace) + tag
+}
+
+// tagInvalidatedKey records when tag was last invalidated, in Redis server
+// microseconds
+func (r *RedisCacheRepository) tagInvalidatedKey(tag string) string {
+	return "tag-invalidated:" + namespacePrefix(r.client, r.namespace) + tag
+}
+
+// tagFenceWindow is how long invalidation times are kept. A fill whose fence
+// is older than this cannot be checked and is not stored.
+const tagFenceWindow = 5 * time.Minute
+
+// tagFenceScript returns the server time of the namespace's node in
+// microseconds. It runs as a script on a namespace key so fences and
+// invalidation times come from the same clock on a cluster.
+var tagFenceScript = redis.NewScript(`
+local now = redis.call("TIME")
+return tonumber(now[1]) * 1000000 + tonumber(now[2])
+`)
+
+// setWithTagsScript stores the value and adds it to each tag set. A tag set
+// lives as long as its longest-lived member; untimed members make it persistent.
+// KEYS are the value, ARGV[4] tag sets and, with a fence, their invalidation
+// times. With ARGV[5] >= 0 nothing is stored if a tag was invalidated at or
+// after that fence, or if the fence is older than ARGV[6] microseconds.
+var setWithTagsScript = redis.NewScript(`
+local ttl = tonumber(ARGV[3])
+local tags = tonumber(ARGV[4])
+local fence = tonumber(ARGV[5])
+if fence >= 0 then
+	local now = redis.call("TIME")
+	if tonumber(now[1]) * 1000000 + tonumber(now[2]) - fence > tonumber(ARGV[6]) then
+		return 0
+	end
+	for i = tags + 2, 2 * tags + 1 do
+		local invalidated = redis.call("GET", KEYS[i])
+		if invalidated and tonumber(invalidated) >= fence then
+			return 0
+		end
+	end
+end
+if ttl > 0 then
+	redis.call("SET", KEYS[1], ARGV[1], "PX", ttl)
+else
+	redis.call("SET", KEYS[1], ARGV[1])
+end
+for i = 2, tags + 1 do
+	local current = redis.call("PTTL", KEYS[i])
+	redis.call("SADD", KEYS[i], ARGV[2])
+	if ttl <= 0 then
+		redis.call("PERSIST", KEYS[i])
+	elseif current == -2 or (current ~= -1 and current < ttl) then
+		redis.call("PEXPIRE", KEYS[i], ttl)
+	end
+end
+return 1
+`)
+
+// invalidateTagScript records the invalidation time for fenced fills, then
+// deletes every member key and the tag set itself. ARGV[1] is the namespace
+// prefix members are stored without, ARGV[2] how long to keep the time.
+var invalidateTagScript = redis.NewScript(`
+local now = redis.call("TIME")
+redis.call("SET", KEYS[2], string.format("%d%06d", tonumber(now[1]), tonumber(now[2])), "PX", ARGV[2])
+local members = redis.call("SMEMBERS", KEYS[1])
+for _, member in ipairs(members) do
+	redis.call("DEL", ARGV[1] .. member)
+end
+redis.call("DEL", KEYS[1])
+return members
+`)
+
+// pruneTagScript removes members whose keys no longer exist
+var pruneTagScript = redis.NewScript(`
+local removed = 0
+for _, member in ipairs(redis.call("SMEMBERS", KEYS[1])) do
+	if redis.call("EXISTS", ARGV[1] .. member) == 0 then
+		redis.call("SREM", KEYS[1], member)
+		removed = removed + 1
+	end
+end
+return removed
+`)
+
+// setWithTags runs setWithTagsScript, checking the tags against fence unless it is negative
+func (r *RedisCacheRepository) setWithTags(ctx context.Context, fence int64, key string, value interface{}, ttl time.Duration, tags []string) (bool, error) {
+	fullKey := r.keyWithNamespace(key)
+
+	data, err := r.encodeValue(ctx, value)
+	if err != nil {
+		return false, err
+	}
+
+	keys := make([]string, 0, 2*len(tags)+1)
+	keys = append(keys, fullKey)
+	for _, tag := range tags {
+		keys = append(keys, r.tagKey(tag))
+	}
+	if fence >= 0 {
+		for _, tag := range tags {
+			keys = append(keys, r.tagInvalidatedKey(tag))
+		}
+	}
+
+	stored, err := setWithTagsScript.Run(ctx, r.client, keys, data, key, ttl.Milliseconds(), len(tags), fence, tagFenceWindow.Microseconds()).Int()
+	if err != nil {
+		contextLogger(ctx, r.logger).WithError(err).WithField("key", fullKey).Error("Failed to set tagged cache")
+		return false, fmt.Errorf("failed to set tagged cache: %w", err)
+	}
+
+	return stored == 1, nil
+}
+
+func (r *RedisCacheRepository) SetWithTags(ctx context.Context, key string, value interface{}, ttl time.Duration, tags ...string) error {
+	_, err := r.setWithTags(ctx, -1, key, value, ttl, tags)
+	return err
+}
+
+func (r *RedisCacheRepository) TagFence(ctx context.Context) (int64, error) {
+	fence, err := tagFenceScript.Run(ctx, r.client, []string{r.keyWithNamespace("tag-fence")}).Int64()
+	if err != nil {
+		contextLogger(ctx, r.logger).WithError(err).Error("Failed to read tag fence")
+		return 0, fmt.Errorf("failed to read tag fence: %w", err)
+	}
+
+	return fence, nil
+}
+
+func (r *RedisCacheRepository) SetWithTagsSince(ctx context.Context, fence int64, key string, value interface{}, ttl time.Duration, tags ...string) (bool, error) {
+	if fence < 0 {
+		return false, fmt.Errorf("invalid tag fence %d", fence)
+	}
+	return r.setWithTags(ctx, fence, key, value, ttl, tags)
+}
+
+func (r *RedisCacheRepository) InvalidateTag(ctx context.Context, tag string) ([]string, error) {
+	keys := []string{r.tagKey(tag), r.tagInvalidatedKey(tag)}
+	members, err := invalidateTagScript.Run(ctx, r.client, keys, namespacePrefix(r.client, r.namespace), tagFenceWindow.Milliseconds()).StringSlice()
+	if err != nil {
+		contextLogger(ctx, r.logger).WithError(err).WithField("tag", tag).Error("Failed to invalidate tag")
+		return nil, fmt.Errorf("failed to invalidate tag: %w", err)
+	}
+
+	return members, nil
+}
+
+func (r *RedisCacheRepository) PruneTag(ctx context.Context, tag string) (int, error) {
+	removed, err := pruneTagScript.Run(ctx, r.client, []string{r.tagKey(tag)}, namespacePrefix(r.client, r.namespace)).Int()
+	if err != nil {
+		contextLogger(ctx, r.logger).WithError(err).WithField("tag", tag).Error("Failed to prune tag")
+		return 0, fmt.Errorf("failed to prune tag: %w", err)
+	}
+
+	return removed, nil
+}
+
 func (r *RedisCacheRepository) HealthCheck(ctx context.Context) error {
 	if err := r.client.Ping(ctx).Err(); err != nil {
 		return fmt.Errorf("cache health check failed: %w", err)
@@ -0,0 +1,348 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/interfaces"
+	"github.com/redis/go-redis/v9"
+	"github.com/redis/go-redis/v9/maintnotifications"
+)
+
+// TestNamespacePrefix tests that only cluster clients hash-tag the namespace
+func TestNamespacePrefix(t *testing.T) {
+	standalone := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+	defer standalone.Close()
+	failover := redis.NewFailoverClient(&redis.FailoverOptions{MasterName: "mymaster", SentinelAddrs: []string{"localhost:26379"}})
+	defer failover.Close()
+	cluster := redis.NewClusterClient(&redis.ClusterOptions{Addrs: []string{"localhost:7000"}})
+	defer cluster.Close()
+
+	tests := []struct {
+		name     string
+		client   redis.UniversalClient
+		expected string
+	}{
+		{name: "standalone", client: standalone, expected: "custodian:"},
+		{name: "sentinel", client: failover, expected: "custodian:"},
+		{name: "cluster", client: cluster, expected: "{custodian}:"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if prefix := namespacePrefix(tt.client, "custodian"); prefix != tt.expected {
+				t.Errorf("namespacePrefix() = %q, expected %q", prefix, tt.expected)
+			}
+		})
+	}
+}
+
+// newTestRedis starts an in-memory Redis for the duration of the test
+func newTestRedis(t *testing.T) (*miniredis.Miniredis, redis.UniversalClient) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{
+		Addr:                     server.Addr(),
+		MaintNotificationsConfig: &maintnotifications.Config{Mode: maintnotifications.ModeDisabled},
+	})
+	t.Cleanup(func() { client.Close() })
+	return server, client
+}
+
+// TestRedisCacheRepositoryTags tests tag sets, their lifetime, invalidation and pruning
+func TestRedisCacheRepositoryTags(t *testing.T) {
+	server, client := newTestRedis(t)
+	cache := NewRedisCacheRepository(client, "custodian", NewSlogLogger(nil))
+	ctx := context.Background()
+
+	if err := cache.SetWithTags(ctx, "short", "a", time.Minute, "account:1"); err != nil {
+		t.Fatalf("SetWithTags failed: %v", err)
+	}
+	if err := cache.SetWithTags(ctx, "long", "b", time.Hour, "account:1", "account:2"); err != nil {
+		t.Fatalf("SetWithTags failed: %v", err)
+	}
+	if ttl := server.TTL("tag:custodian:account:1"); ttl != time.Hour {
+		t.Errorf("tag set TTL = %v, expected the longest member's %v", ttl, time.Hour)
+	}
+
+	// Tag bookkeeping is outside the user keyspace
+	if err := cache.Set(ctx, "tag:account:1", "user value", time.Minute); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if keys, err := cache.Keys(ctx, "*"); err != nil || len(keys) != 3 {
+		t.Errorf("Keys(*) = %v, %v, expected only the user keys", keys, err)
+	}
+	if err := cache.DeletePattern(ctx, "tag:*"); err != nil {
+		t.Fatalf("DeletePattern failed: %v", err)
+	}
+	if !server.Exists("tag:custodian:account:1") {
+		t.Fatal("DeletePattern deleted a tag set")
+	}
+
+	if err := cache.Delete(ctx, "short"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if removed, err := cache.PruneTag(ctx, "account:1"); err != nil || removed != 1 {
+		t.Errorf("PruneTag = %d, %v, expected 1 removed", removed, err)
+	}
+
+	keys, err := cache.InvalidateTag(ctx, "account:1")
+	if err != nil {
+		t.Fatalf("InvalidateTag failed: %v", err)
+	}
+	if len(keys) != 1 || keys[0] != "long" {
+		t.Errorf("InvalidateTag = %v, expected [long]", keys)
+	}
+	if _, err := cache.Get(ctx, "long"); !errors.Is(err, interfaces.ErrCacheMiss) {
+		t.Errorf("invalidated key still cached: %v", err)
+	}
+	if server.Exists("tag:custodian:account:1") {
+		t.Errorf("tag set survived invalidation")
+	}
+}
+
+// TestRedisCacheRepositoryTagFence tests that a fill is dropped if one of its
+// tags was invalidated after the fill's fence was taken
+func TestRedisCacheRepositoryTagFence(t *testing.T) {
+	server, client := newTestRedis(t)
+	cache := NewRedisCacheRepository(client, "custodian", NewSlogLogger(nil))
+	ctx := context.Background()
+	now := time.Now()
+	server.SetTime(now)
+
+	stale, err := cache.TagFence(ctx)
+	if err != nil {
+		t.Fatalf("TagFence failed: %v", err)
+	}
+
+	server.SetTime(now.Add(time.Millisecond))
+	if _, err := cache.InvalidateTag(ctx, "position:1"); err != nil {
+		t.Fatalf("InvalidateTag failed: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		fence  int64
+		tags   []string
+		stored bool
+	}{
+		{name: "tag invalidated after fence", fence: stale, tags: []string{"account:1", "position:1"}},
+		{name: "other tags", fence: stale, tags: []string{"account:1", "position:2"}, stored: true},
+		{name: "fence after invalidation", fence: now.Add(2 * time.Millisecond).UnixMicro(), tags: []string{"position:1"}, stored: true},
+		{name: "fence outside window", fence: now.Add(-tagFenceWindow).UnixMicro(), tags: []string{"position:2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server.SetTime(now.Add(3 * time.Millisecond))
+			stored, err := cache.SetWithTagsSince(ctx, tt.fence, "key", "value", time.Minute, tt.tags...)
+			if err != nil {
+				t.Fatalf("SetWithTagsSince failed: %v", err)
+			}
+			if stored != tt.stored {
+				t.Errorf("stored = %v, expected %v", stored, tt.stored)
+			}
+			if server.Exists("custodian:key") != tt.stored {
+				t.Errorf("key exists = %v, expected %v", server.Exists("custodian:key"), tt.stored)
+			}
+			server.Del("custodian:key")
+		})
+	}
+}
+
+// TestRedisCacheRepositoryBatch tests MSet and MGet, including missing keys
+func TestRedisCacheRepositoryBatch(t *testing.T) {
+	server, client := newTestRedis(t)
+	cache := NewRedisCacheRepository(client, "custodian", NewSlogLogger(nil))
+	ctx := context.Background()
+
+	if err := cache.MSet(ctx, map[string]interface{}{
+		"rate:BTC": "65000",
+		"limits":   map[string]int{"daily": 10},
+	}, time.Minute); err != nil {
+		t.Fatalf("MSet failed: %v", err)
+	}
+	for _, key := range []string{"custodian:rate:BTC", "custodian:limits"} {
+		if ttl := server.TTL(key); ttl != time.Minute {
+			t.Errorf("TTL of %s = %v, expected %v", key, ttl, time.Minute)
+		}
+	}
+
+	values, err := cache.MGet(ctx, []string{"rate:BTC", "limits", "rate:ETH"})
+	if err != nil {
+		t.Fatalf("MGet failed: %v", err)
+	}
+	expected := map[string]string{"rate:BTC": "65000", "limits": `{"daily":10}`}
+	if len(values) != len(expected) {
+		t.Errorf("MGet = %v, expected %v", values, expected)
+	}
+	for key, value := range expected {
+		if values[key] != value {
+			t.Errorf("MGet[%s] = %q, expected %q", key, values[key], value)
+		}
+	}
+}
+
+// TestRedisCacheRepositorySetNX tests that SetNX only writes absent keys
+func TestRedisCacheRepositorySetNX(t *testing.T) {
+	server, client := newTestRedis(t)
+	cache := NewRedisCacheRepository(client, "custodian", NewSlogLogger(nil))
+	ctx := context.Background()
+
+	if ok, err := cache.SetNX(ctx, "idempotency:1", "first", time.Minute); err != nil || !ok {
+		t.Fatalf("SetNX on absent key = %v, %v, expected true", ok, err)
+	}
+	if ok, err := cache.SetNX(ctx, "idempotency:1", "second", time.Hour); err != nil || ok {
+		t.Fatalf("SetNX on present key = %v, %v, expected false", ok, err)
+	}
+	if value, _ := server.Get("custodian:idempotency:1"); value != "first" {
+		t.Errorf("value = %q, expected the first write", value)
+	}
+	if ttl := server.TTL("custodian:idempotency:1"); ttl != time.Minute {
+		t.Errorf("TTL = %v, expected the first write's %v", ttl, time.Minute)
+	}
+}
+
+// TestRedisCacheRepositoryCompareAndSwap tests that values are only replaced
+// when they match and that a TTL of 0 keeps the existing expiry
+func TestRedisCacheRepositoryCompareAndSwap(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		current  string
+		expected string
+		ttl      time.Duration
+		swapped  bool
+		value    string
+		keyTTL   time.Duration
+	}{
+		{name: "match with new TTL", current: "v1", expected: "v1", ttl: time.Hour, swapped: true, value: "v2", keyTTL: time.Hour},
+		{name: "match keeps TTL", current: "v1", expected: "v1", swapped: true, value: "v2", keyTTL: time.Minute},
+		{name: "mismatch", current: "v1", expected: "v0", ttl: time.Hour, value: "v1", keyTTL: time.Minute},
+		{name: "missing key", expected: "v1", ttl: time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, client := newTestRedis(t)
+			cache := NewRedisCacheRepository(client, "custodian", NewSlogLogger(nil))
+			if tt.current != "" {
+				server.Set("custodian:status", tt.current)
+				server.SetTTL("custodian:status", time.Minute)
+			}
+
+			swapped, err := cache.CompareAndSwap(ctx, "status", tt.expected, "v2", tt.ttl)
+			if err != nil {
+				t.Fatalf("CompareAndSwap failed: %v", err)
+			}
+			if swapped != tt.swapped {
+				t.Errorf("swapped = %v, expected %v", swapped, tt.swapped)
+			}
+			if tt.value == "" {
+				if server.Exists("custodian:status") {
+					t.Error("CompareAndSwap created a missing key")
+				}
+				return
+			}
+			if value, _ := server.Get("custodian:status"); value != tt.value {
+				t.Errorf("value = %q, expected %q", value, tt.value)
+			}
+			if ttl := server.TTL("custodian:status"); ttl != tt.keyTTL {
+				t.Errorf("TTL = %v, expected %v", ttl, tt.keyTTL)
+			}
+		})
+	}
+}
+
+// TestRedisCacheRepositoryHash tests setting, reading and deleting hash fields
+func TestRedisCacheRepositoryHash(t *testing.T) {
+	_, client := newTestRedis(t)
+	cache := NewRedisCacheRepository(client, "custodian", NewSlogLogger(nil))
+	ctx := context.Background()
+
+	if err := cache.HSet(ctx, "account:1", map[string]interface{}{
+		"status": "ACTIVE",
+		"limits": map[string]int{"daily": 10},
+	}); err != nil {
+		t.Fatalf("HSet failed: %v", err)
+	}
+	if value, err := cache.HGet(ctx, "account:1", "limits"); err != nil || value != `{"daily":10}` {
+		t.Errorf("HGet = %q, %v, expected JSON encoded limits", value, err)
+	}
+
+	if err := cache.HDel(ctx, "account:1", "limits"); err != nil {
+		t.Fatalf("HDel failed: %v", err)
+	}
+	if _, err := cache.HGet(ctx, "account:1", "limits"); !errors.Is(err, interfaces.ErrCacheMiss) {
+		t.Errorf("HGet of deleted field error = %v, expected ErrCacheMiss", err)
+	}
+	fields, err := cache.HGetAll(ctx, "account:1")
+	if err != nil {
+		t.Fatalf("HGetAll failed: %v", err)
+	}
+	if len(fields) != 1 || fields["status"] != "ACTIVE" {
+		t.Errorf("HGetAll = %v, expected only status", fields)
+	}
+}
+
+// TestRedisCacheRepositoryIncr tests counters and that their TTL is set
+// only when the counter has none
+func TestRedisCacheRepositoryIncr(t *testing.T) {
+	server, client := newTestRedis(t)
+	cache := NewRedisCacheRepository(client, "custodian", NewSlogLogger(nil))
+	ctx := context.Background()
+
+	if count, err := cache.Incr(ctx, "withdrawals", 2, time.Minute); err != nil || count != 2 {
+		t.Fatalf("Incr = %d, %v, expected 2", count, err)
+	}
+	server.FastForward(30 * time.Second)
+	if count, err := cache.Incr(ctx, "withdrawals", 3, time.Minute); err != nil || count != 5 {
+		t.Fatalf("Incr = %d, %v, expected 5", count, err)
+	}
+	if ttl := server.TTL("custodian:withdrawals"); ttl != 30*time.Second {
+		t.Errorf("TTL = %v, expected the first increment's expiry to be kept", ttl)
+	}
+	server.FastForward(30 * time.Second)
+	if count, err := cache.Incr(ctx, "withdrawals", 1, time.Minute); err != nil || count != 1 {
+		t.Errorf("Incr after expiry = %d, %v, expected a new counter", count, err)
+	}
+
+	// A counter created without a TTL gets one on its next increment
+	if _, err := cache.Incr(ctx, "deposits", 1, 0); err != nil {
+		t.Fatalf("Incr failed: %v", err)
+	}
+	if ttl := server.TTL("custodian:deposits"); ttl != 0 {
+		t.Errorf("TTL = %v, expected none", ttl)
+	}
+	if _, err := cache.Incr(ctx, "deposits", 1, time.Minute); err != nil {
+		t.Fatalf("Incr failed: %v", err)
+	}
+	if ttl := server.TTL("custodian:deposits"); ttl != time.Minute {
+		t.Errorf("TTL = %v, expected %v", ttl, time.Minute)
+	}
+
+	// INCRBY replies pass through as integers; Redis's Lua would render
+	// large counters in exponent notation if they were formatted as strings
+	if count, err := cache.Incr(ctx, "notional", 123456789012345, time.Minute); err != nil || count != 123456789012345 {
+		t.Errorf("Incr = %d, %v, expected 123456789012345", count, err)
+	}
+	reply, err := incrScript.Run(ctx, client, []string{"custodian:notional"}, 1, 0, "INCRBY").Result()
+	if _, ok := reply.(int64); err != nil || !ok {
+		t.Errorf("INCRBY reply = %#v, %v, expected an integer", reply, err)
+	}
+
+	if total, err := cache.IncrByFloat(ctx, "volume", 1.5, time.Minute); err != nil || total != 1.5 {
+		t.Fatalf("IncrByFloat = %v, %v, expected 1.5", total, err)
+	}
+	server.FastForward(10 * time.Second)
+	if total, err := cache.IncrByFloat(ctx, "volume", -0.25, time.Minute); err != nil || total != 1.25 {
+		t.Errorf("IncrByFloat = %v, %v, expected 1.25", total, err)
+	}
+	if ttl := server.TTL("custodian:volume"); ttl != 50*time.Second {
+		t.Errorf("TTL = %v, expected the first increment's expiry to be kept", ttl)
+	}
+}
@@ -0,0 +1,100 @@
+package adapters
+
+// matchGlob reports whether s matches pattern with the glob rules Redis uses
+// for KEYS, SCAN MATCH and PSUBSCRIBE, so local caches drop the same keys a
+// pattern deletes in Redis. Unlike path.Match, '*' and '?' also match '/',
+// and malformed patterns never fail: an unterminated class ends at the end of
+// the pattern and a trailing backslash matches itself.
+func matchGlob(pattern, s string) bool {
+	p, i := 0, 0
+	starP, starI := -1, 0
+
+	for i < len(s) {
+		if p < len(pattern) {
+			switch pattern[p] {
+			case '*':
+				starP, starI = p, i
+				p++
+				continue
+			case '?':
+				p++
+				i++
+				continue
+			case '[':
+				if matched, next := matchGlobClass(pattern, p, s[i]); matched {
+					p = next
+					i++
+					continue
+				}
+			case '\\':
+				literal, next := byte('\\'), p+1
+				if p+1 < len(pattern) {
+					literal, next = pattern[p+1], p+2
+				}
+				if s[i] == literal {
+					p = next
+					i++
+					continue
+				}
+			default:
+				if pattern[p] == s[i] {
+					p++
+					i++
+					continue
+				}
+			}
+		}
+
+		// Let the last '*' absorb one more byte and retry from there
+		if starP < 0 {
+			return false
+		}
+		starI++
+		p, i = starP+1, starI
+	}
+
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
+
+// matchGlobClass matches c against the class starting at pattern[p] == '['
+// and returns the index just past it. Classes support '^' negation, ranges in
+// either order and backslash escapes.
+func matchGlobClass(pattern string, p int, c byte) (bool, int) {
+	p++
+	negate := p < len(pattern) && pattern[p] == '^'
+	if negate {
+		p++
+	}
+
+	matched := false
+	for ; p < len(pattern) && pattern[p] != ']'; p++ {
+		switch {
+		case pattern[p] == '\\' && p+1 < len(pattern):
+			p++
+			if pattern[p] == c {
+				matched = true
+			}
+		case p+2 < len(pattern) && pattern[p+1] == '-':
+			lo, hi := pattern[p], pattern[p+2]
+			if lo > hi {
+				lo, hi = hi, lo
+			}
+			if c >= lo && c <= hi {
+				matched = true
+			}
+			p += 2
+		default:
+			if pattern[p] == c {
+				matched = true
+			}
+		}
+	}
+	if p < len(pattern) {
+		p++
+	}
+
+	return matched != negate, p
+}
@@ -0,0 +1,48 @@
+package adapters
+
+import "testing"
+
+// TestMatchGlob tests the Redis glob rules, including where they differ from path.Match
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		s       string
+		matched bool
+	}{
+		{pattern: "balance:*", s: "balance:ACC-1:USD", matched: true},
+		{pattern: "balance:*", s: "position:ACC-1", matched: false},
+		{pattern: "account:*", s: "account:desk/eu/1", matched: true},
+		{pattern: "account:?/1", s: "account:a/1", matched: true},
+		{pattern: "account?1", s: "account/1", matched: true},
+		{pattern: "*", s: "", matched: true},
+		{pattern: "*:USD", s: "balance:ACC-1:USD", matched: true},
+		{pattern: "*a*b*c", s: "xaybzc", matched: true},
+		{pattern: "*a*b*c", s: "xaybzcd", matched: false},
+		{pattern: "a**", s: "abc", matched: true},
+		{pattern: "ab", s: "abc", matched: false},
+		{pattern: "abc", s: "ab", matched: false},
+		{pattern: "h[ae]llo", s: "hello", matched: true},
+		{pattern: "h[ae]llo", s: "hillo", matched: false},
+		{pattern: "h[^e]llo", s: "hallo", matched: true},
+		{pattern: "h[^e]llo", s: "hello", matched: false},
+		{pattern: "h[a-c]llo", s: "hbllo", matched: true},
+		{pattern: "h[c-a]llo", s: "hbllo", matched: true},
+		{pattern: "h[a-c]llo", s: "hdllo", matched: false},
+		{pattern: `h[\]]llo`, s: "h]llo", matched: true},
+		{pattern: "h[/]llo", s: "h/llo", matched: true},
+		{pattern: "h[ab", s: "ha", matched: true},
+		{pattern: "[]a", s: "a", matched: false},
+		{pattern: `balance\*`, s: "balance*", matched: true},
+		{pattern: `balance\*`, s: "balance:1", matched: false},
+		{pattern: `tag\?`, s: "tag?", matched: true},
+		{pattern: `end\`, s: `end\`, matched: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.s, func(t *testing.T) {
+			if matched := matchGlob(tt.pattern, tt.s); matched != tt.matched {
+				t.Errorf("matchGlob(%q, %q) = %v, expected %v", tt.pattern, tt.s, matched, tt.matched)
+			}
+		})
+	}
+}
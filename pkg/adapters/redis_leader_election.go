@@ -0,0 +1,313 @@
+package adapters
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/interfaces"
+	"github.com/redis/go-redis/v9"
+)
+
+// LeaderElectionConfig configures a RedisLeaderElection
+type LeaderElectionConfig struct {
+	// Unique identity of this candidate (defaults to hostname-pid-random)
+	HolderID string
+
+	// Lease duration; a leader that stops renewing loses the lease after this
+	LeaseTTL time.Duration
+
+	// How often the leader renews the lease (defaults to LeaseTTL/3)
+	RenewInterval time.Duration
+
+	// How often followers retry acquiring the lease (defaults to LeaseTTL/2)
+	RetryInterval time.Duration
+
+	// Called when leadership is acquired. ctx is cancelled when it is lost,
+	// at the latest when the last successfully renewed lease would expire.
+	OnElected func(ctx context.Context, fencingToken int64)
+
+	// Called when leadership is lost or released
+	OnRevoked func()
+}
+
+// acquireLeaseScript takes the lease if free and bumps the fencing counter.
+// The counter key has no TTL so tokens stay monotonic across terms.
+var acquireLeaseScript = redis.NewScript(`
+if redis.call("SET", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) then
+	return redis.call("INCR", KEYS[2])
+end
+return 0
+`)
+
+// renewLeaseScript extends the lease only if this candidate still holds it
+var renewLeaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// releaseLeaseScript deletes the lease only if this candidate still holds it
+var releaseLeaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+type RedisLeaderElection struct {
+	client    redis.UniversalClient
+	namespace string
+	election  string
+	config    LeaderElectionConfig
+	logger    interfaces.Logger
+
+	mu            sync.Mutex
+	token         int64
+	cancelTerm    context.CancelFunc
+	termExpiry    *time.Timer
+	resignedUntil time.Time
+}
+
+// errLeaseLost means another candidate holds the lease
+var errLeaseLost = errors.New("lease held by another candidate")
+
+// NewRedisLeaderElection creates a candidate for the named election. Elections
+// are scoped by namespace, so instances with different Redis namespaces elect
+// independently.
+func NewRedisLeaderElection(client redis.UniversalClient, namespace, election string, cfg LeaderElectionConfig, logger interfaces.Logger) (*RedisLeaderElection, error) {
+	if election == "" {
+		return nil, fmt.Errorf("election name is required")
+	}
+	if cfg.LeaseTTL <= 0 {
+		return nil, fmt.Errorf("lease TTL must be positive")
+	}
+	if cfg.RenewInterval <= 0 {
+		cfg.RenewInterval = cfg.LeaseTTL / 3
+	}
+	if cfg.RenewInterval >= cfg.LeaseTTL {
+		return nil, fmt.Errorf("renew interval (%s) must be shorter than lease TTL (%s)", cfg.RenewInterval, cfg.LeaseTTL)
+	}
+	if cfg.RetryInterval <= 0 {
+		cfg.RetryInterval = cfg.LeaseTTL / 2
+	}
+	if cfg.HolderID == "" {
+		holderID, err := defaultHolderID()
+		if err != nil {
+			return nil, err
+		}
+		cfg.HolderID = holderID
+	}
+
+	return &RedisLeaderElection{
+		client:    client,
+		namespace: namespace,
+		election:  election,
+		config:    cfg,
+		logger:    logger,
+	}, nil
+}
+
+func defaultHolderID() (string, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", fmt.Errorf("failed to generate holder ID: %w", err)
+	}
+	return fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), hex.EncodeToString(suffix)), nil
+}
+
+func (e *RedisLeaderElection) leaseKey() string {
+	return fmt.Sprintf("%sleader:%s", namespacePrefix(e.client, e.namespace), e.election)
+}
+
+func (e *RedisLeaderElection) tokenKey() string {
+	return fmt.Sprintf("%sleader:%s:token", namespacePrefix(e.client, e.namespace), e.election)
+}
+
+func (e *RedisLeaderElection) Run(ctx context.Context) error {
+	defer func() {
+		releaseCtx, cancel := context.WithTimeout(context.Background(), e.config.RenewInterval)
+		defer cancel()
+		if err := e.Resign(releaseCtx); err != nil {
+			e.logger.WithError(err).Warn("Failed to release leader lease")
+		}
+	}()
+
+	for {
+		interval := e.config.RetryInterval
+		if e.IsLeader() {
+			interval = e.config.RenewInterval
+			if err := e.renew(ctx); err != nil && ctx.Err() == nil {
+				// Transient Redis errors are tolerated until the term's expiry
+				// timer fires; losing ownership revokes immediately.
+				if errors.Is(err, errLeaseLost) {
+					e.logger.WithError(err).WithField("election", e.election).Warn("Lost leader lease")
+					e.revoke()
+				} else {
+					e.logger.WithError(err).WithField("election", e.election).Warn("Failed to renew leader lease")
+				}
+			}
+		} else if e.canCampaign() {
+			if err := e.tryAcquire(ctx); err != nil && ctx.Err() == nil {
+				e.logger.WithError(err).WithField("election", e.election).Warn("Failed to acquire leader lease")
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+func (e *RedisLeaderElection) canCampaign() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return time.Now().After(e.resignedUntil)
+}
+
+func (e *RedisLeaderElection) tryAcquire(ctx context.Context) error {
+	acquiredAt := time.Now()
+	token, err := acquireLeaseScript.Run(ctx, e.client,
+		[]string{e.leaseKey(), e.tokenKey()},
+		e.config.HolderID, e.config.LeaseTTL.Milliseconds(),
+	).Int64()
+	if err != nil {
+		return fmt.Errorf("failed to acquire lease: %w", err)
+	}
+	if token == 0 {
+		return nil
+	}
+
+	// The term ends when the lease would expire unless a renewal pushes it
+	// forward, so a leader cut off from Redis stops acting before another
+	// candidate can take over. Both times are taken before the request was
+	// sent, erring on the side of an early end.
+	termCtx, cancel := context.WithCancel(ctx)
+	e.mu.Lock()
+	e.token = token
+	e.cancelTerm = cancel
+	e.termExpiry = time.AfterFunc(time.Until(acquiredAt.Add(e.config.LeaseTTL)), func() { e.expire(token) })
+	e.mu.Unlock()
+
+	e.logger.WithFields(interfaces.LogFields{
+		"election":      e.election,
+		"holder_id":     e.config.HolderID,
+		"fencing_token": token,
+	}).Info("Elected leader")
+
+	if e.config.OnElected != nil {
+		go e.config.OnElected(termCtx, token)
+	}
+	return nil
+}
+
+func (e *RedisLeaderElection) renew(ctx context.Context) error {
+	renewedAt := time.Now()
+	renewed, err := renewLeaseScript.Run(ctx, e.client,
+		[]string{e.leaseKey()},
+		e.config.HolderID, e.config.LeaseTTL.Milliseconds(),
+	).Int64()
+	if err != nil {
+		return fmt.Errorf("failed to renew lease: %w", err)
+	}
+	if renewed == 0 {
+		return errLeaseLost
+	}
+
+	e.mu.Lock()
+	// A term that already expired locally stays over; the lease lapses and
+	// is won again with a new fencing token
+	if e.termExpiry != nil {
+		e.termExpiry.Reset(time.Until(renewedAt.Add(e.config.LeaseTTL)))
+	}
+	e.mu.Unlock()
+	return nil
+}
+
+// expire ends the term if it is still current once its lease has run out
+func (e *RedisLeaderElection) expire(token int64) {
+	if e.revokeTerm(token) {
+		e.logger.WithField("election", e.election).Warn("Leader lease expired without renewal")
+	}
+}
+
+// revoke clears local leadership state and notifies the callback
+func (e *RedisLeaderElection) revoke() {
+	e.revokeTerm(0)
+}
+
+// revokeTerm revokes the term with the given fencing token, or the current
+// term if token is 0, and reports whether a term was revoked
+func (e *RedisLeaderElection) revokeTerm(token int64) bool {
+	e.mu.Lock()
+	if token != 0 && e.token != token {
+		e.mu.Unlock()
+		return false
+	}
+	cancel := e.cancelTerm
+	wasLeader := e.token != 0
+	if e.termExpiry != nil {
+		e.termExpiry.Stop()
+	}
+	e.token = 0
+	e.cancelTerm = nil
+	e.termExpiry = nil
+	e.mu.Unlock()
+
+	if !wasLeader {
+		return false
+	}
+	if cancel != nil {
+		cancel()
+	}
+
+	e.logger.WithField("election", e.election).Info("Leadership revoked")
+	if e.config.OnRevoked != nil {
+		e.config.OnRevoked()
+	}
+	return true
+}
+
+func (e *RedisLeaderElection) Resign(ctx context.Context) error {
+	if !e.IsLeader() {
+		return nil
+	}
+
+	e.mu.Lock()
+	// Give other candidates a full lease period to take over
+	e.resignedUntil = time.Now().Add(e.config.LeaseTTL)
+	e.mu.Unlock()
+
+	e.revoke()
+
+	if err := releaseLeaseScript.Run(ctx, e.client, []string{e.leaseKey()}, e.config.HolderID).Err(); err != nil {
+		return fmt.Errorf("failed to release lease: %w", err)
+	}
+	return nil
+}
+
+func (e *RedisLeaderElection) IsLeader() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.token != 0
+}
+
+func (e *RedisLeaderElection) FencingToken() int64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.token
+}
+
+var _ interfaces.LeaderElector = (*RedisLeaderElection)(nil)
@@ -0,0 +1,156 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// newTestElection creates a candidate that sends each term's context on elected
+func newTestElection(t *testing.T, client redis.UniversalClient, holderID string) (*RedisLeaderElection, chan context.Context) {
+	t.Helper()
+	elected := make(chan context.Context, 4)
+	election, err := NewRedisLeaderElection(client, "custodian", "sweeper", LeaderElectionConfig{
+		HolderID:  holderID,
+		LeaseTTL:  200 * time.Millisecond,
+		OnElected: func(ctx context.Context, fencingToken int64) { elected <- ctx },
+	}, NewSlogLogger(nil))
+	if err != nil {
+		t.Fatalf("NewRedisLeaderElection failed: %v", err)
+	}
+	return election, elected
+}
+
+// awaitTerm returns the context of the next term, failing if none starts
+func awaitTerm(t *testing.T, elected chan context.Context) context.Context {
+	t.Helper()
+	select {
+	case termCtx := <-elected:
+		return termCtx
+	case <-time.After(time.Second):
+		t.Fatal("OnElected was not called")
+		return nil
+	}
+}
+
+// TestRedisLeaderElection tests acquiring, renewing and losing the lease
+func TestRedisLeaderElection(t *testing.T) {
+	server, client := newTestRedis(t)
+	ctx := context.Background()
+	leader, elected := newTestElection(t, client, "a")
+	follower, _ := newTestElection(t, client, "b")
+
+	if err := leader.tryAcquire(ctx); err != nil {
+		t.Fatalf("tryAcquire failed: %v", err)
+	}
+	termCtx := awaitTerm(t, elected)
+	if err := follower.tryAcquire(ctx); err != nil {
+		t.Fatalf("follower tryAcquire failed: %v", err)
+	}
+	if !leader.IsLeader() || follower.IsLeader() {
+		t.Fatalf("IsLeader = %v, %v, expected only the first candidate to lead", leader.IsLeader(), follower.IsLeader())
+	}
+	if holder, _ := server.Get("custodian:leader:sweeper"); holder != "a" {
+		t.Errorf("lease holder = %q, expected %q", holder, "a")
+	}
+
+	// Renewals push the term's end forward past the original lease
+	for i := 0; i < 4; i++ {
+		time.Sleep(100 * time.Millisecond)
+		if err := leader.renew(ctx); err != nil {
+			t.Fatalf("renew failed: %v", err)
+		}
+	}
+	if termCtx.Err() != nil || !leader.IsLeader() {
+		t.Fatal("term ended although the lease was renewed")
+	}
+
+	// Another holder owning the lease ends the term on the next renewal
+	server.Set("custodian:leader:sweeper", "b")
+	if err := leader.renew(ctx); !errors.Is(err, errLeaseLost) {
+		t.Fatalf("renew error = %v, expected errLeaseLost", err)
+	}
+	leader.revoke()
+	if termCtx.Err() == nil || leader.IsLeader() || leader.FencingToken() != 0 {
+		t.Error("term still live after the lease was lost")
+	}
+}
+
+// TestRedisLeaderElectionExpiry tests that a leader which cannot renew stops
+// acting once its lease runs out, before another candidate can win it
+func TestRedisLeaderElectionExpiry(t *testing.T) {
+	server, client := newTestRedis(t)
+	ctx := context.Background()
+	leader, elected := newTestElection(t, client, "a")
+
+	acquiredAt := time.Now()
+	if err := leader.tryAcquire(ctx); err != nil {
+		t.Fatalf("tryAcquire failed: %v", err)
+	}
+	termCtx := awaitTerm(t, elected)
+
+	server.SetError("LOADING Redis is loading the dataset in memory")
+	if err := leader.renew(ctx); err == nil || errors.Is(err, errLeaseLost) {
+		t.Fatalf("renew error = %v, expected a transient error", err)
+	}
+	if termCtx.Err() != nil {
+		t.Fatal("term ended on a transient renewal error")
+	}
+
+	select {
+	case <-termCtx.Done():
+		if held := time.Since(acquiredAt); held > 200*time.Millisecond+50*time.Millisecond {
+			t.Errorf("term ended %v after acquiring a 200ms lease", held)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("term outlived its lease")
+	}
+	if leader.IsLeader() {
+		t.Error("IsLeader = true after the lease expired")
+	}
+
+	// A renewal that succeeds after the term ended does not revive it
+	server.SetError("")
+	if err := leader.renew(ctx); err != nil {
+		t.Fatalf("renew failed: %v", err)
+	}
+	if leader.IsLeader() {
+		t.Error("IsLeader = true after renewing an expired term")
+	}
+}
+
+// TestRedisLeaderElectionFencing tests that fencing tokens increase across
+// terms and holders
+func TestRedisLeaderElectionFencing(t *testing.T) {
+	_, client := newTestRedis(t)
+	ctx := context.Background()
+	first, _ := newTestElection(t, client, "a")
+	second, _ := newTestElection(t, client, "b")
+
+	var tokens []int64
+	for _, candidate := range []*RedisLeaderElection{first, second, first} {
+		if err := candidate.tryAcquire(ctx); err != nil {
+			t.Fatalf("tryAcquire failed: %v", err)
+		}
+		token := candidate.FencingToken()
+		if token == 0 {
+			t.Fatalf("candidate %s was not elected", candidate.config.HolderID)
+		}
+		tokens = append(tokens, token)
+		if err := candidate.Resign(ctx); err != nil {
+			t.Fatalf("Resign failed: %v", err)
+		}
+		if candidate.FencingToken() != 0 {
+			t.Errorf("FencingToken = %d after resigning, expected 0", candidate.FencingToken())
+		}
+	}
+
+	for i := 1; i < len(tokens); i++ {
+		if tokens[i] <= tokens[i-1] {
+			t.Errorf("fencing tokens = %v, expected them to increase", tokens)
+		}
+	}
+}
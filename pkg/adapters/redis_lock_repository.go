@@ -0,0 +1,286 @@
+package adapters
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/interfaces"
+	"github.com/redis/go-redis/v9"
+)
+
+// Lock retry backoff bounds
+const (
+	lockRetryMinDelay = 10 * time.Millisecond
+	lockRetryMaxDelay = 250 * time.Millisecond
+)
+
+// unlockScript deletes the lock only if the caller's token still owns it
+var unlockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// extendLockScript extends the lock only if the caller's token still owns it
+var extendLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// Semaphore permits are members of a sorted set scored by their expiry in
+// Redis server milliseconds; expired permits are purged before each acquire.
+var acquireSemaphoreScript = redis.NewScript(`
+local t = redis.call("TIME")
+local now = t[1] * 1000 + math.floor(t[2] / 1000)
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", now)
+if redis.call("ZCARD", KEYS[1]) < tonumber(ARGV[2]) then
+	redis.call("ZADD", KEYS[1], now + tonumber(ARGV[3]), ARGV[1])
+	if redis.call("PTTL", KEYS[1]) < tonumber(ARGV[3]) then
+		redis.call("PEXPIRE", KEYS[1], ARGV[3])
+	end
+	return 1
+end
+return 0
+`)
+
+var extendSemaphoreScript = redis.NewScript(`
+local t = redis.call("TIME")
+local now = t[1] * 1000 + math.floor(t[2] / 1000)
+local score = redis.call("ZSCORE", KEYS[1], ARGV[1])
+if not score or tonumber(score) <= now then
+	redis.call("ZREM", KEYS[1], ARGV[1])
+	return 0
+end
+redis.call("ZADD", KEYS[1], now + tonumber(ARGV[2]), ARGV[1])
+if redis.call("PTTL", KEYS[1]) < tonumber(ARGV[2]) then
+	redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 1
+`)
+
+// NewRedisLockRepository creates a lock repository for namespace. Lock and
+// semaphore keys start with "lock:" and "semaphore:" ahead of the namespace,
+// so cache patterns and tag invalidation never match them.
+func NewRedisLockRepository(client redis.UniversalClient, namespace string, logger interfaces.Logger) interfaces.LockRepository {
+	return &RedisCacheRepository{
+		client:    client,
+		namespace: namespace,
+		logger:    logger,
+	}
+}
+
+func (r *RedisCacheRepository) lockKey(key string) string {
+	return "lock:" + namespacePrefix(r.client, r.namespace) + key
+}
+
+func (r *RedisCacheRepository) semaphoreKey(name string) string {
+	return "semaphore:" + namespacePrefix(r.client, r.namespace) + name
+}
+
+func (r *RedisCacheRepository) TryLock(ctx context.Context, key string, ttl time.Duration) (*interfaces.Lock, error) {
+	if ttl <= 0 {
+		return nil, fmt.Errorf("lock TTL must be positive")
+	}
+
+	token, err := newLockToken()
+	if err != nil {
+		return nil, err
+	}
+
+	acquiredAt := time.Now()
+	ok, err := r.client.SetNX(ctx, r.lockKey(key), token, ttl).Result()
+	if err != nil {
+		contextLogger(ctx, r.logger).WithError(err).WithField("key", key).Error("Failed to acquire lock")
+		return nil, fmt.Errorf("failed to acquire lock: %w", err)
+	}
+	if !ok {
+		return nil, interfaces.ErrLockNotAcquired
+	}
+
+	return &interfaces.Lock{
+		Key:       key,
+		Token:     token,
+		ExpiresAt: acquiredAt.Add(ttl),
+	}, nil
+}
+
+func (r *RedisCacheRepository) Lock(ctx context.Context, key string, ttl, timeout time.Duration) (*interfaces.Lock, error) {
+	var lock *interfaces.Lock
+	err := retryUntil(ctx, timeout, func() error {
+		var err error
+		lock, err = r.TryLock(ctx, key, ttl)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return lock, nil
+}
+
+func (r *RedisCacheRepository) Unlock(ctx context.Context, lock *interfaces.Lock) error {
+	released, err := unlockScript.Run(ctx, r.client, []string{r.lockKey(lock.Key)}, lock.Token).Int64()
+	if err != nil {
+		contextLogger(ctx, r.logger).WithError(err).WithField("key", lock.Key).Error("Failed to release lock")
+		return fmt.Errorf("failed to release lock: %w", err)
+	}
+	if released == 0 {
+		return interfaces.ErrLockNotHeld
+	}
+	return nil
+}
+
+func (r *RedisCacheRepository) ExtendLock(ctx context.Context, lock *interfaces.Lock, ttl time.Duration) error {
+	// The script's PEXPIRE would delete the lock for a TTL under 1ms
+	if ttl < time.Millisecond {
+		return fmt.Errorf("lock TTL must be at least 1ms")
+	}
+
+	extendedAt := time.Now()
+	extended, err := extendLockScript.Run(ctx, r.client, []string{r.lockKey(lock.Key)}, lock.Token, ttl.Milliseconds()).Int64()
+	if err != nil {
+		contextLogger(ctx, r.logger).WithError(err).WithField("key", lock.Key).Error("Failed to extend lock")
+		return fmt.Errorf("failed to extend lock: %w", err)
+	}
+	if extended == 0 {
+		return interfaces.ErrLockNotHeld
+	}
+
+	lock.ExpiresAt = extendedAt.Add(ttl)
+	return nil
+}
+
+func (r *RedisCacheRepository) TryAcquireSemaphore(ctx context.Context, name string, limit int, ttl time.Duration) (*interfaces.SemaphorePermit, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("semaphore limit must be positive")
+	}
+	if ttl <= 0 {
+		return nil, fmt.Errorf("semaphore TTL must be positive")
+	}
+
+	token, err := newLockToken()
+	if err != nil {
+		return nil, err
+	}
+
+	acquiredAt := time.Now()
+	acquired, err := acquireSemaphoreScript.Run(ctx, r.client, []string{r.semaphoreKey(name)}, token, limit, ttl.Milliseconds()).Int64()
+	if err != nil {
+		contextLogger(ctx, r.logger).WithError(err).WithField("semaphore", name).Error("Failed to acquire semaphore")
+		return nil, fmt.Errorf("failed to acquire semaphore: %w", err)
+	}
+	if acquired == 0 {
+		return nil, interfaces.ErrLockNotAcquired
+	}
+
+	return &interfaces.SemaphorePermit{
+		Name:      name,
+		Token:     token,
+		ExpiresAt: acquiredAt.Add(ttl),
+	}, nil
+}
+
+func (r *RedisCacheRepository) AcquireSemaphore(ctx context.Context, name string, limit int, ttl, timeout time.Duration) (*interfaces.SemaphorePermit, error) {
+	var permit *interfaces.SemaphorePermit
+	err := retryUntil(ctx, timeout, func() error {
+		var err error
+		permit, err = r.TryAcquireSemaphore(ctx, name, limit, ttl)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return permit, nil
+}
+
+func (r *RedisCacheRepository) ReleaseSemaphore(ctx context.Context, permit *interfaces.SemaphorePermit) error {
+	removed, err := r.client.ZRem(ctx, r.semaphoreKey(permit.Name), permit.Token).Result()
+	if err != nil {
+		contextLogger(ctx, r.logger).WithError(err).WithField("semaphore", permit.Name).Error("Failed to release semaphore")
+		return fmt.Errorf("failed to release semaphore: %w", err)
+	}
+	if removed == 0 {
+		return interfaces.ErrLockNotHeld
+	}
+	return nil
+}
+
+func (r *RedisCacheRepository) ExtendSemaphore(ctx context.Context, permit *interfaces.SemaphorePermit, ttl time.Duration) error {
+	// A permit extended by less than 1ms would already count as expired
+	if ttl < time.Millisecond {
+		return fmt.Errorf("semaphore TTL must be at least 1ms")
+	}
+
+	extendedAt := time.Now()
+	extended, err := extendSemaphoreScript.Run(ctx, r.client, []string{r.semaphoreKey(permit.Name)}, permit.Token, ttl.Milliseconds()).Int64()
+	if err != nil {
+		contextLogger(ctx, r.logger).WithError(err).WithField("semaphore", permit.Name).Error("Failed to extend semaphore")
+		return fmt.Errorf("failed to extend semaphore: %w", err)
+	}
+	if extended == 0 {
+		return interfaces.ErrLockNotHeld
+	}
+
+	permit.ExpiresAt = extendedAt.Add(ttl)
+	return nil
+}
+
+// retryUntil calls attempt with jittered exponential backoff while it returns
+// ErrLockNotAcquired, until timeout elapses or ctx is cancelled
+func retryUntil(ctx context.Context, timeout time.Duration, attempt func() error) error {
+	deadline := time.Now().Add(timeout)
+	delay := lockRetryMinDelay
+
+	for {
+		err := attempt()
+		if err != interfaces.ErrLockNotAcquired {
+			return err
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return err
+		}
+
+		wait := delay/2 + jitter(delay/2)
+		if wait > remaining {
+			wait = remaining
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+
+		delay *= 2
+		if delay > lockRetryMaxDelay {
+			delay = lockRetryMaxDelay
+		}
+	}
+}
+
+func jitter(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(max)))
+	if err != nil {
+		return 0
+	}
+	return time.Duration(n.Int64())
+}
+
+func newLockToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate lock token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,159 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/interfaces"
+)
+
+// TestRedisLockRepository tests lock ownership, release, extension and expiry
+func TestRedisLockRepository(t *testing.T) {
+	server, client := newTestRedis(t)
+	locks := NewRedisLockRepository(client, "custodian", NewSlogLogger(nil))
+	ctx := context.Background()
+
+	lock, err := locks.TryLock(ctx, "settlement:1", time.Second)
+	if err != nil {
+		t.Fatalf("TryLock failed: %v", err)
+	}
+	if _, err := locks.TryLock(ctx, "settlement:1", time.Second); !errors.Is(err, interfaces.ErrLockNotAcquired) {
+		t.Errorf("second TryLock error = %v, expected ErrLockNotAcquired", err)
+	}
+	if _, err := locks.Lock(ctx, "settlement:1", time.Second, 30*time.Millisecond); !errors.Is(err, interfaces.ErrLockNotAcquired) {
+		t.Errorf("Lock on held key error = %v, expected ErrLockNotAcquired after timeout", err)
+	}
+
+	for _, ttl := range []time.Duration{0, -time.Second, time.Microsecond} {
+		if err := locks.ExtendLock(ctx, lock, ttl); err == nil {
+			t.Errorf("ExtendLock with TTL %v succeeded, expected a validation error", ttl)
+		}
+	}
+	if !server.Exists("lock:custodian:settlement:1") {
+		t.Fatal("lock deleted by an invalid extension")
+	}
+
+	if err := locks.ExtendLock(ctx, lock, time.Minute); err != nil {
+		t.Fatalf("ExtendLock failed: %v", err)
+	}
+	if ttl := server.TTL("lock:custodian:settlement:1"); ttl != time.Minute {
+		t.Errorf("lock TTL = %v after extend, expected %v", ttl, time.Minute)
+	}
+
+	foreign := &interfaces.Lock{Key: lock.Key, Token: "other"}
+	if err := locks.Unlock(ctx, foreign); !errors.Is(err, interfaces.ErrLockNotHeld) {
+		t.Errorf("Unlock with foreign token error = %v, expected ErrLockNotHeld", err)
+	}
+	if err := locks.ExtendLock(ctx, foreign, time.Minute); !errors.Is(err, interfaces.ErrLockNotHeld) {
+		t.Errorf("ExtendLock with foreign token error = %v, expected ErrLockNotHeld", err)
+	}
+
+	if err := locks.Unlock(ctx, lock); err != nil {
+		t.Fatalf("Unlock failed: %v", err)
+	}
+	if err := locks.Unlock(ctx, lock); !errors.Is(err, interfaces.ErrLockNotHeld) {
+		t.Errorf("second Unlock error = %v, expected ErrLockNotHeld", err)
+	}
+
+	expiring, err := locks.TryLock(ctx, "settlement:2", time.Second)
+	if err != nil {
+		t.Fatalf("TryLock failed: %v", err)
+	}
+	server.FastForward(2 * time.Second)
+	if err := locks.ExtendLock(ctx, expiring, time.Second); !errors.Is(err, interfaces.ErrLockNotHeld) {
+		t.Errorf("ExtendLock after expiry error = %v, expected ErrLockNotHeld", err)
+	}
+	if _, err := locks.TryLock(ctx, "settlement:2", time.Second); err != nil {
+		t.Errorf("TryLock after expiry failed: %v", err)
+	}
+}
+
+// TestRedisLockRepositoryOutsideCache tests that cache patterns and tag
+// invalidation in the same namespace cannot see or delete live locks
+func TestRedisLockRepositoryOutsideCache(t *testing.T) {
+	_, client := newTestRedis(t)
+	locks := NewRedisLockRepository(client, "custodian", NewSlogLogger(nil))
+	cache := NewRedisCacheRepository(client, "custodian", NewSlogLogger(nil))
+	ctx := context.Background()
+
+	lock, err := locks.TryLock(ctx, "tag:account:1", time.Minute)
+	if err != nil {
+		t.Fatalf("TryLock failed: %v", err)
+	}
+	permit, err := locks.TryAcquireSemaphore(ctx, "withdrawals", 1, time.Minute)
+	if err != nil {
+		t.Fatalf("TryAcquireSemaphore failed: %v", err)
+	}
+
+	if keys, err := cache.Keys(ctx, "*"); err != nil || len(keys) != 0 {
+		t.Errorf("cache Keys(*) = %v, %v, expected no keys", keys, err)
+	}
+	if err := cache.DeletePattern(ctx, "*"); err != nil {
+		t.Fatalf("DeletePattern failed: %v", err)
+	}
+	if _, err := cache.InvalidateTag(ctx, "account:1"); err != nil {
+		t.Fatalf("InvalidateTag failed: %v", err)
+	}
+
+	if err := locks.Unlock(ctx, lock); err != nil {
+		t.Errorf("lock lost to cache deletes: %v", err)
+	}
+	if err := locks.ReleaseSemaphore(ctx, permit); err != nil {
+		t.Errorf("semaphore permit lost to cache deletes: %v", err)
+	}
+}
+
+// TestRedisSemaphore tests permit limits, release, extension and expiry
+func TestRedisSemaphore(t *testing.T) {
+	server, client := newTestRedis(t)
+	locks := NewRedisLockRepository(client, "custodian", NewSlogLogger(nil))
+	ctx := context.Background()
+	now := time.Now()
+	server.SetTime(now)
+
+	var permits []*interfaces.SemaphorePermit
+	for i := 0; i < 2; i++ {
+		permit, err := locks.TryAcquireSemaphore(ctx, "withdrawals", 2, time.Second)
+		if err != nil {
+			t.Fatalf("permit %d: TryAcquireSemaphore failed: %v", i, err)
+		}
+		permits = append(permits, permit)
+	}
+	if _, err := locks.TryAcquireSemaphore(ctx, "withdrawals", 2, time.Second); !errors.Is(err, interfaces.ErrLockNotAcquired) {
+		t.Errorf("permit over limit error = %v, expected ErrLockNotAcquired", err)
+	}
+
+	if err := locks.ReleaseSemaphore(ctx, permits[0]); err != nil {
+		t.Fatalf("ReleaseSemaphore failed: %v", err)
+	}
+	if err := locks.ReleaseSemaphore(ctx, permits[0]); !errors.Is(err, interfaces.ErrLockNotHeld) {
+		t.Errorf("second ReleaseSemaphore error = %v, expected ErrLockNotHeld", err)
+	}
+	replacement, err := locks.TryAcquireSemaphore(ctx, "withdrawals", 2, time.Second)
+	if err != nil {
+		t.Fatalf("TryAcquireSemaphore after release failed: %v", err)
+	}
+
+	for _, ttl := range []time.Duration{0, -time.Second} {
+		if err := locks.ExtendSemaphore(ctx, replacement, ttl); err == nil {
+			t.Errorf("ExtendSemaphore with TTL %v succeeded, expected a validation error", ttl)
+		}
+	}
+
+	// Only the extended permit outlives the original lease
+	if err := locks.ExtendSemaphore(ctx, replacement, time.Minute); err != nil {
+		t.Fatalf("ExtendSemaphore failed: %v", err)
+	}
+	server.SetTime(now.Add(2 * time.Second))
+	if err := locks.ExtendSemaphore(ctx, permits[1], time.Second); !errors.Is(err, interfaces.ErrLockNotHeld) {
+		t.Errorf("ExtendSemaphore after expiry error = %v, expected ErrLockNotHeld", err)
+	}
+	if _, err := locks.TryAcquireSemaphore(ctx, "withdrawals", 2, time.Second); err != nil {
+		t.Errorf("TryAcquireSemaphore with an expired permit failed: %v", err)
+	}
+	if _, err := locks.TryAcquireSemaphore(ctx, "withdrawals", 2, time.Second); !errors.Is(err, interfaces.ErrLockNotAcquired) {
+		t.Errorf("permit over limit error = %v, expected ErrLockNotAcquired", err)
+	}
+}
@@ -3,21 +3,21 @@ package adapters
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/interfaces"
 	"github.com/redis/go-redis/v9"
-	"github.com/sirupsen/logrus"
 )
 
 type RedisServiceDiscovery struct {
-	client    *redis.Client
+	client    redis.UniversalClient
 	namespace string
-	logger    *logrus.Logger
+	logger    interfaces.Logger
 }
 
-func NewRedisServiceDiscovery(client *redis.Client, namespace string, logger *logrus.Logger) interfaces.ServiceDiscoveryRepository {
+func NewRedisServiceDiscovery(client redis.UniversalClient, namespace string, logger interfaces.Logger) interfaces.ServiceDiscoveryRepository {
 	return &RedisServiceDiscovery{
 		client:    client,
 		namespace: namespace,
@@ -26,36 +26,45 @@ func NewRedisServiceDiscovery(client *redis.Client, namespace string, logger *lo
 }
 
 func (r *RedisServiceDiscovery) serviceKey(serviceID string) string {
-	return fmt.Sprintf("%s:service:%s", r.namespace, serviceID)
+	return fmt.Sprintf("%sservice:%s", namespacePrefix(r.client, r.namespace), serviceID)
 }
 
 func (r *RedisServiceDiscovery) heartbeatKey(serviceID string) string {
-	return fmt.Sprintf("%s:heartbeat:%s", r.namespace, serviceID)
+	return fmt.Sprintf("%sheartbeat:%s", namespacePrefix(r.client, r.namespace), serviceID)
 }
 
 func (r *RedisServiceDiscovery) Register(ctx context.Context, info *interfaces.ServiceInfo) error {
 	key := r.serviceKey(info.ServiceID)
 	heartbeatKey := r.heartbeatKey(info.ServiceID)
 
+	now := time.Now()
+	if info.Status == "" {
+		info.Status = interfaces.ServiceStatusHealthy
+	}
+	if info.RegisteredAt.IsZero() {
+		info.RegisteredAt = now
+	}
+	info.LastHeartbeat = now
+
 	data, err := json.Marshal(info)
 	if err != nil {
-		r.logger.WithError(err).Error("Failed to marshal service info")
+		contextLogger(ctx, r.logger).WithError(err).Error("Failed to marshal service info")
 		return fmt.Errorf("failed to marshal service info: %w", err)
 	}
 
 	// Set service info with 90s TTL
 	if err := r.client.Set(ctx, key, data, 90*time.Second).Err(); err != nil {
-		r.logger.WithError(err).Error("Failed to register service")
+		contextLogger(ctx, r.logger).WithError(err).Error("Failed to register service")
 		return fmt.Errorf("failed to register service: %w", err)
 	}
 
 	// Set initial heartbeat
-	if err := r.client.Set(ctx, heartbeatKey, time.Now().Unix(), 90*time.Second).Err(); err != nil {
-		r.logger.WithError(err).Error("Failed to set heartbeat")
+	if err := r.client.Set(ctx, heartbeatKey, now.Unix(), 90*time.Second).Err(); err != nil {
+		contextLogger(ctx, r.logger).WithError(err).Error("Failed to set heartbeat")
 		return fmt.Errorf("failed to set heartbeat: %w", err)
 	}
 
-	r.logger.WithField("service_id", info.ServiceID).Info("Service registered")
+	contextLogger(ctx, r.logger).WithField("service_id", info.ServiceID).Info("Service registered")
 	return nil
 }
 
@@ -64,39 +73,110 @@ func (r *RedisServiceDiscovery) Deregister(ctx context.Context, serviceID string
 	heartbeatKey := r.heartbeatKey(serviceID)
 
 	if err := r.client.Del(ctx, key, heartbeatKey).Err(); err != nil {
-		r.logger.WithError(err).Error("Failed to deregister service")
+		contextLogger(ctx, r.logger).WithError(err).Error("Failed to deregister service")
 		return fmt.Errorf("failed to deregister service: %w", err)
 	}
 
-	r.logger.WithField("service_id", serviceID).Info("Service deregistered")
+	contextLogger(ctx, r.logger).WithField("service_id", serviceID).Info("Service deregistered")
 	return nil
 }
 
 func (r *RedisServiceDiscovery) Heartbeat(ctx context.Context, serviceID string) error {
 	heartbeatKey := r.heartbeatKey(serviceID)
-	serviceKey := r.serviceKey(serviceID)
+	now := time.Now()
+
+	// Record the heartbeat in the registration and refresh its TTL
+	err := r.updateServiceInfo(ctx, serviceID, 90*time.Second, func(info *interfaces.ServiceInfo) {
+		info.LastHeartbeat = now
+	})
+	if errors.Is(err, interfaces.ErrNotFound) {
+		return err
+	}
+	if err != nil {
+		contextLogger(ctx, r.logger).WithError(err).Error("Failed to update heartbeat")
+		return fmt.Errorf("failed to update heartbeat: %w", err)
+	}
 
 	// Update heartbeat timestamp
-	if err := r.client.Set(ctx, heartbeatKey, time.Now().Unix(), 90*time.Second).Err(); err != nil {
-		r.logger.WithError(err).Error("Failed to update heartbeat")
+	if err := r.client.Set(ctx, heartbeatKey, now.Unix(), 90*time.Second).Err(); err != nil {
+		contextLogger(ctx, r.logger).WithError(err).Error("Failed to update heartbeat")
 		return fmt.Errorf("failed to update heartbeat: %w", err)
 	}
 
-	// Refresh service key TTL
-	if err := r.client.Expire(ctx, serviceKey, 90*time.Second).Err(); err != nil {
-		r.logger.WithError(err).Error("Failed to refresh service TTL")
-		return fmt.Errorf("failed to refresh service TTL: %w", err)
+	return nil
+}
+
+func (r *RedisServiceDiscovery) UpdateStatus(ctx context.Context, serviceID string, status interfaces.ServiceStatus) error {
+	// Keep the registration TTL so a stale instance still expires on schedule
+	err := r.updateServiceInfo(ctx, serviceID, redis.KeepTTL, func(info *interfaces.ServiceInfo) {
+		info.Status = status
+	})
+	if errors.Is(err, interfaces.ErrNotFound) {
+		return err
+	}
+	if err != nil {
+		contextLogger(ctx, r.logger).WithError(err).Error("Failed to update service status")
+		return fmt.Errorf("failed to update service status: %w", err)
 	}
 
+	contextLogger(ctx, r.logger).WithFields(interfaces.LogFields{
+		"service_id": serviceID,
+		"status":     status,
+	}).Info("Service status updated")
 	return nil
 }
 
+// maxServiceUpdateAttempts bounds retries of a registration update that
+// keeps racing other writers
+const maxServiceUpdateAttempts = 5
+
+// updateServiceInfo rewrites a registration with the given TTL after applying
+// update. The read and write run in a WATCH transaction that is retried if the
+// registration changed in between, so heartbeats and status changes do not
+// overwrite each other.
+func (r *RedisServiceDiscovery) updateServiceInfo(ctx context.Context, serviceID string, ttl time.Duration, update func(info *interfaces.ServiceInfo)) error {
+	key := r.serviceKey(serviceID)
+
+	for attempt := 0; attempt < maxServiceUpdateAttempts; attempt++ {
+		err := r.client.Watch(ctx, func(tx *redis.Tx) error {
+			data, err := tx.Get(ctx, key).Result()
+			if err == redis.Nil {
+				return fmt.Errorf("service %w: %s", interfaces.ErrNotFound, serviceID)
+			}
+			if err != nil {
+				return err
+			}
+
+			var info interfaces.ServiceInfo
+			if err := json.Unmarshal([]byte(data), &info); err != nil {
+				return fmt.Errorf("failed to unmarshal service info: %w", err)
+			}
+			update(&info)
+			updated, err := json.Marshal(&info)
+			if err != nil {
+				return fmt.Errorf("failed to marshal service info: %w", err)
+			}
+
+			_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+				pipe.Set(ctx, key, updated, ttl)
+				return nil
+			})
+			return err
+		}, key)
+		if err != redis.TxFailedErr {
+			return err
+		}
+	}
+
+	return fmt.Errorf("service %s changed concurrently %d times", serviceID, maxServiceUpdateAttempts)
+}
+
 func (r *RedisServiceDiscovery) Discover(ctx context.Context, serviceName string) ([]*interfaces.ServiceInfo, error) {
-	pattern := fmt.Sprintf("%s:service:*", r.namespace)
+	pattern := r.serviceKey("*")
 
 	keys, err := r.client.Keys(ctx, pattern).Result()
 	if err != nil {
-		r.logger.WithError(err).Error("Failed to discover services")
+		contextLogger(ctx, r.logger).WithError(err).Error("Failed to discover services")
 		return nil, fmt.Errorf("failed to discover services: %w", err)
 	}
 
@@ -104,13 +184,13 @@ func (r *RedisServiceDiscovery) Discover(ctx context.Context, serviceName string
 	for _, key := range keys {
 		data, err := r.client.Get(ctx, key).Result()
 		if err != nil {
-			r.logger.WithError(err).WithField("key", key).Warn("Failed to get service data")
+			contextLogger(ctx, r.logger).WithError(err).WithField("key", key).Warn("Failed to get service data")
 			continue
 		}
 
 		var info interfaces.ServiceInfo
 		if err := json.Unmarshal([]byte(data), &info); err != nil {
-			r.logger.WithError(err).WithField("key", key).Warn("Failed to unmarshal service info")
+			contextLogger(ctx, r.logger).WithError(err).WithField("key", key).Warn("Failed to unmarshal service info")
 			continue
 		}
 
@@ -122,21 +202,42 @@ func (r *RedisServiceDiscovery) Discover(ctx context.Context, serviceName string
 	return services, nil
 }
 
+func (r *RedisServiceDiscovery) Query(ctx context.Context, query *interfaces.DiscoverQuery) ([]*interfaces.ServiceInfo, error) {
+	matcher, err := newServiceMatcher(query)
+	if err != nil {
+		return nil, fmt.Errorf("invalid discover query: %w", err)
+	}
+
+	all, err := r.ListServices(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	services := []*interfaces.ServiceInfo{}
+	for _, info := range all {
+		if matcher.matches(info) {
+			services = append(services, info)
+		}
+	}
+
+	return services, nil
+}
+
 func (r *RedisServiceDiscovery) GetServiceInfo(ctx context.Context, serviceID string) (*interfaces.ServiceInfo, error) {
 	key := r.serviceKey(serviceID)
 
 	data, err := r.client.Get(ctx, key).Result()
 	if err == redis.Nil {
-		return nil, fmt.Errorf("service not found: %s", serviceID)
+		return nil, fmt.Errorf("service %w: %s", interfaces.ErrNotFound, serviceID)
 	}
 	if err != nil {
-		r.logger.WithError(err).Error("Failed to get service info")
+		contextLogger(ctx, r.logger).WithError(err).Error("Failed to get service info")
 		return nil, fmt.Errorf("failed to get service info: %w", err)
 	}
 
 	var info interfaces.ServiceInfo
 	if err := json.Unmarshal([]byte(data), &info); err != nil {
-		r.logger.WithError(err).Error("Failed to unmarshal service info")
+		contextLogger(ctx, r.logger).WithError(err).Error("Failed to unmarshal service info")
 		return nil, fmt.Errorf("failed to unmarshal service info: %w", err)
 	}
 
@@ -144,11 +245,11 @@ func (r *RedisServiceDiscovery) GetServiceInfo(ctx context.Context, serviceID st
 }
 
 func (r *RedisServiceDiscovery) ListServices(ctx context.Context) ([]*interfaces.ServiceInfo, error) {
-	pattern := fmt.Sprintf("%s:service:*", r.namespace)
+	pattern := r.serviceKey("*")
 
 	keys, err := r.client.Keys(ctx, pattern).Result()
 	if err != nil {
-		r.logger.WithError(err).Error("Failed to list services")
+		contextLogger(ctx, r.logger).WithError(err).Error("Failed to list services")
 		return nil, fmt.Errorf("failed to list services: %w", err)
 	}
 
@@ -156,13 +257,13 @@ func (r *RedisServiceDiscovery) ListServices(ctx context.Context) ([]*interfaces
 	for _, key := range keys {
 		data, err := r.client.Get(ctx, key).Result()
 		if err != nil {
-			r.logger.WithError(err).WithField("key", key).Warn("Failed to get service data")
+			contextLogger(ctx, r.logger).WithError(err).WithField("key", key).Warn("Failed to get service data")
 			continue
 		}
 
 		var info interfaces.ServiceInfo
 		if err := json.Unmarshal([]byte(data), &info); err != nil {
-			r.logger.WithError(err).WithField("key", key).Warn("Failed to unmarshal service info")
+			contextLogger(ctx, r.logger).WithError(err).WithField("key", key).Warn("Failed to unmarshal service info")
 			continue
 		}
 
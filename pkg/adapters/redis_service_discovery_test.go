@@ -0,0 +1,85 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/interfaces"
+)
+
+// TestRedisServiceDiscoveryHeartbeat tests that heartbeats advance the stored
+// last_heartbeat and TTL without overwriting status changes
+func TestRedisServiceDiscoveryHeartbeat(t *testing.T) {
+	server, client := newTestRedis(t)
+	discovery := NewRedisServiceDiscovery(client, "custodian", NewSlogLogger(nil))
+	ctx := context.Background()
+
+	if err := discovery.Register(ctx, &interfaces.ServiceInfo{ServiceName: "custodian", ServiceID: "custodian-1"}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	registered, err := discovery.GetServiceInfo(ctx, "custodian-1")
+	if err != nil {
+		t.Fatalf("GetServiceInfo failed: %v", err)
+	}
+	if err := discovery.UpdateStatus(ctx, "custodian-1", interfaces.ServiceStatusDraining); err != nil {
+		t.Fatalf("UpdateStatus failed: %v", err)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	server.FastForward(60 * time.Second)
+	if err := discovery.Heartbeat(ctx, "custodian-1"); err != nil {
+		t.Fatalf("Heartbeat failed: %v", err)
+	}
+
+	info, err := discovery.GetServiceInfo(ctx, "custodian-1")
+	if err != nil {
+		t.Fatalf("GetServiceInfo failed: %v", err)
+	}
+	if !info.LastHeartbeat.After(registered.LastHeartbeat) {
+		t.Errorf("last heartbeat = %v, expected it to advance past registration at %v", info.LastHeartbeat, registered.LastHeartbeat)
+	}
+	if !info.RegisteredAt.Equal(registered.RegisteredAt) {
+		t.Errorf("registered at = %v, expected %v", info.RegisteredAt, registered.RegisteredAt)
+	}
+	if info.Status != interfaces.ServiceStatusDraining {
+		t.Errorf("status = %q, expected the status update to survive the heartbeat", info.Status)
+	}
+	if ttl := server.TTL("custodian:service:custodian-1"); ttl != 90*time.Second {
+		t.Errorf("registration TTL = %v, expected it refreshed to 90s", ttl)
+	}
+
+	server.FastForward(91 * time.Second)
+	if err := discovery.Heartbeat(ctx, "custodian-1"); !errors.Is(err, interfaces.ErrNotFound) {
+		t.Errorf("Heartbeat after expiry error = %v, expected ErrNotFound", err)
+	}
+}
+
+// TestRedisServiceDiscoveryLegacyFormat tests that registrations written with
+// the Go field names by earlier releases are still discovered
+func TestRedisServiceDiscoveryLegacyFormat(t *testing.T) {
+	server, client := newTestRedis(t)
+	discovery := NewRedisServiceDiscovery(client, "custodian", NewSlogLogger(nil))
+	ctx := context.Background()
+
+	server.Set("custodian:service:custodian-0", `{"ServiceName":"custodian","ServiceID":"custodian-0","Address":"10.0.0.1","Port":8080,`+
+		`"Version":"1.0.0","Metadata":{"region":"eu"},"RegisteredAt":"2026-01-02T03:04:05Z","LastHeartbeat":"2026-01-02T03:05:05Z"}`)
+
+	services, err := discovery.Discover(ctx, "custodian")
+	if err != nil {
+		t.Fatalf("Discover failed: %v", err)
+	}
+	if len(services) != 1 {
+		t.Fatalf("Discover = %d services, expected the legacy registration", len(services))
+	}
+
+	info := services[0]
+	heartbeat := time.Date(2026, 1, 2, 3, 5, 5, 0, time.UTC)
+	if info.ServiceID != "custodian-0" || info.Address != "10.0.0.1" || info.Port != 8080 || info.Metadata["region"] != "eu" {
+		t.Errorf("service info = %+v, expected the legacy fields", info)
+	}
+	if info.RegisteredAt.IsZero() || !info.LastHeartbeat.Equal(heartbeat) {
+		t.Errorf("registered at = %v, last heartbeat = %v, expected the legacy times", info.RegisteredAt, info.LastHeartbeat)
+	}
+}
@@ -0,0 +1,207 @@
+package adapters
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/interfaces"
+)
+
+type primaryReadsKey struct{}
+
+// WithPrimaryReads returns a context whose repository reads go to the
+// primary, e.g. to read a record the caller has just written
+func WithPrimaryReads(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryReadsKey{}, true)
+}
+
+// primaryReadsForced reports whether ctx was marked by WithPrimaryReads
+func primaryReadsForced(ctx context.Context) bool {
+	forced, _ := ctx.Value(primaryReadsKey{}).(bool)
+	return forced
+}
+
+// replicaLagQuery reports how far a replica's replay is behind: 0 when it
+// is streaming and has replayed everything received (or is not a replica),
+// else the age of the last replayed transaction, or NULL if there is none.
+// A replica cut off from the primary has nothing left to replay, so without
+// a streaming WAL receiver the replay age is used, which grows while it is
+// cut off. Roles without pg_read_all_stats see a NULL status, so a receiver
+// row with a hidden status counts as streaming.
+const replicaLagQuery = `
+	SELECT CASE
+		WHEN NOT pg_is_in_recovery() THEN 0
+		WHEN EXISTS (SELECT 1 FROM pg_stat_wal_receiver WHERE COALESCE(status, 'streaming') = 'streaming')
+			AND pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+		ELSE EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp())
+	END
+`
+
+// defaultReplicaCheckInterval applies when no check interval is configured
+const defaultReplicaCheckInterval = 5 * time.Second
+
+// replica is a read-only PostgreSQL pool; it serves reads once a lag check
+// has passed
+type replica struct {
+	name   string
+	db     *sql.DB
+	usable atomic.Bool
+}
+
+// readRouter sends the read-only repository queries to replicas within the
+// staleness tolerance, round-robin, and to the primary otherwise. A query
+// that fails on a replica is retried on the primary and the replica is
+// skipped until its next successful check. Writes always use the primary.
+type readRouter struct {
+	primary  *sql.DB
+	replicas []*replica
+	next     atomic.Uint64
+
+	maxLag        time.Duration
+	checkInterval time.Duration
+	logger        interfaces.Logger
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// newPrimaryReads routes every read to db
+func newPrimaryReads(db *sql.DB) *readRouter {
+	return &readRouter{primary: db}
+}
+
+func newReadRouter(primary *sql.DB, replicas map[string]*sql.DB, maxLag, checkInterval time.Duration, logger interfaces.Logger) *readRouter {
+	rt := &readRouter{
+		primary:       primary,
+		maxLag:        maxLag,
+		checkInterval: durationOrDefault(checkInterval, defaultReplicaCheckInterval),
+		logger:        logger,
+	}
+	for name, db := range replicas {
+		rt.replicas = append(rt.replicas, &replica{name: name, db: db})
+	}
+	return rt
+}
+
+// pick returns the next usable replica, or nil to use the primary
+func (rt *readRouter) pick(ctx context.Context) *replica {
+	if len(rt.replicas) == 0 || primaryReadsForced(ctx) {
+		return nil
+	}
+
+	start := rt.next.Add(1)
+	for i := range rt.replicas {
+		candidate := rt.replicas[(start+uint64(i))%uint64(len(rt.replicas))]
+		if candidate.usable.Load() {
+			return candidate
+		}
+	}
+	return nil
+}
+
+func (rt *readRouter) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	if replica := rt.pick(ctx); replica != nil {
+		row := replica.db.QueryRowContext(ctx, query, args...)
+		if err := row.Err(); !rt.replicaFailed(ctx, replica, err) {
+			return row
+		}
+	}
+	return rt.primary.QueryRowContext(ctx, query, args...)
+}
+
+func (rt *readRouter) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	if replica := rt.pick(ctx); replica != nil {
+		rows, err := replica.db.QueryContext(ctx, query, args...)
+		if !rt.replicaFailed(ctx, replica, err) {
+			return rows, err
+		}
+	}
+	return rt.primary.QueryContext(ctx, query, args...)
+}
+
+// replicaFailed takes the replica out of rotation if err means it is
+// unavailable, rather than that the query itself is bad
+func (rt *readRouter) replicaFailed(ctx context.Context, replica *replica, err error) bool {
+	if err == nil || err == sql.ErrNoRows || ctx.Err() != nil || !isDependencyFailure(err) {
+		return false
+	}
+
+	if replica.usable.CompareAndSwap(true, false) {
+		contextLogger(ctx, rt.logger).WithError(err).WithField("replica", replica.name).Warn("Replica read failed, reading from primary")
+	}
+	return true
+}
+
+// start checks every replica now and then periodically until stopped
+func (rt *readRouter) start(ctx context.Context) {
+	if len(rt.replicas) == 0 || rt.stop != nil {
+		return
+	}
+
+	rt.checkAll(ctx)
+
+	rt.stop = make(chan struct{})
+	rt.wg.Add(1)
+	go func() {
+		defer rt.wg.Done()
+
+		ticker := time.NewTicker(rt.checkInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-rt.stop:
+				return
+			case <-ticker.C:
+				rt.checkAll(context.Background())
+			}
+		}
+	}()
+}
+
+func (rt *readRouter) close() {
+	if rt.stop == nil {
+		return
+	}
+	close(rt.stop)
+	rt.wg.Wait()
+	rt.stop = nil
+}
+
+func (rt *readRouter) checkAll(ctx context.Context) {
+	for _, replica := range rt.replicas {
+		rt.check(ctx, replica)
+	}
+}
+
+// check measures the replica's lag and updates whether it serves reads
+func (rt *readRouter) check(ctx context.Context, replica *replica) {
+	ctx, cancel := context.WithTimeout(ctx, rt.checkInterval)
+	defer cancel()
+
+	var lagSeconds sql.NullFloat64
+	err := replica.db.QueryRowContext(ctx, replicaLagQuery).Scan(&lagSeconds)
+	if err == nil && !lagSeconds.Valid {
+		err = errors.New("replica is not streaming and has replayed no transaction")
+	}
+	lag := time.Duration(lagSeconds.Float64 * float64(time.Second))
+	usable := err == nil && (rt.maxLag <= 0 || lag <= rt.maxLag)
+
+	if replica.usable.Swap(usable) == usable {
+		return
+	}
+
+	logger := rt.logger.WithField("replica", replica.name)
+	switch {
+	case usable:
+		logger.WithField("lag", lag.String()).Info("Replica serving reads")
+	case err != nil:
+		logger.WithError(err).Warn("Replica check failed, reading from primary")
+	default:
+		logger.WithField("lag", lag.String()).Warn("Replica lag exceeds tolerance, reading from primary")
+	}
+}
@@ -0,0 +1,147 @@
+package adapters
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// fakeDatabase answers the lag query with lag (NULL if lagUnknown) and every
+// other query with its name
+type fakeDatabase struct {
+	name       string
+	lag        float64
+	lagUnknown bool
+	down       atomic.Bool
+}
+
+func (d *fakeDatabase) Connect(ctx context.Context) (driver.Conn, error) {
+	return &fakeDatabaseConn{d}, nil
+}
+func (d *fakeDatabase) Driver() driver.Driver { return nil }
+
+type fakeDatabaseConn struct {
+	db *fakeDatabase
+}
+
+func (c *fakeDatabaseConn) Prepare(query string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (c *fakeDatabaseConn) Close() error                              { return nil }
+func (c *fakeDatabaseConn) Begin() (driver.Tx, error)                 { return nil, driver.ErrSkip }
+
+func (c *fakeDatabaseConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if c.db.down.Load() {
+		return nil, errors.New("connection refused")
+	}
+	if strings.Contains(query, "pg_last_wal_replay_lsn") {
+		if c.db.lagUnknown {
+			return &fakeRows{value: nil}, nil
+		}
+		return &fakeRows{value: c.db.lag}, nil
+	}
+	return &fakeRows{value: c.db.name}, nil
+}
+
+type fakeRows struct {
+	value driver.Value
+	read  bool
+}
+
+func (r *fakeRows) Columns() []string { return []string{"value"} }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.read {
+		return io.EOF
+	}
+	r.read = true
+	dest[0] = r.value
+	return nil
+}
+
+// TestReadRouter tests replica selection, staleness, fallback and forced primary reads
+func TestReadRouter(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	primary := &fakeDatabase{name: "primary"}
+	fresh := &fakeDatabase{name: "fresh"}
+	stale := &fakeDatabase{name: "stale", lag: 60}
+
+	rt := newReadRouter(sql.OpenDB(primary), map[string]*sql.DB{
+		"fresh": sql.OpenDB(fresh),
+		"stale": sql.OpenDB(stale),
+	}, 5*time.Second, time.Hour, NewLogrusLogger(logger))
+
+	readFrom := func(ctx context.Context) string {
+		var name string
+		if err := rt.QueryRowContext(ctx, "SELECT name").Scan(&name); err != nil {
+			t.Fatalf("QueryRowContext failed: %v", err)
+		}
+		return name
+	}
+
+	ctx := context.Background()
+	if name := readFrom(ctx); name != "primary" {
+		t.Errorf("read before replica check served by %s, expected primary", name)
+	}
+
+	rt.start(ctx)
+	defer rt.close()
+
+	for i := 0; i < 4; i++ {
+		if name := readFrom(ctx); name != "fresh" {
+			t.Errorf("read %d served by %s, expected fresh replica", i, name)
+		}
+	}
+	if name := readFrom(WithPrimaryReads(ctx)); name != "primary" {
+		t.Errorf("forced primary read served by %s", name)
+	}
+
+	fresh.down.Store(true)
+	if name := readFrom(ctx); name != "primary" {
+		t.Errorf("read with replica down served by %s, expected primary fallback", name)
+	}
+
+	fresh.down.Store(false)
+	rt.checkAll(ctx)
+	if name := readFrom(ctx); name != "fresh" {
+		t.Errorf("read after replica recovery served by %s, expected fresh replica", name)
+	}
+}
+
+// TestReadRouterCheck tests which lag check results make a replica usable
+func TestReadRouterCheck(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	tests := []struct {
+		name           string
+		replica        *fakeDatabase
+		expectedUsable bool
+	}{
+		{name: "caught up", replica: &fakeDatabase{lag: 0}, expectedUsable: true},
+		{name: "within tolerance", replica: &fakeDatabase{lag: 2}, expectedUsable: true},
+		{name: "behind", replica: &fakeDatabase{lag: 60}},
+		{name: "cut off with nothing replayed", replica: &fakeDatabase{lagUnknown: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rt := newReadRouter(sql.OpenDB(&fakeDatabase{name: "primary"}), map[string]*sql.DB{
+				"replica": sql.OpenDB(tt.replica),
+			}, 5*time.Second, time.Hour, NewLogrusLogger(logger))
+
+			rt.checkAll(context.Background())
+			if usable := rt.replicas[0].usable.Load(); usable != tt.expectedUsable {
+				t.Errorf("usable = %v, expected %v", usable, tt.expectedUsable)
+			}
+		})
+	}
+}
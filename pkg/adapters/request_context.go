@@ -0,0 +1,60 @@
+package adapters
+
+import (
+	"context"
+
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/interfaces"
+)
+
+type requestContextKey int
+
+const (
+	correlationIDKey requestContextKey = iota
+	requestIDKey
+)
+
+// Log field names for the IDs carried in the context
+const (
+	LogFieldCorrelationID = "correlation_id"
+	LogFieldRequestID     = "request_id"
+)
+
+// WithCorrelationID returns a context carrying the ID shared by every
+// operation of one business flow (e.g. a settlement) across services
+func WithCorrelationID(ctx context.Context, correlationID string) context.Context {
+	return context.WithValue(ctx, correlationIDKey, correlationID)
+}
+
+// CorrelationID returns the correlation ID in ctx, or ""
+func CorrelationID(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDKey).(string)
+	return id
+}
+
+// WithRequestID returns a context carrying the ID of one inbound request
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestID returns the request ID in ctx, or ""
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// contextLogFields returns the IDs carried by ctx as log fields
+func contextLogFields(ctx context.Context) interfaces.LogFields {
+	fields := interfaces.LogFields{}
+	if id := CorrelationID(ctx); id != "" {
+		fields[LogFieldCorrelationID] = id
+	}
+	if id := RequestID(ctx); id != "" {
+		fields[LogFieldRequestID] = id
+	}
+	return fields
+}
+
+// contextLogger returns a logger that tags lines with the IDs carried by ctx
+func contextLogger(ctx context.Context, logger interfaces.Logger) interfaces.Logger {
+	return logger.WithContext(ctx).WithFields(contextLogFields(ctx))
+}
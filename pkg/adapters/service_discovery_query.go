@@ -0,0 +1,321 @@
+package adapters
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/interfaces"
+)
+
+// semVersion is a parsed semantic version. Missing minor/patch components are zero.
+type semVersion struct {
+	major, minor, patch int
+	prerelease          string
+}
+
+// parseSemVersion parses "1", "1.2", "v1.2.3" and "1.2.3-rc.1+build" forms.
+// It also reports how many numeric components were present, which the ~ and ^
+// operators use to decide their upper bound.
+func parseSemVersion(s string) (semVersion, int, error) {
+	var v semVersion
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if s == "" {
+		return v, 0, fmt.Errorf("empty version")
+	}
+
+	if i := strings.IndexByte(s, '+'); i >= 0 {
+		s = s[:i]
+	}
+	if i := strings.IndexByte(s, '-'); i >= 0 {
+		v.prerelease = s[i+1:]
+		s = s[:i]
+	}
+
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 {
+		return v, 0, fmt.Errorf("invalid version: %s", s)
+	}
+	nums := [3]int{}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return v, 0, fmt.Errorf("invalid version component %q in %s", part, s)
+		}
+		nums[i] = n
+	}
+	v.major, v.minor, v.patch = nums[0], nums[1], nums[2]
+	return v, len(parts), nil
+}
+
+// compare returns -1, 0 or 1. A prerelease sorts before its release.
+func (v semVersion) compare(o semVersion) int {
+	for _, d := range [3]int{v.major - o.major, v.minor - o.minor, v.patch - o.patch} {
+		if d < 0 {
+			return -1
+		}
+		if d > 0 {
+			return 1
+		}
+	}
+	switch {
+	case v.prerelease == o.prerelease:
+		return 0
+	case v.prerelease == "":
+		return 1
+	case o.prerelease == "":
+		return -1
+	}
+	return comparePrerelease(v.prerelease, o.prerelease)
+}
+
+func comparePrerelease(a, b string) int {
+	ap, bp := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(ap) && i < len(bp); i++ {
+		an, aErr := strconv.Atoi(ap[i])
+		bn, bErr := strconv.Atoi(bp[i])
+		switch {
+		case aErr == nil && bErr == nil:
+			if an != bn {
+				if an < bn {
+					return -1
+				}
+				return 1
+			}
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(ap[i], bp[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	switch {
+	case len(ap) < len(bp):
+		return -1
+	case len(ap) > len(bp):
+		return 1
+	}
+	return 0
+}
+
+type versionComparator struct {
+	op      string
+	version semVersion
+}
+
+func (c versionComparator) matches(v semVersion) bool {
+	cmp := v.compare(c.version)
+	switch c.op {
+	case "=":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	}
+	return false
+}
+
+// versionConstraint is a disjunction ("||") of conjunctions (",") of comparators
+type versionConstraint [][]versionComparator
+
+// parseVersionConstraint parses expressions such as ">=1.2, <2", "^1.4",
+// "~2.0.3" and ">=1 <1.5 || >=2". Comparators within a group may be separated
+// by commas or whitespace.
+func parseVersionConstraint(expr string) (versionConstraint, error) {
+	var constraint versionConstraint
+	for _, group := range strings.Split(expr, "||") {
+		fields := strings.FieldsFunc(group, func(r rune) bool { return r == ',' || r == ' ' })
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("empty version constraint group in %q", expr)
+		}
+
+		var comparators []versionComparator
+		pendingOp := ""
+		for _, field := range fields {
+			// Allow a space between operator and version (">= 1.2")
+			if strings.Trim(field, "<>=!~^") == "" {
+				pendingOp += field
+				continue
+			}
+			parsed, err := parseComparator(pendingOp + field)
+			pendingOp = ""
+			if err != nil {
+				return nil, fmt.Errorf("invalid version constraint %q: %w", expr, err)
+			}
+			comparators = append(comparators, parsed...)
+		}
+		if pendingOp != "" {
+			return nil, fmt.Errorf("invalid version constraint %q: operator %s has no version", expr, pendingOp)
+		}
+		constraint = append(constraint, comparators)
+	}
+	return constraint, nil
+}
+
+func parseComparator(field string) ([]versionComparator, error) {
+	op := ""
+	for _, candidate := range []string{">=", "<=", "!=", "==", ">", "<", "=", "~", "^"} {
+		if strings.HasPrefix(field, candidate) {
+			op = candidate
+			break
+		}
+	}
+	if op == "==" {
+		field, op = field[1:], "="
+	}
+
+	v, components, err := parseSemVersion(strings.TrimPrefix(field, op))
+	if err != nil {
+		return nil, err
+	}
+
+	switch op {
+	case "", "=":
+		return []versionComparator{{op: "=", version: v}}, nil
+	case "~":
+		// ~1.2.3 := >=1.2.3 <1.3.0, ~1 := >=1.0.0 <2.0.0
+		upper := semVersion{major: v.major, minor: v.minor + 1}
+		if components == 1 {
+			upper = semVersion{major: v.major + 1}
+		}
+		return []versionComparator{{op: ">=", version: v}, {op: "<", version: upper}}, nil
+	case "^":
+		// ^1.2.3 := >=1.2.3 <2.0.0, ^0.2.3 := >=0.2.3 <0.3.0, ^0.0.3 := >=0.0.3 <0.0.4
+		var upper semVersion
+		switch {
+		case v.major > 0 || components == 1:
+			upper = semVersion{major: v.major + 1}
+		case v.minor > 0 || components == 2:
+			upper = semVersion{minor: v.minor + 1}
+		default:
+			upper = semVersion{patch: v.patch + 1}
+		}
+		return []versionComparator{{op: ">=", version: v}, {op: "<", version: upper}}, nil
+	}
+	return []versionComparator{{op: op, version: v}}, nil
+}
+
+func (c versionConstraint) matches(version string) bool {
+	v, _, err := parseSemVersion(version)
+	if err != nil {
+		return false
+	}
+	for _, group := range c {
+		ok := true
+		for _, comparator := range group {
+			if !comparator.matches(v) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+// serviceMatcher is a compiled DiscoverQuery
+type serviceMatcher struct {
+	query      *interfaces.DiscoverQuery
+	constraint versionConstraint
+}
+
+func newServiceMatcher(query *interfaces.DiscoverQuery) (*serviceMatcher, error) {
+	if query == nil {
+		query = &interfaces.DiscoverQuery{}
+	}
+
+	m := &serviceMatcher{query: query}
+	if query.VersionConstraint != "" {
+		constraint, err := parseVersionConstraint(query.VersionConstraint)
+		if err != nil {
+			return nil, err
+		}
+		m.constraint = constraint
+	}
+
+	for _, req := range query.Labels {
+		if req.Key == "" {
+			return nil, fmt.Errorf("label requirement key cannot be empty")
+		}
+		switch req.Operator {
+		case interfaces.LabelOpEquals, interfaces.LabelOpNotEquals:
+			if len(req.Values) != 1 {
+				return nil, fmt.Errorf("label operator %s on %s requires exactly one value", req.Operator, req.Key)
+			}
+		case interfaces.LabelOpIn, interfaces.LabelOpNotIn:
+			if len(req.Values) == 0 {
+				return nil, fmt.Errorf("label operator %s on %s requires at least one value", req.Operator, req.Key)
+			}
+		case interfaces.LabelOpExists, interfaces.LabelOpDoesNotExist:
+		default:
+			return nil, fmt.Errorf("unsupported label operator: %s", req.Operator)
+		}
+	}
+
+	return m, nil
+}
+
+func (m *serviceMatcher) matches(info *interfaces.ServiceInfo) bool {
+	if m.query.ServiceName != "" && info.ServiceName != m.query.ServiceName {
+		return false
+	}
+	if !m.matchesStatus(effectiveStatus(info)) {
+		return false
+	}
+	if m.constraint != nil && !m.constraint.matches(info.Version) {
+		return false
+	}
+	for _, req := range m.query.Labels {
+		if !matchesLabel(req, info.Metadata) {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *serviceMatcher) matchesStatus(status interfaces.ServiceStatus) bool {
+	if len(m.query.Statuses) == 0 {
+		return status == interfaces.ServiceStatusHealthy || status == interfaces.ServiceStatusDegraded
+	}
+	return slices.Contains(m.query.Statuses, status)
+}
+
+// effectiveStatus treats registrations without a status as healthy
+func effectiveStatus(info *interfaces.ServiceInfo) interfaces.ServiceStatus {
+	if info.Status == "" {
+		return interfaces.ServiceStatusHealthy
+	}
+	return info.Status
+}
+
+func matchesLabel(req interfaces.LabelRequirement, labels map[string]string) bool {
+	value, ok := labels[req.Key]
+	switch req.Operator {
+	case interfaces.LabelOpEquals:
+		return ok && value == req.Values[0]
+	case interfaces.LabelOpNotEquals:
+		return !ok || value != req.Values[0]
+	case interfaces.LabelOpIn:
+		return ok && slices.Contains(req.Values, value)
+	case interfaces.LabelOpNotIn:
+		return !ok || !slices.Contains(req.Values, value)
+	case interfaces.LabelOpExists:
+		return ok
+	case interfaces.LabelOpDoesNotExist:
+		return !ok
+	}
+	return false
+}
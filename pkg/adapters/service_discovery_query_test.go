@@ -0,0 +1,151 @@
+package adapters
+
+import (
+	"testing"
+
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/interfaces"
+)
+
+// TestVersionConstraint tests semantic version constraint matching
+func TestVersionConstraint(t *testing.T) {
+	tests := []struct {
+		name       string
+		constraint string
+		version    string
+		expected   bool
+	}{
+		{name: "range: inside", constraint: ">=1.2, <2", version: "1.4.0", expected: true},
+		{name: "range: lower bound", constraint: ">=1.2, <2", version: "1.2.0", expected: true},
+		{name: "range: upper bound excluded", constraint: ">=1.2, <2", version: "2.0.0", expected: false},
+		{name: "range: below", constraint: ">=1.2, <2", version: "1.1.9", expected: false},
+		{name: "range: space after operator", constraint: ">= 1.2 < 2", version: "1.9.9", expected: true},
+		{name: "exact match with v prefix", constraint: "1.0.0", version: "v1.0.0", expected: true},
+		{name: "not equal", constraint: "!=1.0.0", version: "1.0.0", expected: false},
+		{name: "caret: same major", constraint: "^1.4", version: "1.9.0", expected: true},
+		{name: "caret: next major", constraint: "^1.4", version: "2.0.0", expected: false},
+		{name: "caret: zero major pins minor", constraint: "^0.2.3", version: "0.3.0", expected: false},
+		{name: "tilde: same minor", constraint: "~2.0.3", version: "2.0.9", expected: true},
+		{name: "tilde: next minor", constraint: "~2.0.3", version: "2.1.0", expected: false},
+		{name: "or: second group", constraint: "<1 || >=3", version: "3.2.0", expected: true},
+		{name: "or: neither group", constraint: "<1 || >=3", version: "2.0.0", expected: false},
+		{name: "prerelease below release", constraint: ">=1.2.0", version: "1.2.0-rc.1", expected: false},
+		{name: "prerelease ordering", constraint: ">1.2.0-rc.2", version: "1.2.0-rc.10", expected: true},
+		{name: "unparsable version never matches", constraint: ">=0", version: "latest", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			constraint, err := parseVersionConstraint(tt.constraint)
+			if err != nil {
+				t.Fatalf("parseVersionConstraint(%s) failed: %v", tt.constraint, err)
+			}
+			if result := constraint.matches(tt.version); result != tt.expected {
+				t.Errorf("%q matches %q = %v, expected %v", tt.constraint, tt.version, result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestVersionConstraintInvalid tests rejection of malformed constraints
+func TestVersionConstraintInvalid(t *testing.T) {
+	for _, constraint := range []string{">=", "1.2.3.4", ">=a.b", "1.0 ||", "~"} {
+		if _, err := parseVersionConstraint(constraint); err == nil {
+			t.Errorf("parseVersionConstraint(%q) expected error", constraint)
+		}
+	}
+}
+
+// TestServiceMatcher tests DiscoverQuery filtering by name, status and labels
+func TestServiceMatcher(t *testing.T) {
+	service := &interfaces.ServiceInfo{
+		ServiceName: "custodian-simulator",
+		ServiceID:   "custodian-Komainu",
+		Version:     "1.3.0",
+		Metadata: map[string]string{
+			"region": "eu-west",
+			"tier":   "primary",
+		},
+	}
+
+	tests := []struct {
+		name     string
+		query    *interfaces.DiscoverQuery
+		status   interfaces.ServiceStatus
+		expected bool
+	}{
+		{
+			name:     "nil query matches healthy service",
+			query:    nil,
+			expected: true,
+		},
+		{
+			name:     "name mismatch",
+			query:    &interfaces.DiscoverQuery{ServiceName: "risk-monitor"},
+			expected: false,
+		},
+		{
+			name:     "draining excluded by default",
+			query:    &interfaces.DiscoverQuery{ServiceName: "custodian-simulator"},
+			status:   interfaces.ServiceStatusDraining,
+			expected: false,
+		},
+		{
+			name: "maintenance included when requested",
+			query: &interfaces.DiscoverQuery{
+				Statuses: []interfaces.ServiceStatus{interfaces.ServiceStatusMaintenance},
+			},
+			status:   interfaces.ServiceStatusMaintenance,
+			expected: true,
+		},
+		{
+			name: "label equality and set membership",
+			query: &interfaces.DiscoverQuery{
+				VersionConstraint: ">=1.2, <2",
+				Labels: []interfaces.LabelRequirement{
+					{Key: "region", Operator: interfaces.LabelOpEquals, Values: []string{"eu-west"}},
+					{Key: "tier", Operator: interfaces.LabelOpIn, Values: []string{"primary", "secondary"}},
+				},
+			},
+			expected: true,
+		},
+		{
+			name: "label not in",
+			query: &interfaces.DiscoverQuery{
+				Labels: []interfaces.LabelRequirement{
+					{Key: "region", Operator: interfaces.LabelOpNotIn, Values: []string{"eu-west"}},
+				},
+			},
+			expected: false,
+		},
+		{
+			name: "label existence",
+			query: &interfaces.DiscoverQuery{
+				Labels: []interfaces.LabelRequirement{
+					{Key: "tier", Operator: interfaces.LabelOpExists},
+					{Key: "canary", Operator: interfaces.LabelOpDoesNotExist},
+				},
+			},
+			expected: true,
+		},
+		{
+			name:     "version outside constraint",
+			query:    &interfaces.DiscoverQuery{VersionConstraint: "^2"},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := *service
+			info.Status = tt.status
+
+			matcher, err := newServiceMatcher(tt.query)
+			if err != nil {
+				t.Fatalf("newServiceMatcher failed: %v", err)
+			}
+			if result := matcher.matches(&info); result != tt.expected {
+				t.Errorf("matches = %v, expected %v", result, tt.expected)
+			}
+		})
+	}
+}
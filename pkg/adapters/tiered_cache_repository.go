@@ -0,0 +1,465 @@
+package adapters
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/interfaces"
+	"github.com/redis/go-redis/v9"
+)
+
+// TieredCacheConfig configures the in-process tier of a TieredCacheRepository
+type TieredCacheConfig struct {
+	// Maximum number of entries held in process
+	MaxEntries int
+
+	// Upper bound on how long an entry is served locally. Entries never
+	// outlive their Redis TTL, and this also bounds staleness if an
+	// invalidation message is missed during a pub/sub reconnect.
+	LocalTTL time.Duration
+
+	// Identifies this replica in invalidation messages (defaults to hostname-pid-random)
+	InstanceID string
+}
+
+// cacheInvalidation is broadcast on the namespace's invalidation channel
+type cacheInvalidation struct {
+	Origin  string   `json:"origin"`
+	Keys    []string `json:"keys,omitempty"`
+	Pattern string   `json:"pattern,omitempty"`
+}
+
+// TieredCacheRepository keeps a bounded in-process LRU in front of another
+// CacheRepository and keeps replicas coherent over Redis pub/sub. Only Get
+// populates the local tier; MGet serves local hits, and other reads pass
+// straight through to the wrapped repository.
+type TieredCacheRepository struct {
+	interfaces.CacheRepository
+
+	client  redis.UniversalClient
+	channel string
+	config  TieredCacheConfig
+	ttl     *durationSetting // LocalTTL, replaced by SetLocalTTL
+	local   *localLRU
+	logger  interfaces.Logger
+
+	mu     sync.Mutex
+	pubsub *redis.PubSub
+	done   chan struct{}
+}
+
+func NewTieredCacheRepository(inner interfaces.CacheRepository, client redis.UniversalClient, namespace string, cfg TieredCacheConfig, logger interfaces.Logger) (*TieredCacheRepository, error) {
+	if cfg.MaxEntries <= 0 {
+		return nil, fmt.Errorf("local cache max entries must be positive")
+	}
+	if cfg.LocalTTL <= 0 {
+		return nil, fmt.Errorf("local cache TTL must be positive")
+	}
+	if cfg.InstanceID == "" {
+		instanceID, err := defaultHolderID()
+		if err != nil {
+			return nil, err
+		}
+		cfg.InstanceID = instanceID
+	}
+
+	return &TieredCacheRepository{
+		CacheRepository: inner,
+		client:          client,
+		channel:         fmt.Sprintf("%s:cache-invalidation", namespace),
+		config:          cfg,
+		ttl:             newDurationSetting(cfg.LocalTTL),
+		local:           newLocalLRU(cfg.MaxEntries),
+		logger:          logger,
+	}, nil
+}
+
+// Start subscribes to invalidations from other replicas
+func (t *TieredCacheRepository) Start(ctx context.Context) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.pubsub != nil {
+		return nil
+	}
+
+	pubsub := t.client.Subscribe(ctx, t.channel)
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return fmt.Errorf("failed to subscribe to cache invalidations: %w", err)
+	}
+
+	t.pubsub = pubsub
+	t.done = make(chan struct{})
+	go t.listen(pubsub.Channel(), t.done)
+
+	t.logger.WithField("channel", t.channel).Info("Cache invalidation listener started")
+	return nil
+}
+
+// Stop unsubscribes and drops all local entries
+func (t *TieredCacheRepository) Stop() error {
+	t.mu.Lock()
+	pubsub, done := t.pubsub, t.done
+	t.pubsub, t.done = nil, nil
+	t.mu.Unlock()
+
+	if pubsub == nil {
+		return nil
+	}
+
+	err := pubsub.Close()
+	<-done
+	t.local.clear()
+	if err != nil {
+		return fmt.Errorf("failed to close cache invalidation subscription: %w", err)
+	}
+	return nil
+}
+
+func (t *TieredCacheRepository) listen(messages <-chan *redis.Message, done chan struct{}) {
+	defer close(done)
+
+	for msg := range messages {
+		var inv cacheInvalidation
+		if err := json.Unmarshal([]byte(msg.Payload), &inv); err != nil {
+			t.logger.WithError(err).Warn("Ignoring malformed cache invalidation")
+			continue
+		}
+		if inv.Origin == t.config.InstanceID {
+			continue
+		}
+		t.applyInvalidation(inv)
+	}
+}
+
+func (t *TieredCacheRepository) applyInvalidation(inv cacheInvalidation) {
+	for _, key := range inv.Keys {
+		t.local.remove(key)
+	}
+	if inv.Pattern != "" {
+		t.local.removeMatching(func(key string) bool {
+			return matchGlob(inv.Pattern, key)
+		})
+	}
+}
+
+// invalidate drops keys locally and tells other replicas to do the same.
+// Publish failures are logged: other replicas then converge within LocalTTL.
+func (t *TieredCacheRepository) invalidate(ctx context.Context, inv cacheInvalidation) {
+	t.applyInvalidation(inv)
+
+	inv.Origin = t.config.InstanceID
+	payload, err := json.Marshal(inv)
+	if err != nil {
+		contextLogger(ctx, t.logger).WithError(err).Warn("Failed to encode cache invalidation")
+		return
+	}
+	if err := t.client.Publish(ctx, t.channel, payload).Err(); err != nil {
+		contextLogger(ctx, t.logger).WithError(err).WithField("channel", t.channel).Warn("Failed to publish cache invalidation")
+	}
+}
+
+// SetLocalTTL changes LocalTTL for entries cached from now on
+func (t *TieredCacheRepository) SetLocalTTL(ttl time.Duration) error {
+	if ttl <= 0 {
+		return fmt.Errorf("local cache TTL must be positive")
+	}
+	t.ttl.Store(ttl)
+	return nil
+}
+
+func (t *TieredCacheRepository) localTTL(redisTTL time.Duration) time.Duration {
+	localTTL := t.ttl.Load()
+	if redisTTL > 0 && redisTTL < localTTL {
+		return redisTTL
+	}
+	return localTTL
+}
+
+func (t *TieredCacheRepository) Get(ctx context.Context, key string) (string, error) {
+	if value, ok := t.local.get(key); ok {
+		return value, nil
+	}
+
+	// An invalidation that lands while we read from Redis must win over the
+	// value we are about to populate
+	generation := t.local.currentGeneration()
+
+	value, err := t.CacheRepository.Get(ctx, key)
+	if err != nil {
+		return "", err
+	}
+
+	// Never serve an entry locally past its Redis expiry
+	ttl, err := t.CacheRepository.TTL(ctx, key)
+	if err == nil {
+		t.local.setIfGeneration(generation, key, value, t.localTTL(ttl))
+	}
+	return value, nil
+}
+
+func (t *TieredCacheRepository) MGet(ctx context.Context, keys []string) (map[string]string, error) {
+	result := make(map[string]string, len(keys))
+	missing := []string{}
+	for _, key := range keys {
+		if value, ok := t.local.get(key); ok {
+			result[key] = value
+		} else {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) == 0 {
+		return result, nil
+	}
+
+	fetched, err := t.CacheRepository.MGet(ctx, missing)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range fetched {
+		result[key] = value
+	}
+	return result, nil
+}
+
+func (t *TieredCacheRepository) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	if err := t.CacheRepository.Set(ctx, key, value, ttl); err != nil {
+		return err
+	}
+	t.invalidate(ctx, cacheInvalidation{Keys: []string{key}})
+	return nil
+}
+
+func (t *TieredCacheRepository) Delete(ctx context.Context, key string) error {
+	if err := t.CacheRepository.Delete(ctx, key); err != nil {
+		return err
+	}
+	t.invalidate(ctx, cacheInvalidation{Keys: []string{key}})
+	return nil
+}
+
+func (t *TieredCacheRepository) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	if err := t.CacheRepository.Expire(ctx, key, ttl); err != nil {
+		return err
+	}
+	t.invalidate(ctx, cacheInvalidation{Keys: []string{key}})
+	return nil
+}
+
+func (t *TieredCacheRepository) DeletePattern(ctx context.Context, pattern string) error {
+	if err := t.CacheRepository.DeletePattern(ctx, pattern); err != nil {
+		return err
+	}
+	t.invalidate(ctx, cacheInvalidation{Pattern: pattern})
+	return nil
+}
+
+func (t *TieredCacheRepository) MSet(ctx context.Context, values map[string]interface{}, ttl time.Duration) error {
+	if err := t.CacheRepository.MSet(ctx, values, ttl); err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	t.invalidate(ctx, cacheInvalidation{Keys: keys})
+	return nil
+}
+
+func (t *TieredCacheRepository) SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	ok, err := t.CacheRepository.SetNX(ctx, key, value, ttl)
+	if err != nil {
+		return false, err
+	}
+	if ok {
+		t.invalidate(ctx, cacheInvalidation{Keys: []string{key}})
+	}
+	return ok, nil
+}
+
+func (t *TieredCacheRepository) CompareAndSwap(ctx context.Context, key, expected string, value interface{}, ttl time.Duration) (bool, error) {
+	swapped, err := t.CacheRepository.CompareAndSwap(ctx, key, expected, value, ttl)
+	if err != nil {
+		return false, err
+	}
+	if swapped {
+		t.invalidate(ctx, cacheInvalidation{Keys: []string{key}})
+	}
+	return swapped, nil
+}
+
+func (t *TieredCacheRepository) Incr(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error) {
+	value, err := t.CacheRepository.Incr(ctx, key, delta, ttl)
+	if err != nil {
+		return 0, err
+	}
+	t.invalidate(ctx, cacheInvalidation{Keys: []string{key}})
+	return value, nil
+}
+
+func (t *TieredCacheRepository) IncrByFloat(ctx context.Context, key string, delta float64, ttl time.Duration) (float64, error) {
+	value, err := t.CacheRepository.IncrByFloat(ctx, key, delta, ttl)
+	if err != nil {
+		return 0, err
+	}
+	t.invalidate(ctx, cacheInvalidation{Keys: []string{key}})
+	return value, nil
+}
+
+func (t *TieredCacheRepository) SetWithTags(ctx context.Context, key string, value interface{}, ttl time.Duration, tags ...string) error {
+	if err := t.CacheRepository.SetWithTags(ctx, key, value, ttl, tags...); err != nil {
+		return err
+	}
+	t.invalidate(ctx, cacheInvalidation{Keys: []string{key}})
+	return nil
+}
+
+func (t *TieredCacheRepository) SetWithTagsSince(ctx context.Context, fence int64, key string, value interface{}, ttl time.Duration, tags ...string) (bool, error) {
+	stored, err := t.CacheRepository.SetWithTagsSince(ctx, fence, key, value, ttl, tags...)
+	if err != nil {
+		return false, err
+	}
+	if stored {
+		t.invalidate(ctx, cacheInvalidation{Keys: []string{key}})
+	}
+	return stored, nil
+}
+
+func (t *TieredCacheRepository) InvalidateTag(ctx context.Context, tag string) ([]string, error) {
+	keys, err := t.CacheRepository.InvalidateTag(ctx, tag)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) > 0 {
+		t.invalidate(ctx, cacheInvalidation{Keys: keys})
+	}
+	return keys, nil
+}
+
+// localLRU is a size-bounded LRU map with per-entry expiry. generation is
+// bumped on every removal so fills racing an invalidation can be discarded.
+type localLRU struct {
+	mu         sync.Mutex
+	maxEntries int
+	generation uint64
+	order      *list.List
+	entries    map[string]*list.Element
+	now        func() time.Time
+}
+
+type localEntry struct {
+	key       string
+	value     string
+	expiresAt time.Time
+}
+
+func newLocalLRU(maxEntries int) *localLRU {
+	return &localLRU{
+		maxEntries: maxEntries,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+		now:        time.Now,
+	}
+}
+
+func (l *localLRU) get(key string) (string, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	elem, ok := l.entries[key]
+	if !ok {
+		return "", false
+	}
+	entry := elem.Value.(*localEntry)
+	if !l.now().Before(entry.expiresAt) {
+		l.removeElement(elem)
+		return "", false
+	}
+	l.order.MoveToFront(elem)
+	return entry.value, true
+}
+
+func (l *localLRU) currentGeneration() uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.generation
+}
+
+// setIfGeneration stores the entry unless an invalidation happened since generation was read
+func (l *localLRU) setIfGeneration(generation uint64, key, value string, ttl time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.generation != generation {
+		return
+	}
+	l.setLocked(key, value, ttl)
+}
+
+func (l *localLRU) set(key, value string, ttl time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.setLocked(key, value, ttl)
+}
+
+func (l *localLRU) setLocked(key, value string, ttl time.Duration) {
+	expiresAt := l.now().Add(ttl)
+	if elem, ok := l.entries[key]; ok {
+		entry := elem.Value.(*localEntry)
+		entry.value, entry.expiresAt = value, expiresAt
+		l.order.MoveToFront(elem)
+		return
+	}
+
+	l.entries[key] = l.order.PushFront(&localEntry{key: key, value: value, expiresAt: expiresAt})
+	for l.order.Len() > l.maxEntries {
+		l.removeElement(l.order.Back())
+	}
+}
+
+func (l *localLRU) remove(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.generation++
+	if elem, ok := l.entries[key]; ok {
+		l.removeElement(elem)
+	}
+}
+
+func (l *localLRU) removeMatching(match func(key string) bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.generation++
+	for key, elem := range l.entries {
+		if match(key) {
+			l.removeElement(elem)
+		}
+	}
+}
+
+func (l *localLRU) clear() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.generation++
+	l.order.Init()
+	l.entries = make(map[string]*list.Element)
+}
+
+func (l *localLRU) len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.order.Len()
+}
+
+func (l *localLRU) removeElement(elem *list.Element) {
+	l.order.Remove(elem)
+	delete(l.entries, elem.Value.(*localEntry).key)
+}
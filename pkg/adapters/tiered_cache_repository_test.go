@@ -0,0 +1,92 @@
+package adapters
+
+import (
+	"testing"
+	"time"
+)
+
+// TestLocalLRUEviction tests that the least recently used entry is evicted first
+func TestLocalLRUEviction(t *testing.T) {
+	lru := newLocalLRU(2)
+	lru.set("a", "1", time.Minute)
+	lru.set("b", "2", time.Minute)
+
+	// Touch "a" so "b" becomes least recently used
+	if _, ok := lru.get("a"); !ok {
+		t.Fatal("expected a to be cached")
+	}
+	lru.set("c", "3", time.Minute)
+
+	if _, ok := lru.get("b"); ok {
+		t.Error("expected b to be evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := lru.get(key); !ok {
+			t.Errorf("expected %s to be cached", key)
+		}
+	}
+	if lru.len() != 2 {
+		t.Errorf("len = %d, expected 2", lru.len())
+	}
+}
+
+// TestLocalLRUExpiry tests per-entry TTL
+func TestLocalLRUExpiry(t *testing.T) {
+	now := time.Now()
+	lru := newLocalLRU(10)
+	lru.now = func() time.Time { return now }
+
+	lru.set("short", "1", time.Second)
+	lru.set("long", "2", time.Minute)
+
+	now = now.Add(2 * time.Second)
+	if _, ok := lru.get("short"); ok {
+		t.Error("expected short-lived entry to expire")
+	}
+	if value, ok := lru.get("long"); !ok || value != "2" {
+		t.Errorf("get(long) = %q, %v, expected 2, true", value, ok)
+	}
+}
+
+// TestLocalLRUInvalidationRace tests that a fill racing an invalidation is discarded
+func TestLocalLRUInvalidationRace(t *testing.T) {
+	lru := newLocalLRU(10)
+
+	generation := lru.currentGeneration()
+	lru.remove("balance:ACC-1")
+	lru.setIfGeneration(generation, "balance:ACC-1", "stale", time.Minute)
+
+	if _, ok := lru.get("balance:ACC-1"); ok {
+		t.Error("expected stale fill to be discarded")
+	}
+}
+
+// TestTieredCacheInvalidationPattern tests pattern invalidations from other replicas
+func TestTieredCacheInvalidationPattern(t *testing.T) {
+	cache, err := NewTieredCacheRepository(newMemoryCache(), nil, "custodian", TieredCacheConfig{
+		MaxEntries: 10,
+		LocalTTL:   time.Minute,
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewTieredCacheRepository failed: %v", err)
+	}
+
+	cache.local.set("balance:ACC-1:USD", "100", time.Minute)
+	cache.local.set("balance:ACC-1:desk/eu", "150", time.Minute)
+	cache.local.set("balance:ACC-2:USD", "200", time.Minute)
+	cache.local.set("position:ACC-1:BTC", "1", time.Minute)
+
+	cache.applyInvalidation(cacheInvalidation{Pattern: "balance:ACC-1:*"})
+
+	// Redis globs match '/', so keys Redis deleted must not linger locally
+	for _, key := range []string{"balance:ACC-1:USD", "balance:ACC-1:desk/eu"} {
+		if _, ok := cache.local.get(key); ok {
+			t.Errorf("expected %s to be invalidated", key)
+		}
+	}
+	for _, key := range []string{"balance:ACC-2:USD", "position:ACC-1:BTC"} {
+		if _, ok := cache.local.get(key); !ok {
+			t.Errorf("expected %s to remain cached", key)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package adapters
+
+import (
+	"context"
+	"database/sql"
+	"sync/atomic"
+
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/interfaces"
+)
+
+// Span attribute keys set by the adapters
+const (
+	SpanAttrDBSystem      = "db.system"
+	SpanAttrDBTable       = "db.table"
+	SpanAttrDBOperation   = "db.operation"
+	SpanAttrRowsAffected  = "db.rows_affected"
+	SpanAttrRowsReturned  = "db.rows_returned"
+	SpanAttrRepository    = "repository"
+	SpanAttrInstance      = "instance"
+	SpanAttrCorrelationID = "correlation_id"
+	SpanAttrRequestID     = "request_id"
+)
+
+// Store names reported as db.system
+const (
+	DBSystemPostgres = "postgresql"
+	DBSystemRedis    = "redis"
+)
+
+// repositoryTables maps Postgres-backed repositories to their tables
+var repositoryTables = map[string]string{
+	RepositoryPosition:   "positions",
+	RepositorySettlement: "settlements",
+	RepositoryBalance:    "balances",
+	RepositoryAccount:    "accounts",
+	RepositoryAsset:      "assets",
+}
+
+type spanContextKey struct{}
+
+type noopTracer struct{}
+
+func (noopTracer) StartSpan(ctx context.Context, name string, attributes ...interfaces.SpanAttribute) (context.Context, interfaces.Span) {
+	return ctx, noopSpan{}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttributes(attributes ...interfaces.SpanAttribute) {}
+func (noopSpan) End(err error)                                        {}
+
+// tracerHolder lets the tracer be replaced after repositories are built
+type tracerHolder struct {
+	tracer atomic.Value // tracerBox
+}
+
+// tracerBox keeps the stored type constant for atomic.Value
+type tracerBox struct {
+	tracer interfaces.Tracer
+}
+
+func newTracerHolder() *tracerHolder {
+	h := &tracerHolder{}
+	h.set(nil)
+	return h
+}
+
+// set installs tracer; nil disables tracing
+func (h *tracerHolder) set(tracer interfaces.Tracer) {
+	if tracer == nil {
+		tracer = noopTracer{}
+	}
+	h.tracer.Store(tracerBox{tracer: tracer})
+}
+
+func (h *tracerHolder) get() interfaces.Tracer {
+	return h.tracer.Load().(tracerBox).tracer
+}
+
+// TracingInterceptor wraps every call in a span named
+// "<repository>.<operation>" carrying the store, table, operation, instance
+// and the correlation and request IDs from the context. Repositories add
+// row counts to the span through the context.
+func TracingInterceptor(tracer interfaces.Tracer, system, instance string) CallInterceptor {
+	holder := newTracerHolder()
+	holder.set(tracer)
+	return holder.interceptor(system, instance)
+}
+
+func (h *tracerHolder) interceptor(system, instance string) CallInterceptor {
+	return func(ctx context.Context, call RepositoryCall, next func(ctx context.Context) error) error {
+		attributes := []interfaces.SpanAttribute{
+			{Key: SpanAttrDBSystem, Value: system},
+			{Key: SpanAttrDBOperation, Value: call.Operation},
+			{Key: SpanAttrRepository, Value: call.Repository},
+			{Key: SpanAttrInstance, Value: instance},
+		}
+		if table, ok := repositoryTables[call.Repository]; ok && system == DBSystemPostgres {
+			attributes = append(attributes, interfaces.SpanAttribute{Key: SpanAttrDBTable, Value: table})
+		}
+		if id := CorrelationID(ctx); id != "" {
+			attributes = append(attributes, interfaces.SpanAttribute{Key: SpanAttrCorrelationID, Value: id})
+		}
+		if id := RequestID(ctx); id != "" {
+			attributes = append(attributes, interfaces.SpanAttribute{Key: SpanAttrRequestID, Value: id})
+		}
+
+		ctx, span := h.get().StartSpan(ctx, call.Repository+"."+call.Operation, attributes...)
+		ctx = context.WithValue(ctx, spanContextKey{}, span)
+
+		err := next(ctx)
+		span.End(err)
+		return err
+	}
+}
+
+// SpanFromContext returns the adapter span in ctx, or a no-op span
+func SpanFromContext(ctx context.Context) interfaces.Span {
+	if span, ok := ctx.Value(spanContextKey{}).(interfaces.Span); ok {
+		return span
+	}
+	return noopSpan{}
+}
+
+// recordRowsAffected adds the row count of a write to the current span
+func recordRowsAffected(ctx context.Context, rows int64) {
+	SpanFromContext(ctx).SetAttributes(interfaces.SpanAttribute{Key: SpanAttrRowsAffected, Value: rows})
+}
+
+// recordResult adds the row count of result, if the driver reports one
+func recordResult(ctx context.Context, result sql.Result) {
+	if rows, err := result.RowsAffected(); err == nil {
+		recordRowsAffected(ctx, rows)
+	}
+}
+
+// recordRowsReturned adds the row count of a query to the current span
+func recordRowsReturned(ctx context.Context, rows int) {
+	SpanFromContext(ctx).SetAttributes(interfaces.SpanAttribute{Key: SpanAttrRowsReturned, Value: rows})
+}
@@ -0,0 +1,87 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/interfaces"
+)
+
+type recordedSpan struct {
+	name       string
+	attributes map[string]interface{}
+	ended      bool
+	err        error
+}
+
+func (s *recordedSpan) SetAttributes(attributes ...interfaces.SpanAttribute) {
+	for _, attribute := range attributes {
+		s.attributes[attribute.Key] = attribute.Value
+	}
+}
+
+func (s *recordedSpan) End(err error) {
+	s.ended = true
+	s.err = err
+}
+
+type recordingTracer struct {
+	spans []*recordedSpan
+}
+
+func (t *recordingTracer) StartSpan(ctx context.Context, name string, attributes ...interfaces.SpanAttribute) (context.Context, interfaces.Span) {
+	span := &recordedSpan{name: name, attributes: map[string]interface{}{}}
+	span.SetAttributes(attributes...)
+	t.spans = append(t.spans, span)
+	return ctx, span
+}
+
+// TestTracingInterceptor tests span naming, attributes and row counts reported through the context
+func TestTracingInterceptor(t *testing.T) {
+	tracer := &recordingTracer{}
+	interceptor := TracingInterceptor(tracer, DBSystemPostgres, "custodian-komainu")
+
+	ctx := WithRequestID(WithCorrelationID(context.Background(), "settlement-42"), "req-7")
+	call := RepositoryCall{Repository: RepositorySettlement, Operation: "Complete"}
+	failure := errors.New("connection reset")
+
+	err := interceptor(ctx, call, func(ctx context.Context) error {
+		recordRowsAffected(ctx, 1)
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("interceptor error = %v, expected %v", err, failure)
+	}
+
+	if len(tracer.spans) != 1 {
+		t.Fatalf("recorded %d spans, expected 1", len(tracer.spans))
+	}
+	span := tracer.spans[0]
+	if span.name != "settlement.Complete" || !span.ended || span.err != failure {
+		t.Errorf("span = %+v, expected ended settlement.Complete with error", span)
+	}
+
+	expected := map[string]interface{}{
+		SpanAttrDBSystem:      DBSystemPostgres,
+		SpanAttrDBTable:       "settlements",
+		SpanAttrDBOperation:   "Complete",
+		SpanAttrInstance:      "custodian-komainu",
+		SpanAttrCorrelationID: "settlement-42",
+		SpanAttrRequestID:     "req-7",
+		SpanAttrRowsAffected:  int64(1),
+	}
+	for key, value := range expected {
+		if span.attributes[key] != value {
+			t.Errorf("attribute %s = %v, expected %v", key, span.attributes[key], value)
+		}
+	}
+
+	// Outside an intercepted call row counts are dropped
+	recordRowsReturned(context.Background(), 3)
+
+	fields := contextLogFields(ctx)
+	if fields[LogFieldCorrelationID] != "settlement-42" || fields[LogFieldRequestID] != "req-7" {
+		t.Errorf("log fields = %v, expected correlation and request IDs", fields)
+	}
+}
@@ -0,0 +1,113 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/interfaces"
+)
+
+// DefaultCompressionThreshold is the encoded size above which typed cache
+// values are gzipped when using the package-level helpers
+const DefaultCompressionThreshold = 4096
+
+// TypedCache stores values of type T in a CacheRepository using a codec and
+// optional gzip compression
+type TypedCache[T any] struct {
+	cache         interfaces.CacheRepository
+	codec         interfaces.CacheCodec
+	compressAbove int
+}
+
+// NewTypedCache wraps cache with the given codec (JSON if nil). Values whose
+// encoded size exceeds compressAbove bytes are gzipped; 0 disables compression.
+func NewTypedCache[T any](cache interfaces.CacheRepository, codec interfaces.CacheCodec, compressAbove int) *TypedCache[T] {
+	if codec == nil {
+		codec = JSONCodec{}
+	}
+	return &TypedCache[T]{
+		cache:         cache,
+		codec:         codec,
+		compressAbove: compressAbove,
+	}
+}
+
+// Get returns the decoded value, or an error wrapping interfaces.ErrCacheMiss
+func (c *TypedCache[T]) Get(ctx context.Context, key string) (T, error) {
+	var value T
+
+	raw, err := c.cache.Get(ctx, key)
+	if err != nil {
+		return value, err
+	}
+	if err := decodeCacheValue(c.codec, []byte(raw), &value); err != nil {
+		return value, err
+	}
+	return value, nil
+}
+
+func (c *TypedCache[T]) Set(ctx context.Context, key string, value T, ttl time.Duration) error {
+	data, err := encodeCacheValue(c.codec, value, c.compressAbove)
+	if err != nil {
+		return err
+	}
+	return c.cache.Set(ctx, key, data, ttl)
+}
+
+// SetWithTags stores value and records it under tags for InvalidateTag
+func (c *TypedCache[T]) SetWithTags(ctx context.Context, key string, value T, ttl time.Duration, tags ...string) error {
+	data, err := encodeCacheValue(c.codec, value, c.compressAbove)
+	if err != nil {
+		return err
+	}
+	return c.cache.SetWithTags(ctx, key, data, ttl, tags...)
+}
+
+// SetWithTagsSince stores value under tags unless one of them was invalidated
+// after fence was taken
+func (c *TypedCache[T]) SetWithTagsSince(ctx context.Context, fence int64, key string, value T, ttl time.Duration, tags ...string) (bool, error) {
+	data, err := encodeCacheValue(c.codec, value, c.compressAbove)
+	if err != nil {
+		return false, err
+	}
+	return c.cache.SetWithTagsSince(ctx, fence, key, data, ttl, tags...)
+}
+
+// GetOrLoad returns the cached value, or calls load and caches its result.
+// Cache errors other than a miss also fall through to load, and a failure to
+// populate the cache does not fail the call.
+func (c *TypedCache[T]) GetOrLoad(ctx context.Context, key string, ttl time.Duration, load func(ctx context.Context) (T, error)) (T, error) {
+	value, err := c.Get(ctx, key)
+	if err == nil {
+		return value, nil
+	}
+
+	value, err = load(ctx)
+	if err != nil {
+		return value, err
+	}
+
+	_ = c.Set(ctx, key, value, ttl)
+	return value, nil
+}
+
+// GetTyped reads a JSON-encoded value written by SetTyped or CacheRepository.Set
+func GetTyped[T any](ctx context.Context, cache interfaces.CacheRepository, key string) (T, error) {
+	return NewTypedCache[T](cache, JSONCodec{}, DefaultCompressionThreshold).Get(ctx, key)
+}
+
+// SetTyped writes value as JSON, compressing it above DefaultCompressionThreshold
+func SetTyped[T any](ctx context.Context, cache interfaces.CacheRepository, key string, value T, ttl time.Duration) error {
+	return NewTypedCache[T](cache, JSONCodec{}, DefaultCompressionThreshold).Set(ctx, key, value, ttl)
+}
+
+// GetOrLoad is the JSON-encoded form of TypedCache.GetOrLoad
+func GetOrLoad[T any](ctx context.Context, cache interfaces.CacheRepository, key string, ttl time.Duration, load func(ctx context.Context) (T, error)) (T, error) {
+	return NewTypedCache[T](cache, JSONCodec{}, DefaultCompressionThreshold).GetOrLoad(ctx, key, ttl, load)
+}
+
+// IsCacheMiss reports whether err means the key was not in the cache
+func IsCacheMiss(err error) bool {
+	return errors.Is(err, interfaces.ErrCacheMiss)
+}
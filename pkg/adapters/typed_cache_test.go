@@ -0,0 +1,186 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/interfaces"
+)
+
+// memoryCache is a minimal in-memory CacheRepository for typed cache tests
+type memoryCache struct {
+	interfaces.CacheRepository
+	values map[string]string
+}
+
+func newMemoryCache() *memoryCache {
+	return &memoryCache{values: map[string]string{}}
+}
+
+func (m *memoryCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	switch v := value.(type) {
+	case []byte:
+		m.values[key] = string(v)
+	case string:
+		m.values[key] = v
+	default:
+		return fmt.Errorf("unexpected value type %T", value)
+	}
+	return nil
+}
+
+func (m *memoryCache) Get(ctx context.Context, key string) (string, error) {
+	value, ok := m.values[key]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", interfaces.ErrCacheMiss, key)
+	}
+	return value, nil
+}
+
+type cachedPosition struct {
+	AccountID string
+	Symbol    string
+	Quantity  float64
+}
+
+// TestTypedCacheCodecs tests round trips through each codec with and without compression
+func TestTypedCacheCodecs(t *testing.T) {
+	ctx := context.Background()
+	position := cachedPosition{AccountID: "ACC-1", Symbol: "BTC", Quantity: 1.5}
+
+	tests := []struct {
+		name          string
+		codec         interfaces.CacheCodec
+		compressAbove int
+	}{
+		{name: "json", codec: JSONCodec{}},
+		{name: "json compressed", codec: JSONCodec{}, compressAbove: 1},
+		{name: "gob", codec: GobCodec{}},
+		{name: "gob compressed", codec: GobCodec{}, compressAbove: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mem := newMemoryCache()
+			cache := NewTypedCache[cachedPosition](mem, tt.codec, tt.compressAbove)
+
+			if err := cache.Set(ctx, "position", position, time.Minute); err != nil {
+				t.Fatalf("Set failed: %v", err)
+			}
+			if compressed := mem.values["position"][0] == cacheValueCompressed; compressed != (tt.compressAbove > 0) {
+				t.Errorf("compressed = %v, expected %v", compressed, tt.compressAbove > 0)
+			}
+
+			got, err := cache.Get(ctx, "position")
+			if err != nil {
+				t.Fatalf("Get failed: %v", err)
+			}
+			if got != position {
+				t.Errorf("Get = %+v, expected %+v", got, position)
+			}
+		})
+	}
+}
+
+// TestTypedCacheRawCodec tests that raw values are stored unchanged
+func TestTypedCacheRawCodec(t *testing.T) {
+	ctx := context.Background()
+	mem := newMemoryCache()
+	cache := NewTypedCache[[]byte](mem, RawCodec{}, 0)
+
+	if err := cache.Set(ctx, "blob", []byte("payload"), time.Minute); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if mem.values["blob"] != "payload" {
+		t.Errorf("stored %q, expected raw payload", mem.values["blob"])
+	}
+
+	got, err := cache.Get(ctx, "blob")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if string(got) != "payload" {
+		t.Errorf("Get = %q, expected payload", got)
+	}
+}
+
+// TestTypedCacheHeaderBytes tests that uncompressed values which look like
+// framed or gzipped data round trip unchanged
+func TestTypedCacheHeaderBytes(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name          string
+		value         string
+		compressAbove int
+		stored        string
+	}{
+		{name: "gzip magic", value: "\x1f\x8b\x08payload", stored: "\x1f\x8b\x08payload"},
+		{name: "gzip magic with compression enabled", value: "\x1f\x8b", compressAbove: 1024, stored: "\x1f\x8b"},
+		{name: "plain header", value: "\x00payload", stored: "\x00\x00payload"},
+		{name: "compressed header", value: "\x01payload", stored: "\x00\x01payload"},
+		{name: "empty", value: "", stored: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mem := newMemoryCache()
+			cache := NewTypedCache[[]byte](mem, RawCodec{}, tt.compressAbove)
+
+			if err := cache.Set(ctx, "blob", []byte(tt.value), time.Minute); err != nil {
+				t.Fatalf("Set failed: %v", err)
+			}
+			if mem.values["blob"] != tt.stored {
+				t.Errorf("stored %q, expected %q", mem.values["blob"], tt.stored)
+			}
+
+			got, err := cache.Get(ctx, "blob")
+			if err != nil {
+				t.Fatalf("Get failed: %v", err)
+			}
+			if string(got) != tt.value {
+				t.Errorf("Get = %q, expected %q", got, tt.value)
+			}
+		})
+	}
+}
+
+// TestGetOrLoad tests loading on miss and serving subsequent reads from cache
+func TestGetOrLoad(t *testing.T) {
+	ctx := context.Background()
+	mem := newMemoryCache()
+
+	if _, err := GetTyped[cachedPosition](ctx, mem, "missing"); !IsCacheMiss(err) {
+		t.Fatalf("GetTyped error = %v, expected cache miss", err)
+	}
+
+	loads := 0
+	load := func(ctx context.Context) (cachedPosition, error) {
+		loads++
+		return cachedPosition{AccountID: "ACC-1", Symbol: "ETH", Quantity: 3}, nil
+	}
+
+	for i := 0; i < 2; i++ {
+		got, err := GetOrLoad(ctx, mem, "position", time.Minute, load)
+		if err != nil {
+			t.Fatalf("GetOrLoad failed: %v", err)
+		}
+		if got.Symbol != "ETH" {
+			t.Errorf("GetOrLoad = %+v, expected ETH position", got)
+		}
+	}
+	if loads != 1 {
+		t.Errorf("loader called %d times, expected 1", loads)
+	}
+
+	loadErr := errors.New("database unavailable")
+	_, err := GetOrLoad(ctx, mem, "other", time.Minute, func(ctx context.Context) (cachedPosition, error) {
+		return cachedPosition{}, loadErr
+	})
+	if !errors.Is(err, loadErr) {
+		t.Errorf("GetOrLoad error = %v, expected loader error", err)
+	}
+}
@@ -0,0 +1,29 @@
+package interfaces
+
+import (
+	"context"
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/models"
+)
+
+type AccountRepository interface {
+	// Create a new account, under its parent if set
+	Create(ctx context.Context, account *models.Account) error
+
+	// Get account by ID
+	GetByID(ctx context.Context, accountID string) (*models.Account, error)
+
+	// Query accounts with filters
+	Query(ctx context.Context, query *models.AccountQuery) ([]*models.Account, error)
+
+	// Update type, parent, base currency and metadata
+	Update(ctx context.Context, account *models.Account) error
+
+	// Move account to another lifecycle status
+	UpdateStatus(ctx context.Context, accountID string, status models.AccountStatus) error
+
+	// Get direct sub-accounts
+	GetChildren(ctx context.Context, accountID string) ([]*models.Account, error)
+
+	// Get all sub-accounts below account, parents before children
+	GetDescendants(ctx context.Context, accountID string) ([]*models.Account, error)
+}
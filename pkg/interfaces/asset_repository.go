@@ -0,0 +1,23 @@
+package interfaces
+
+import (
+	"context"
+	"github.com/quantfidential/trading-ecosystem/custodian-data-adapter-go/pkg/models"
+)
+
+type AssetRepository interface {
+	// Create a new asset with its aliases
+	Create(ctx context.Context, asset *models.Asset) error
+
+	// Get asset by symbol or alias, in any case
+	GetBySymbol(ctx context.Context, symbol string) (*models.Asset, error)
+
+	// Query assets with filters
+	Query(ctx context.Context, query *models.AssetQuery) ([]*models.Asset, error)
+
+	// Update asset definition, replacing its aliases
+	Update(ctx context.Context, asset *models.Asset) error
+
+	// Enable or disable writes referencing the asset
+	SetEnabled(ctx context.Context, symbol string, enabled bool) error
+}
@@ -2,14 +2,26 @@ package interfaces
 
 import (
 	"context"
+	"errors"
 	"time"
 )
 
+// ErrCacheMiss is returned by Get when the key does not exist
+var ErrCacheMiss = errors.New("key not found")
+
+// CacheCodec serializes values stored through the typed cache helpers
+type CacheCodec interface {
+	// Name identifies the codec in logs and errors
+	Name() string
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
 type CacheRepository interface {
 	// Set a value with optional TTL
 	Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error
 
-	// Get a value
+	// Get a value, returning ErrCacheMiss if the key does not exist
 	Get(ctx context.Context, key string) (string, error)
 
 	// Delete a key
@@ -21,12 +33,62 @@ type CacheRepository interface {
 	// Set expiration on key
 	Expire(ctx context.Context, key string, ttl time.Duration) error
 
+	// Remaining time to live: -1 if the key has no expiry, ErrCacheMiss if it does not exist
+	TTL(ctx context.Context, key string) (time.Duration, error)
+
 	// Get keys matching pattern
 	Keys(ctx context.Context, pattern string) ([]string, error)
 
 	// Delete keys matching pattern
 	DeletePattern(ctx context.Context, pattern string) error
 
+	// Get several values in one round trip; missing keys are omitted from the result
+	MGet(ctx context.Context, keys []string) (map[string]string, error)
+
+	// Set several values with the same optional TTL in one pipeline
+	MSet(ctx context.Context, values map[string]interface{}, ttl time.Duration) error
+
+	// Set a value only if the key does not exist
+	SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error)
+
+	// Replace a value only if it currently equals expected
+	CompareAndSwap(ctx context.Context, key, expected string, value interface{}, ttl time.Duration) (bool, error)
+
+	// Set hash fields
+	HSet(ctx context.Context, key string, fields map[string]interface{}) error
+
+	// Get a hash field, returning ErrCacheMiss if the key or field does not exist
+	HGet(ctx context.Context, key, field string) (string, error)
+
+	// Get all hash fields
+	HGetAll(ctx context.Context, key string) (map[string]string, error)
+
+	// Delete hash fields
+	HDel(ctx context.Context, key string, fields ...string) error
+
+	// Atomically add delta to an integer counter; ttl is applied when the counter has none
+	Incr(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error)
+
+	// Atomically add delta to a float counter; ttl is applied when the counter has none
+	IncrByFloat(ctx context.Context, key string, delta float64, ttl time.Duration) (float64, error)
+
+	// Set a value and record it under each tag for bulk invalidation
+	SetWithTags(ctx context.Context, key string, value interface{}, ttl time.Duration, tags ...string) error
+
+	// Start a cache fill: the returned fence is passed to SetWithTagsSince
+	// once the value has been read from the source of truth
+	TagFence(ctx context.Context) (int64, error)
+
+	// SetWithTags unless one of the tags was invalidated after fence was
+	// taken, reporting whether the value was stored
+	SetWithTagsSince(ctx context.Context, fence int64, key string, value interface{}, ttl time.Duration, tags ...string) (bool, error)
+
+	// Delete every key recorded under tag, returning the deleted keys
+	InvalidateTag(ctx context.Context, tag string) ([]string, error)
+
+	// Drop tag members whose keys have expired or been deleted, returning how many were removed
+	PruneTag(ctx context.Context, tag string) (int, error)
+
 	// Health check
 	HealthCheck(ctx context.Context) error
 }
@@ -0,0 +1,24 @@
+package interfaces
+
+import (
+	"context"
+)
+
+// Credentials authenticate connections to a backing store
+type Credentials struct {
+	// Username may be empty to keep the user from the connection URL
+	Username string
+	Password string
+}
+
+// CredentialsProvider supplies rotating store credentials. The adapters ask
+// for the current credentials whenever they open a connection, and recycle
+// or re-authenticate existing connections when notified of a rotation.
+type CredentialsProvider interface {
+	// Current credentials
+	Credentials(ctx context.Context) (Credentials, error)
+
+	// Register fn to be called after the credentials rotate; the returned
+	// function removes it
+	Subscribe(fn func()) (unsubscribe func())
+}
@@ -0,0 +1,14 @@
+package interfaces
+
+import "errors"
+
+// ErrNotFound is wrapped by repository errors for records that do not exist
+var ErrNotFound = errors.New("not found")
+
+// ErrInvalidAccount is wrapped by errors for writes that reference an
+// unknown or closed account, or break the account hierarchy
+var ErrInvalidAccount = errors.New("invalid account")
+
+// ErrInvalidAsset is wrapped by errors for writes that reference an unknown
+// or disabled asset, or exceed its precision
+var ErrInvalidAsset = errors.New("invalid asset")
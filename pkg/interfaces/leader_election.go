@@ -0,0 +1,23 @@
+package interfaces
+
+import (
+	"context"
+)
+
+// LeaderElector coordinates singleton workers (settlement sweeper, hold expiry)
+// across several instances so only one of them acts at a time.
+type LeaderElector interface {
+	// Run campaigns for leadership and renews the lease until ctx is cancelled.
+	// The lease is released on return.
+	Run(ctx context.Context) error
+
+	// Resign gives up leadership if held; Run keeps campaigning afterwards
+	Resign(ctx context.Context) error
+
+	// Whether this instance currently holds the lease
+	IsLeader() bool
+
+	// Fencing token of the current term, 0 when not leader. Tokens increase
+	// monotonically across terms so downstream writes can reject stale leaders.
+	FencingToken() int64
+}
@@ -0,0 +1,57 @@
+package interfaces
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrLockNotAcquired is returned when a lock or semaphore permit is held by others
+	ErrLockNotAcquired = errors.New("lock not acquired")
+
+	// ErrLockNotHeld is returned when unlocking or extending a lock or permit
+	// that has expired or been taken over by another owner
+	ErrLockNotHeld = errors.New("lock not held")
+)
+
+// Lock is a held mutual-exclusion lease. Token identifies the owner and must be
+// presented to unlock or extend it.
+type Lock struct {
+	Key       string
+	Token     string
+	ExpiresAt time.Time
+}
+
+// SemaphorePermit is one of a bounded number of concurrent leases on a name
+type SemaphorePermit struct {
+	Name      string
+	Token     string
+	ExpiresAt time.Time
+}
+
+type LockRepository interface {
+	// Try to acquire a lock once, returning ErrLockNotAcquired if it is held
+	TryLock(ctx context.Context, key string, ttl time.Duration) (*Lock, error)
+
+	// Acquire a lock, retrying until timeout elapses
+	Lock(ctx context.Context, key string, ttl, timeout time.Duration) (*Lock, error)
+
+	// Release a lock if still owned by the caller
+	Unlock(ctx context.Context, lock *Lock) error
+
+	// Extend the lease of a lock still owned by the caller
+	ExtendLock(ctx context.Context, lock *Lock, ttl time.Duration) error
+
+	// Try to acquire one of limit permits once, returning ErrLockNotAcquired when full
+	TryAcquireSemaphore(ctx context.Context, name string, limit int, ttl time.Duration) (*SemaphorePermit, error)
+
+	// Acquire one of limit permits, retrying until timeout elapses
+	AcquireSemaphore(ctx context.Context, name string, limit int, ttl, timeout time.Duration) (*SemaphorePermit, error)
+
+	// Release a permit
+	ReleaseSemaphore(ctx context.Context, permit *SemaphorePermit) error
+
+	// Extend the lease of a permit still held by the caller
+	ExtendSemaphore(ctx context.Context, permit *SemaphorePermit, ttl time.Duration) error
+}
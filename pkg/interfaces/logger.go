@@ -0,0 +1,44 @@
+package interfaces
+
+import (
+	"context"
+)
+
+// LogLevel orders log messages by severity
+type LogLevel int
+
+const (
+	LogLevelDebug LogLevel = iota
+	LogLevelInfo
+	LogLevelWarn
+	LogLevelError
+)
+
+// LogFields are structured key/value pairs attached to log lines
+type LogFields map[string]interface{}
+
+// Logger is the logging dependency of the adapters. Implementations for
+// logrus and log/slog are provided by the adapters package.
+type Logger interface {
+	// Return a logger that adds the given fields to every line
+	WithField(key string, value interface{}) Logger
+	WithFields(fields LogFields) Logger
+	WithError(err error) Logger
+
+	// Return a logger that passes ctx to the backend (e.g. for trace hooks)
+	WithContext(ctx context.Context) Logger
+
+	Debug(msg string)
+	Info(msg string)
+	Warn(msg string)
+	Error(msg string)
+
+	// Whether messages at level are emitted
+	Enabled(level LogLevel) bool
+}
+
+// LevelSetter is implemented by loggers whose level can change at runtime,
+// such as the logrus adapter
+type LevelSetter interface {
+	SetLevel(level LogLevel)
+}
@@ -2,18 +2,103 @@ package interfaces
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 )
 
+// ServiceStatus describes whether a registered instance should receive traffic
+type ServiceStatus string
+
+const (
+	ServiceStatusHealthy     ServiceStatus = "healthy"
+	ServiceStatusDegraded    ServiceStatus = "degraded"
+	ServiceStatusDraining    ServiceStatus = "draining"
+	ServiceStatusMaintenance ServiceStatus = "maintenance"
+	ServiceStatusUnhealthy   ServiceStatus = "unhealthy"
+)
+
+// ServiceInfo is stored as JSON so services written in other languages can
+// register and discover each other; the field names are part of that contract.
+//
+// Releases before the snake_case tags stored the Go field names
+// ("ServiceName", "LastHeartbeat", ...). Those registrations still decode, but
+// older releases cannot read the new names, so during a rolling upgrade new
+// instances are invisible to old ones until they re-register or expire.
 type ServiceInfo struct {
+	ServiceName   string            `json:"service_name"`
+	ServiceID     string            `json:"service_id"`
+	Address       string            `json:"address"`
+	Port          int               `json:"port"`
+	Version       string            `json:"version"`
+	Status        ServiceStatus     `json:"status,omitempty"`
+	Metadata      map[string]string `json:"metadata,omitempty"`
+	RegisteredAt  time.Time         `json:"registered_at"`
+	LastHeartbeat time.Time         `json:"last_heartbeat"`
+}
+
+// UnmarshalJSON decodes ServiceInfo, falling back to the Go field names that
+// registrations written before the JSON tags used
+func (s *ServiceInfo) UnmarshalJSON(data []byte) error {
+	type serviceInfo ServiceInfo
+	var decoded struct {
+		serviceInfo
+		LegacyServiceName   string    `json:"ServiceName"`
+		LegacyServiceID     string    `json:"ServiceID"`
+		LegacyRegisteredAt  time.Time `json:"RegisteredAt"`
+		LegacyLastHeartbeat time.Time `json:"LastHeartbeat"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	*s = ServiceInfo(decoded.serviceInfo)
+	if s.ServiceName == "" {
+		s.ServiceName = decoded.LegacyServiceName
+	}
+	if s.ServiceID == "" {
+		s.ServiceID = decoded.LegacyServiceID
+	}
+	if s.RegisteredAt.IsZero() {
+		s.RegisteredAt = decoded.LegacyRegisteredAt
+	}
+	if s.LastHeartbeat.IsZero() {
+		s.LastHeartbeat = decoded.LegacyLastHeartbeat
+	}
+	return nil
+}
+
+// LabelOperator is the comparison applied by a LabelRequirement
+type LabelOperator string
+
+const (
+	LabelOpEquals       LabelOperator = "="
+	LabelOpNotEquals    LabelOperator = "!="
+	LabelOpIn           LabelOperator = "in"
+	LabelOpNotIn        LabelOperator = "notin"
+	LabelOpExists       LabelOperator = "exists"
+	LabelOpDoesNotExist LabelOperator = "!exists"
+)
+
+// LabelRequirement selects services by a single metadata key
+type LabelRequirement struct {
+	Key      string
+	Operator LabelOperator
+	Values   []string
+}
+
+// DiscoverQuery filters registered services. All non-empty criteria must match.
+type DiscoverQuery struct {
+	// Exact service name; empty matches any service
 	ServiceName string
-	ServiceID   string
-	Address     string
-	Port        int
-	Version     string
-	Metadata    map[string]string
-	RegisteredAt time.Time
-	LastHeartbeat time.Time
+
+	// Semantic version constraint, e.g. ">=1.2, <2" or "^1.4 || ~2.0.3"
+	VersionConstraint string
+
+	// Metadata label selectors
+	Labels []LabelRequirement
+
+	// Accepted statuses; empty accepts healthy and degraded instances only
+	Statuses []ServiceStatus
 }
 
 type ServiceDiscoveryRepository interface {
@@ -23,12 +108,19 @@ type ServiceDiscoveryRepository interface {
 	// Deregister a service instance
 	Deregister(ctx context.Context, serviceID string) error
 
-	// Update heartbeat for a service
+	// Update heartbeat for a service. Returns ErrNotFound once the
+	// registration has expired, so the caller can register again.
 	Heartbeat(ctx context.Context, serviceID string) error
 
+	// Update the advertised status of a service (e.g. draining before shutdown)
+	UpdateStatus(ctx context.Context, serviceID string, status ServiceStatus) error
+
 	// Discover service instances by name
 	Discover(ctx context.Context, serviceName string) ([]*ServiceInfo, error)
 
+	// Query service instances by name, version, labels and status
+	Query(ctx context.Context, query *DiscoverQuery) ([]*ServiceInfo, error)
+
 	// Get service info by ID
 	GetServiceInfo(ctx context.Context, serviceID string) (*ServiceInfo, error)
 
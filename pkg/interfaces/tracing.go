@@ -0,0 +1,27 @@
+package interfaces
+
+import (
+	"context"
+)
+
+// Tracer is the hook the adapters call around every repository operation.
+// Implementations bridge to OpenTelemetry or another tracing system.
+type Tracer interface {
+	// Start a span as a child of any span in ctx; the returned context carries it
+	StartSpan(ctx context.Context, name string, attributes ...SpanAttribute) (context.Context, Span)
+}
+
+// Span is one traced operation
+type Span interface {
+	// Add attributes known only after the operation ran (e.g. rows affected)
+	SetAttributes(attributes ...SpanAttribute)
+
+	// End the span, recording err if the operation failed
+	End(err error)
+}
+
+// SpanAttribute is a key/value pair attached to a span
+type SpanAttribute struct {
+	Key   string
+	Value interface{}
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type AccountType string
+
+const (
+	AccountTypeClient  AccountType = "CLIENT"
+	AccountTypeOmnibus AccountType = "OMNIBUS"
+	AccountTypeHouse   AccountType = "HOUSE"
+	AccountTypeFee     AccountType = "FEE"
+)
+
+type AccountStatus string
+
+const (
+	AccountStatusActive AccountStatus = "ACTIVE"
+	AccountStatusFrozen AccountStatus = "FROZEN"
+	AccountStatusClosed AccountStatus = "CLOSED"
+)
+
+type Account struct {
+	AccountID       string          `json:"account_id" db:"account_id"`
+	AccountType     AccountType     `json:"account_type" db:"account_type"`
+	ParentAccountID *string         `json:"parent_account_id,omitempty" db:"parent_account_id"`
+	Status          AccountStatus   `json:"status" db:"status"`
+	BaseCurrency    string          `json:"base_currency" db:"base_currency"`
+	CreatedAt       time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time       `json:"updated_at" db:"updated_at"`
+	ClosedAt        *time.Time      `json:"closed_at,omitempty" db:"closed_at"`
+	Metadata        json.RawMessage `json:"metadata,omitempty" db:"metadata"`
+}
+
+type AccountQuery struct {
+	AccountType     *AccountType
+	Status          *AccountStatus
+	ParentAccountID *string
+	BaseCurrency    *string
+	Limit           int
+	Offset          int
+}
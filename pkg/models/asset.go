@@ -0,0 +1,36 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type AssetClass string
+
+const (
+	AssetClassCrypto     AssetClass = "CRYPTO"
+	AssetClassStablecoin AssetClass = "STABLECOIN"
+	AssetClassFiat       AssetClass = "FIAT"
+	AssetClassSecurity   AssetClass = "SECURITY"
+)
+
+type Asset struct {
+	Symbol         string          `json:"symbol" db:"symbol"`
+	Aliases        []string        `json:"aliases,omitempty" db:"aliases"`
+	AssetClass     AssetClass      `json:"asset_class" db:"asset_class"`
+	Decimals       int             `json:"decimals" db:"decimals"`
+	Network        *string         `json:"network,omitempty" db:"network"`
+	SettlementDays int             `json:"settlement_days" db:"settlement_days"` // Settlement cycle T+N
+	Enabled        bool            `json:"enabled" db:"enabled"`
+	CreatedAt      time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at" db:"updated_at"`
+	Metadata       json.RawMessage `json:"metadata,omitempty" db:"metadata"`
+}
+
+type AssetQuery struct {
+	AssetClass *AssetClass
+	Network    *string
+	Enabled    *bool
+	Limit      int
+	Offset     int
+}